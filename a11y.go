@@ -0,0 +1,118 @@
+package templatebox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Accessibility rules reported in LintIssue.Rule when
+// Config.CheckAccessibility is set.
+const (
+	// RuleImageAlt reports an <img> without an alt attribute. Decorative
+	// images should have an empty alt="".
+	RuleImageAlt = "img-alt"
+
+	// RuleInputLabel reports an <input>, <select> or <textarea> without a
+	// <label>, aria-label, aria-labelledby or title.
+	RuleInputLabel = "input-label"
+
+	// RuleDuplicateID reports an id used by more than one element.
+	RuleDuplicateID = "duplicate-id"
+)
+
+// unlabelledInputTypes are the input types that need no label.
+var unlabelledInputTypes = map[string]bool{
+	"hidden": true, "submit": true, "reset": true, "button": true, "image": true,
+}
+
+// checkAccessibility reports images without alt text, form controls
+// without labels and duplicate ids in output.
+func checkAccessibility(name string, output []byte) []LintIssue {
+	// labels can come after the control they label, so collect the
+	// targets of every <label for> first
+	labelled := make(map[string]bool)
+	scanTokens(output, func(tok htmlToken, line, col int) {
+		if tok.Type == startTagToken && tok.Data == "label" {
+			if id, ok := attr(tok, "for"); ok {
+				labelled[id] = true
+			}
+		}
+	})
+
+	var issues []LintIssue
+	ids := make(map[string]string)
+	inLabel := 0
+	scanTokens(output, func(tok htmlToken, line, col int) {
+		if tok.Type == endTagToken && tok.Data == "label" && inLabel > 0 {
+			inLabel--
+		}
+		if tok.Type != startTagToken && tok.Type != selfClosingTagToken {
+			return
+		}
+		at := func(rule, format string, args ...any) {
+			issues = append(issues, outputIssue(name, line, col, rule, format, args...))
+		}
+
+		if id, ok := attr(tok, "id"); ok && id != "" {
+			if first, dup := ids[id]; dup {
+				at(RuleDuplicateID, "id %q is already used at %s", id, first)
+			} else {
+				ids[id] = fmt.Sprintf("%d:%d", line, col)
+			}
+		}
+
+		switch tok.Data {
+		case "label":
+			if tok.Type == startTagToken {
+				inLabel++
+			}
+		case "img":
+			if _, ok := attr(tok, "alt"); !ok {
+				src, _ := attr(tok, "src")
+				at(RuleImageAlt, "<img src=%q> has no alt attribute", src)
+			}
+		case "input", "select", "textarea":
+			if typ, _ := attr(tok, "type"); tok.Data == "input" && unlabelledInputTypes[strings.ToLower(typ)] {
+				return
+			}
+			if inLabel > 0 || hasLabel(tok, labelled) {
+				return
+			}
+			at(RuleInputLabel, "%s has no label", describeControl(tok))
+		}
+	})
+	return issues
+}
+
+// describeControl returns the start tag of a form control with only its
+// name or id attribute, to identify it in messages.
+func describeControl(tok htmlToken) string {
+	for _, a := range []string{"name", "id"} {
+		if v, ok := attr(tok, a); ok {
+			return fmt.Sprintf("<%s %s=%q>", tok.Data, a, v)
+		}
+	}
+	return "<" + tok.Data + ">"
+}
+
+// hasLabel reports whether the form control is labelled by an attribute or
+// by a <label> whose for attribute is in labelled.
+func hasLabel(tok htmlToken, labelled map[string]bool) bool {
+	for _, a := range []string{"aria-label", "aria-labelledby", "title"} {
+		if v, ok := attr(tok, a); ok && strings.TrimSpace(v) != "" {
+			return true
+		}
+	}
+	id, ok := attr(tok, "id")
+	return ok && labelled[id]
+}
+
+// attr returns the value of the named attribute of tok.
+func attr(tok htmlToken, key string) (string, bool) {
+	for _, a := range tok.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,49 @@
+package templatebox_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestCheckAccessibility(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		CheckAccessibility: true,
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("form", templatebox.TemplateSet{
+		Templates: []string{`<form id="signup">
+<img src="logo.png"><img src="spacer.gif" alt="">
+<label>Name <input name="name"></label>
+<input id="email" name="email"><label for="email">Email</label>
+<input name="phone"><input type="hidden" name="token">
+<textarea aria-label="Bio"></textarea>
+<div id="signup"></div>
+</form>`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	issues, err := box.CheckOutput("form")
+	if err != nil {
+		t.Fatalf("CheckOutput failed: %v", err)
+	}
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	expected := []string{
+		`output:2:1: <img src="logo.png"> has no alt attribute (img-alt)`,
+		`output:5:1: <input name="phone"> has no label (input-label)`,
+		`output:7:1: id "signup" is already used at 1:1 (duplicate-id)`,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("CheckOutput returned %q, expected %q", got, expected)
+	}
+}
@@ -0,0 +1,31 @@
+// Package bluemonday provides a templatebox.Sanitizer backed by the
+// bluemonday HTML sanitizer.
+//
+//	box.SetSanitizer(bluemonday.New(nil))
+package bluemonday
+
+import (
+	bm "github.com/microcosm-cc/bluemonday"
+)
+
+// Sanitizer adapts a bluemonday policy to the templatebox.Sanitizer
+// interface.
+type Sanitizer struct {
+	policy *bm.Policy
+}
+
+// New returns a Sanitizer using the given policy. If policy is nil the
+// bluemonday UGCPolicy is used, which allows the common formatting elements
+// found in user-generated content.
+func New(policy *bm.Policy) *Sanitizer {
+	if policy == nil {
+		policy = bm.UGCPolicy()
+	}
+	return &Sanitizer{policy: policy}
+}
+
+// Sanitize returns html with all elements and attributes not allowed by
+// the policy removed.
+func (s *Sanitizer) Sanitize(html string) string {
+	return s.policy.Sanitize(html)
+}
@@ -0,0 +1,20 @@
+package bluemonday_test
+
+import (
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+	"github.com/andyfusniak/templatebox/adapter/bluemonday"
+)
+
+var _ templatebox.Sanitizer = (*bluemonday.Sanitizer)(nil)
+
+func TestSanitizeDefaultPolicy(t *testing.T) {
+	s := bluemonday.New(nil)
+
+	got := s.Sanitize(`<p onclick="x()">hi <a href="javascript:alert(1)">link</a></p><script>alert(1)</script>`)
+	expected := `<p>hi link</p>`
+	if got != expected {
+		t.Fatalf("Sanitize returned %s, expected %s", got, expected)
+	}
+}
@@ -0,0 +1,16 @@
+module github.com/andyfusniak/templatebox/adapter/bluemonday
+
+go 1.22.5
+
+replace github.com/andyfusniak/templatebox => ../..
+
+require (
+	github.com/andyfusniak/templatebox v0.0.0-00010101000000-000000000000
+	github.com/microcosm-cc/bluemonday v1.0.27
+)
+
+require (
+	github.com/aymerick/douceur v0.2.0 // indirect
+	github.com/gorilla/css v1.0.1 // indirect
+	golang.org/x/net v0.26.0 // indirect
+)
@@ -0,0 +1,53 @@
+// Package chroma provides a templatebox.Highlighter backed by the chroma
+// syntax highlighter.
+//
+//	box.SetHighlighter(chroma.New("github"))
+package chroma
+
+import (
+	"bytes"
+
+	ch "github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// Highlighter adapts chroma to the templatebox.Highlighter interface.
+type Highlighter struct {
+	style     *ch.Style
+	formatter *html.Formatter
+}
+
+// New returns a Highlighter using the named chroma style, such as "github"
+// or "monokai", with inline styles. If the style is not found the default
+// style is used. Pass formatter options, such as html.WithClasses(true)
+// to use CSS classes instead of inline styles, to replace the defaults.
+func New(style string, opts ...html.Option) *Highlighter {
+	if len(opts) == 0 {
+		opts = []html.Option{html.PreventSurroundingPre(false)}
+	}
+	return &Highlighter{
+		style:     styles.Get(style),
+		formatter: html.New(opts...),
+	}
+}
+
+// Highlight renders code in the language lang as highlighted HTML. Code in
+// an unknown language is escaped without highlighting.
+func (h *Highlighter) Highlight(lang, code string) (string, error) {
+	lexer := lexers.Get(lang)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+
+	it, err := ch.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := h.formatter.Format(&buf, h.style, it); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
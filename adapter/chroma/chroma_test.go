@@ -0,0 +1,23 @@
+package chroma_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+	"github.com/andyfusniak/templatebox/adapter/chroma"
+)
+
+var _ templatebox.Highlighter = (*chroma.Highlighter)(nil)
+
+func TestHighlight(t *testing.T) {
+	h := chroma.New("github")
+
+	got, err := h.Highlight("go", `x := "<b>"`)
+	if err != nil {
+		t.Fatalf("Highlight failed: %v", err)
+	}
+	if !strings.HasPrefix(got, "<pre") || !strings.Contains(got, "&lt;b&gt;") || !strings.Contains(got, "<span") {
+		t.Fatalf("Highlight returned %s, expected escaped and highlighted code in a pre element", got)
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/andyfusniak/templatebox/adapter/chroma
+
+go 1.22.5
+
+replace github.com/andyfusniak/templatebox => ../..
+
+require (
+	github.com/alecthomas/chroma/v2 v2.14.0
+	github.com/andyfusniak/templatebox v0.0.0-00010101000000-000000000000
+)
+
+require github.com/dlclark/regexp2 v1.11.0 // indirect
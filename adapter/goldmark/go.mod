@@ -0,0 +1,10 @@
+module github.com/andyfusniak/templatebox/adapter/goldmark
+
+go 1.22.5
+
+replace github.com/andyfusniak/templatebox => ../..
+
+require (
+	github.com/andyfusniak/templatebox v0.0.0-00010101000000-000000000000
+	github.com/yuin/goldmark v1.8.6
+)
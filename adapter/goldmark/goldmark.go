@@ -0,0 +1,35 @@
+// Package goldmark provides a templatebox.MarkdownConverter backed by the
+// goldmark CommonMark implementation.
+//
+//	box.SetMarkdownConverter(goldmark.New(nil))
+package goldmark
+
+import (
+	"bytes"
+
+	gm "github.com/yuin/goldmark"
+)
+
+// Converter adapts a goldmark.Markdown to the templatebox.MarkdownConverter
+// interface.
+type Converter struct {
+	md gm.Markdown
+}
+
+// New returns a Converter using md. If md is nil a default goldmark
+// instance is used.
+func New(md gm.Markdown) *Converter {
+	if md == nil {
+		md = gm.New()
+	}
+	return &Converter{md: md}
+}
+
+// Convert renders markdown to HTML.
+func (c *Converter) Convert(markdown string) (string, error) {
+	var buf bytes.Buffer
+	if err := c.md.Convert([]byte(markdown), &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,24 @@
+package goldmark_test
+
+import (
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+	"github.com/andyfusniak/templatebox/adapter/goldmark"
+)
+
+var _ templatebox.MarkdownConverter = (*goldmark.Converter)(nil)
+
+func TestConvert(t *testing.T) {
+	c := goldmark.New(nil)
+
+	got, err := c.Convert("# Title\n\nSome *emphasis*.")
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+
+	expected := "<h1>Title</h1>\n<p>Some <em>emphasis</em>.</p>\n"
+	if got != expected {
+		t.Fatalf("Convert returned %q, expected %q", got, expected)
+	}
+}
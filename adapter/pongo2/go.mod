@@ -0,0 +1,10 @@
+module github.com/andyfusniak/templatebox/adapter/pongo2
+
+go 1.22.5
+
+replace github.com/andyfusniak/templatebox => ../..
+
+require (
+	github.com/andyfusniak/templatebox v0.0.0-00010101000000-000000000000
+	github.com/flosch/pongo2/v6 v6.1.0
+)
@@ -0,0 +1,101 @@
+// Package pongo2 provides a templatebox.Engine backed by pongo2, a
+// Django-syntax template engine, for teams relying on {% extends %} and
+// {% block %} inheritance.
+//
+//	box.AddTemplate("home", templatebox.FileSet{
+//		Filenames: []string{"home.html", "layouts/base.html"},
+//		Engine:    pongo2.New(),
+//	})
+package pongo2
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/andyfusniak/templatebox"
+	p2 "github.com/flosch/pongo2/v6"
+)
+
+// Engine parses templates with pongo2. The first source is the template
+// and every source, including the first, can be named by {% extends %},
+// {% include %} and {% import %} tags, using either the filename from the
+// FileSet or its base name. Templates are only loaded from the sources, so
+// every template in the inheritance chain must be listed in the FileSet;
+// this keeps debug mode rebuilding and Reload working on the whole chain.
+//
+// The templatebox FuncMap is available as global functions, for example
+// {{ asset("css/site.css") }}.
+type Engine struct{}
+
+// New returns a pongo2 Engine.
+func New() *Engine {
+	return &Engine{}
+}
+
+// Template is a parsed pongo2 template.
+type Template struct {
+	tpl *p2.Template
+}
+
+// Parse implements templatebox.Engine.
+func (e *Engine) Parse(name string, srcs []templatebox.Source, funcs templatebox.FuncMap) (templatebox.Template, error) {
+	if len(srcs) == 0 {
+		return nil, fmt.Errorf("no sources for template %s", name)
+	}
+
+	loader := make(sourceLoader)
+	for _, src := range srcs {
+		loader[src.Name] = src.Text
+		if src.Filename != "" {
+			loader[src.Filename] = src.Text
+		}
+	}
+
+	set := p2.NewSet(name, loader)
+	for k, fn := range funcs {
+		set.Globals[k] = fn
+	}
+
+	tpl, err := set.FromFile(srcs[0].Name)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{tpl: tpl}, nil
+}
+
+// Execute implements templatebox.Template. The keys of data given as a
+// map[string]any or pongo2.Context become the template variables; any other
+// data is available as the variable data.
+func (t *Template) Execute(w io.Writer, data any) error {
+	var ctx p2.Context
+	switch d := data.(type) {
+	case p2.Context:
+		ctx = d
+	case map[string]any:
+		ctx = p2.Context(d)
+	case nil:
+	default:
+		ctx = p2.Context{"data": data}
+	}
+	return t.tpl.ExecuteWriter(ctx, w)
+}
+
+// sourceLoader is a pongo2.TemplateLoader serving the template sources.
+type sourceLoader map[string][]byte
+
+// Abs implements pongo2.TemplateLoader. Names are relative to the template
+// directory rather than to the including template.
+func (l sourceLoader) Abs(base, name string) string {
+	return name
+}
+
+// Get implements pongo2.TemplateLoader.
+func (l sourceLoader) Get(path string) (io.Reader, error) {
+	text, ok := l[path]
+	if !ok {
+		return nil, fmt.Errorf("template %s is not in the FileSet: %w", path, os.ErrNotExist)
+	}
+	return bytes.NewReader(text), nil
+}
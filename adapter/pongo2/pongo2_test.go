@@ -0,0 +1,57 @@
+package pongo2_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+	"github.com/andyfusniak/templatebox/adapter/pongo2"
+)
+
+var _ templatebox.Engine = (*pongo2.Engine)(nil)
+
+func TestRenderExtends(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"home.html": `{% extends "layouts/base.html" %}` +
+			`{% block content %}Hi {{ name }}{% endblock %}`,
+		"layouts/base.html": `<title>{% block title %}Site{% endblock %}</title>` +
+			`<main>{% block content %}{% endblock %}</main>{{ upper("end") }}`,
+	}
+	for name, text := range files {
+		filename := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+		if err := os.WriteFile(filename, []byte(text), 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+
+	box, err := templatebox.NewBoxFromOSDir(dir, nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetGlobalFuncMap(templatebox.FuncMap{"upper": strings.ToUpper})
+
+	err = box.AddTemplate("home", templatebox.FileSet{
+		Filenames: []string{"home.html", "layouts/base.html"},
+		Engine:    pongo2.New(),
+	})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "home", map[string]any{"name": "<Ann>"}); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	expected := `<title>Site</title><main>Hi &lt;Ann&gt;</main>END`
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+}
@@ -0,0 +1,21 @@
+module github.com/andyfusniak/templatebox/adapter/raymond
+
+go 1.22.5
+
+replace github.com/andyfusniak/templatebox => ../..
+
+replace github.com/andyfusniak/templatebox/adapter/bluemonday => ../bluemonday
+
+require (
+	github.com/andyfusniak/templatebox v0.0.0-00010101000000-000000000000
+	github.com/andyfusniak/templatebox/adapter/bluemonday v0.0.0-00010101000000-000000000000
+	github.com/aymerick/raymond v2.0.2+incompatible
+)
+
+require (
+	github.com/aymerick/douceur v0.2.0 // indirect
+	github.com/gorilla/css v1.0.1 // indirect
+	github.com/microcosm-cc/bluemonday v1.0.27 // indirect
+	golang.org/x/net v0.26.0 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+)
@@ -0,0 +1,94 @@
+// Package raymond provides a templatebox.Engine backed by raymond, a
+// Handlebars implementation that also renders Mustache templates, so
+// logic-less templates exported from design and marketing tools can be
+// registered into a Box alongside html/template templates.
+//
+//	box.SetSanitizer(bluemonday.New(nil))
+//	box.AddTemplate("promo", templatebox.FileSet{
+//		Filenames: []string{"promo.hbs", "footer.hbs"},
+//		Engine:    raymond.New(),
+//	})
+package raymond
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/andyfusniak/templatebox"
+	ry "github.com/aymerick/raymond"
+)
+
+// Engine parses Handlebars and Mustache templates with raymond. The first
+// source is the template and any further sources are registered as
+// partials, named after the source without its extension, so footer.hbs is
+// included with {{> footer }}.
+//
+// The templatebox FuncMap is not available to templates because Handlebars
+// helpers use a different calling convention.
+type Engine struct {
+	// Sanitize passes the rendered output through the Sanitizer of the
+	// Box, as set with SetSanitizer. Triple-stash expressions such as
+	// {{{ body }}} are not escaped by Handlebars, so leave it on unless
+	// the templates are trusted.
+	Sanitize bool
+}
+
+// New returns an Engine that sanitizes its output.
+func New() *Engine {
+	return &Engine{Sanitize: true}
+}
+
+// Template is a parsed Handlebars template.
+type Template struct {
+	tpl      *ry.Template
+	sanitize func(string) (template.HTML, error)
+}
+
+// Parse implements templatebox.Engine.
+func (e *Engine) Parse(name string, srcs []templatebox.Source, funcs templatebox.FuncMap) (templatebox.Template, error) {
+	if len(srcs) == 0 {
+		return nil, fmt.Errorf("no sources for template %s", name)
+	}
+
+	tpl, err := ry.Parse(string(srcs[0].Text))
+	if err != nil {
+		return nil, fmt.Errorf("parse %s failed: %w", srcs[0].Name, err)
+	}
+	for _, src := range srcs[1:] {
+		partial, err := ry.Parse(string(src.Text))
+		if err != nil {
+			return nil, fmt.Errorf("parse %s failed: %w", src.Name, err)
+		}
+		tpl.RegisterPartialTemplate(strings.TrimSuffix(src.Name, path.Ext(src.Name)), partial)
+	}
+
+	t := &Template{tpl: tpl}
+	if e.Sanitize {
+		fn, ok := funcs["sanitize"].(func(string) (template.HTML, error))
+		if !ok {
+			return nil, fmt.Errorf("template %s: sanitize func has type %T", name, funcs["sanitize"])
+		}
+		t.sanitize = fn
+	}
+	return t, nil
+}
+
+// Execute implements templatebox.Template.
+func (t *Template) Execute(w io.Writer, data any) error {
+	out, err := t.tpl.Exec(data)
+	if err != nil {
+		return err
+	}
+	if t.sanitize != nil {
+		html, err := t.sanitize(out)
+		if err != nil {
+			return err
+		}
+		out = string(html)
+	}
+	_, err = io.WriteString(w, out)
+	return err
+}
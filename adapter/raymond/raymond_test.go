@@ -0,0 +1,57 @@
+package raymond_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+	"github.com/andyfusniak/templatebox/adapter/bluemonday"
+	"github.com/andyfusniak/templatebox/adapter/raymond"
+)
+
+var _ templatebox.Engine = (*raymond.Engine)(nil)
+
+func TestRenderSanitized(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"promo.hbs":  `<h1>{{ title }}</h1>{{{ body }}}{{> footer }}`,
+		"footer.hbs": `<p>{{ company }}</p>`,
+	}
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+
+	box, err := templatebox.NewBoxFromOSDir(dir, nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetSanitizer(bluemonday.New(nil))
+
+	err = box.AddTemplate("promo", templatebox.FileSet{
+		Filenames: []string{"promo.hbs", "footer.hbs"},
+		Engine:    raymond.New(),
+	})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	data := map[string]string{
+		"title":   "Sale & more",
+		"body":    `<p>Half price<script>alert(1)</script></p>`,
+		"company": "Acme",
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "promo", data); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	expected := `<h1>Sale &amp; more</h1><p>Half price</p><p>Acme</p>`
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+}
@@ -0,0 +1,39 @@
+// Package texttemplate provides a templatebox.Engine backed by text/template,
+// for templates whose output is not HTML, such as plain text emails or
+// configuration files. Output is not escaped.
+//
+//	box.AddTemplate("welcome.txt", templatebox.FileSet{
+//		Filenames: []string{"welcome.txt"},
+//		Engine:    texttemplate.New(),
+//	})
+package texttemplate
+
+import (
+	"text/template"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+// Engine parses templates with text/template.
+type Engine struct{}
+
+// New returns a text/template Engine.
+func New() *Engine {
+	return &Engine{}
+}
+
+// Parse implements templatebox.Engine. Sources are parsed in the same way
+// as template.ParseFiles.
+func (e *Engine) Parse(name string, srcs []templatebox.Source, funcs templatebox.FuncMap) (templatebox.Template, error) {
+	t := template.New(name).Funcs(template.FuncMap(funcs))
+	for _, src := range srcs {
+		tmpl := t
+		if src.Name != t.Name() {
+			tmpl = t.New(src.Name)
+		}
+		if _, err := tmpl.Parse(string(src.Text)); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,39 @@
+package texttemplate_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+	"github.com/andyfusniak/templatebox/adapter/texttemplate"
+)
+
+var _ templatebox.Engine = (*texttemplate.Engine)(nil)
+
+func TestRenderText(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("email", templatebox.TemplateSet{
+		Templates: []string{
+			`Hello {{ template "name" . }}, you have {{ len (list 1 2) }} messages.`,
+			`{{ define "name" }}{{ .Name }}{{ end }}`,
+		},
+		Engine: texttemplate.New(),
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "email", map[string]string{"Name": "<Ann>"}); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	expected := "Hello <Ann>, you have 2 messages."
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+}
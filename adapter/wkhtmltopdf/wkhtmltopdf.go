@@ -0,0 +1,50 @@
+// Package wkhtmltopdf provides a templatebox.Converter that turns rendered
+// HTML into PDF using the wkhtmltopdf command line tool.
+//
+//	pdf, err := box.RenderDocument(ctx, "invoice", data, wkhtmltopdf.New())
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// Converter runs the wkhtmltopdf binary, writing HTML to its standard input
+// and reading the PDF from its standard output.
+type Converter struct {
+	// Path is the wkhtmltopdf executable. Defaults to "wkhtmltopdf" looked
+	// up in PATH.
+	Path string
+
+	// Args are extra command line options placed before the input and
+	// output arguments, for example []string{"--page-size", "A4"}.
+	Args []string
+}
+
+// New returns a Converter using the wkhtmltopdf binary found in PATH.
+func New(args ...string) *Converter {
+	return &Converter{Path: "wkhtmltopdf", Args: args}
+}
+
+// Convert converts html to PDF. The process is killed if ctx is done before
+// it exits.
+func (c *Converter) Convert(ctx context.Context, html []byte) ([]byte, error) {
+	path := c.Path
+	if path == "" {
+		path = "wkhtmltopdf"
+	}
+
+	args := append(append([]string{"--quiet"}, c.Args...), "-", "-")
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin = bytes.NewReader(html)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("wkhtmltopdf failed: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,38 @@
+package wkhtmltopdf_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+	"github.com/andyfusniak/templatebox/adapter/wkhtmltopdf"
+)
+
+var _ templatebox.Converter = (*wkhtmltopdf.Converter)(nil)
+
+func TestConvertWithFakeBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binary is a shell script")
+	}
+
+	// the fake binary echoes its arguments followed by standard input
+	path := filepath.Join(t.TempDir(), "fake-wkhtmltopdf")
+	err := os.WriteFile(path, []byte("#!/bin/sh\necho \"$@\"\ncat\n"), 0755)
+	if err != nil {
+		t.Fatalf("os.WriteFile failed: %v", err)
+	}
+
+	c := &wkhtmltopdf.Converter{Path: path, Args: []string{"--page-size", "A4"}}
+	out, err := c.Convert(context.Background(), []byte("<h1>Invoice</h1>"))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+
+	expected := "--quiet --page-size A4 - -\n<h1>Invoice</h1>"
+	if string(out) != expected {
+		t.Fatalf("Convert returned %q, expected %q", out, expected)
+	}
+}
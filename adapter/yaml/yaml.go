@@ -0,0 +1,26 @@
+// Package yaml registers a templatebox data format for .yaml and .yml
+// files, decoded with go-yaml, so they are read by templatebox.LoadData,
+// SSGOptions.DataDir and example data files. Import it for its side
+// effect:
+//
+//	import _ "github.com/andyfusniak/templatebox/adapter/yaml"
+package yaml
+
+import (
+	"github.com/andyfusniak/templatebox"
+	goyaml "gopkg.in/yaml.v2"
+)
+
+func init() {
+	templatebox.RegisterDataFormat(".yaml", Decode)
+	templatebox.RegisterDataFormat(".yml", Decode)
+}
+
+// Decode decodes the YAML document b.
+func Decode(b []byte) (any, error) {
+	var v any
+	if err := goyaml.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,66 @@
+package yaml_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andyfusniak/templatebox"
+	_ "github.com/andyfusniak/templatebox/adapter/yaml"
+)
+
+func TestLoadData(t *testing.T) {
+	fsys := fstest.MapFS{
+		"shop/config.yaml": {Data: []byte("currency: GBP\nopen: true\nhours: [9, 17]\n")},
+		"products.yml":     {Data: []byte("- name: Mug\n  stock: 3\n")},
+	}
+
+	data, err := templatebox.LoadData(fsys)
+	if err != nil {
+		t.Fatalf("LoadData failed: %v", err)
+	}
+
+	expected := map[string]any{
+		"products": []any{
+			map[string]any{"name": "Mug", "stock": 3},
+		},
+		"shop": map[string]any{
+			"config": map[string]any{"currency": "GBP", "open": true, "hours": []any{9, 17}},
+		},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Fatalf("LoadData returned %#v, expected %#v", data, expected)
+	}
+}
+
+func TestPreviewExampleDataFile(t *testing.T) {
+	path := t.TempDir()
+	files := map[string]string{
+		"about.html":      `About {{ .Name }}`,
+		"about.data.yaml": "Name: Grace\n",
+	}
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(path, name), []byte(text), 0644); err != nil {
+			t.Fatalf("os.WriteFile failed: %v", err)
+		}
+	}
+
+	box, err := templatebox.NewBoxFromOSDir(path, nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplate("about", templatebox.FileSet{Filenames: []string{"about.html"}})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	got, err := box.Preview("about")
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+	if expected := "About Grace"; got != expected {
+		t.Fatalf("Preview returned %s, expected %s", got, expected)
+	}
+}
@@ -0,0 +1,231 @@
+package templatebox
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// TemplateInfo describes a registered template.
+type TemplateInfo struct {
+	Name         string     `json:"name"`
+	Output       string     `json:"output"`
+	Files        []string   `json:"files,omitempty"`
+	Defines      []string   `json:"defines"`
+	Hash         string     `json:"hash"`
+	LastRendered *time.Time `json:"lastRendered,omitempty"`
+}
+
+// Templates returns information about every registered template, sorted by
+// name. Files is empty for templates added with AddTemplateRaw.
+func (b *Box) Templates() []TemplateInfo {
+	sets := b.registeredFileSets()
+
+	names := b.Names()
+	infos := make([]TemplateInfo, 0, len(names))
+	for _, name := range names {
+		b.mu.RLock()
+		t, ok := b.html[name]
+		h := b.hashes[name]
+		out := b.opts[name].output
+		b.mu.RUnlock()
+		if !ok {
+			continue
+		}
+
+		info := TemplateInfo{
+			Name:    name,
+			Output:  out.String(),
+			Files:   sets[name].Filenames,
+			Defines: definedNames(t),
+			Hash:    h.Sum,
+		}
+		if last, ok := b.LastRendered(name); ok {
+			info.LastRendered = &last
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// definedNames returns the sorted names of the templates defined in t, or
+// nil if t was not parsed by html/template.
+func definedNames(t Template) []string {
+	ht, ok := t.(*template.Template)
+	if !ok {
+		return nil
+	}
+
+	var names []string
+	for _, d := range ht.Templates() {
+		names = append(names, d.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AdminHandler returns an http.Handler for inspecting and operating the Box
+// in production. Every endpoint is read-only except POST /reload, which
+// reloads the templates. Previews are rendered as with Preview, so they are
+// not counted by LastRendered and Unrendered, and are not passed to Tee or
+// recorded. It serves the following endpoints relative to the path it is
+// mounted on:
+//
+//	GET  /                 HTML index of the registered templates
+//	GET  /templates        JSON list of TemplateInfo
+//	GET  /graph            JSON map of each template file to the templates using it
+//	GET  /stats            JSON map of template name to last render time
+//	POST /reload           calls ReloadContext with the request context
+//	GET  /audit            JSON list of the AuditLog entries
+//	GET  /versions         JSON list of the Versions recorded by Reload
+//	GET  /diff             JSON VersionDiff of the versions in the old and new query parameters
+//	GET  /data/{name}      JSON of the redacted data recorded for the template
+//	GET  /preview/{name}   renders the template using Preview
+//	POST /preview/{name}   renders the template with the JSON request body as data
+//
+// The handler performs no authentication of its own. Mount it behind the
+// application's own auth middleware, for example:
+//
+//	mux.Handle("/admin/templates/", http.StripPrefix("/admin/templates", auth(box.AdminHandler())))
+//
+// The auth middleware can set the user on the request context with
+// WithActor to record them in the audit log of reloads.
+func (b *Box) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", b.adminIndex)
+	mux.HandleFunc("GET /templates", b.adminTemplates)
+	mux.HandleFunc("GET /graph", b.adminGraph)
+	mux.HandleFunc("GET /stats", b.adminStats)
+	mux.HandleFunc("POST /reload", b.adminReload)
+	mux.HandleFunc("GET /audit", b.adminAudit)
+	mux.HandleFunc("GET /versions", b.adminVersions)
+	mux.HandleFunc("GET /diff", b.adminDiff)
+	mux.HandleFunc("GET /data/{name}", b.adminData)
+	mux.HandleFunc("GET /preview/{name}", b.adminPreviewSample)
+	mux.HandleFunc("POST /preview/{name}", b.adminPreview)
+	return mux
+}
+
+var adminIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <title>templatebox</title>
+</head>
+<body>
+  <h1>Templates</h1>
+  <table>
+    <tr><th>Name</th><th>Output</th><th>Files</th><th>Last rendered</th></tr>
+    {{- range . }}
+    <tr><td>{{ .Name }}</td><td>{{ .Output }}</td><td>{{ range $i, $f := .Files }}{{ if $i }}, {{ end }}{{ $f }}{{ end }}</td><td>{{ with .LastRendered }}{{ .Format "2006-01-02 15:04:05" }}{{ else }}never{{ end }}</td></tr>
+    {{- end }}
+  </table>
+</body>
+</html>
+`))
+
+func (b *Box) adminIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := adminIndexTemplate.Execute(w, b.Templates()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (b *Box) adminTemplates(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, b.Templates())
+}
+
+func (b *Box) adminGraph(w http.ResponseWriter, r *http.Request) {
+	graph := make(map[string][]string)
+	for name, s := range b.registeredFileSets() {
+		for _, f := range s.Filenames {
+			graph[f] = append(graph[f], name)
+		}
+	}
+	for _, names := range graph {
+		sort.Strings(names)
+	}
+	writeJSON(w, http.StatusOK, graph)
+}
+
+func (b *Box) adminStats(w http.ResponseWriter, r *http.Request) {
+	stats := make(map[string]*time.Time)
+	for _, info := range b.Templates() {
+		stats[info.Name] = info.LastRendered
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func (b *Box) adminReload(w http.ResponseWriter, r *http.Request) {
+	if err := b.ReloadContext(r.Context()); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "reloaded"})
+}
+
+func (b *Box) adminAudit(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, b.AuditLog())
+}
+
+func (b *Box) adminVersions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, b.Versions())
+}
+
+func (b *Box) adminDiff(w http.ResponseWriter, r *http.Request) {
+	d, err := b.Diff(r.URL.Query().Get("old"), r.URL.Query().Get("new"))
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+func (b *Box) adminPreview(w http.ResponseWriter, r *http.Request) {
+	var data any
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON data: " + err.Error()})
+			return
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := b.renderQuiet(r.Context(), &buf, r.PathValue("name"), data); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+func (b *Box) adminData(w http.ResponseWriter, r *http.Request) {
+	data, ok := b.RecordedData(r.PathValue("name"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no data recorded"})
+		return
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+func (b *Box) adminPreviewSample(w http.ResponseWriter, r *http.Request) {
+	s, err := b.Preview(r.PathValue("name"))
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(s))
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
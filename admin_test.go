@@ -0,0 +1,105 @@
+package templatebox_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestAdminHandler(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateMap(map[string]templatebox.FileSet{
+		"a": {Filenames: []string{"layout.html", "a.html"}},
+		"b": {Filenames: []string{"layout.html", "b.html"}},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateMap failed: %v", err)
+	}
+	err = box.AddTemplateRaw("hello", templatebox.TemplateSet{
+		Templates: []string{`Hello {{ .Name }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	srv := httptest.NewServer(http.StripPrefix("/admin", box.AdminHandler()))
+	defer srv.Close()
+
+	// list templates
+	resp, err := http.Get(srv.URL + "/admin/templates")
+	if err != nil {
+		t.Fatalf("http.Get failed: %v", err)
+	}
+	var infos []templatebox.TemplateInfo
+	if err := json.NewDecoder(resp.Body).Decode(&infos); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	resp.Body.Close()
+	if len(infos) != 3 || infos[0].Name != "a" || infos[2].Name != "hello" {
+		t.Fatalf("GET /templates returned %+v", infos)
+	}
+	if !reflect.DeepEqual(infos[0].Defines, []string{"a.html", "content", "layout.html"}) {
+		t.Fatalf("GET /templates returned defines %v", infos[0].Defines)
+	}
+
+	// dependency graph
+	resp, err = http.Get(srv.URL + "/admin/graph")
+	if err != nil {
+		t.Fatalf("http.Get failed: %v", err)
+	}
+	var graph map[string][]string
+	if err := json.NewDecoder(resp.Body).Decode(&graph); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	resp.Body.Close()
+	if !reflect.DeepEqual(graph["layout.html"], []string{"a", "b"}) {
+		t.Fatalf("GET /graph returned %v", graph)
+	}
+
+	// preview
+	resp, err = http.Post(srv.URL+"/admin/preview/hello", "application/json", strings.NewReader(`{"Name":"admin"}`))
+	if err != nil {
+		t.Fatalf("http.Post failed: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("io.ReadAll failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "Hello admin" {
+		t.Fatalf("POST /preview/hello returned %d %s", resp.StatusCode, body)
+	}
+	if _, ok := box.LastRendered("hello"); ok {
+		t.Fatalf("LastRendered reported a render of hello after POST /preview/hello")
+	}
+
+	// reload
+	resp, err = http.Post(srv.URL+"/admin/reload", "", nil)
+	if err != nil {
+		t.Fatalf("http.Post failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST /reload returned %d", resp.StatusCode)
+	}
+
+	// reload is not allowed with GET
+	resp, err = http.Get(srv.URL + "/admin/reload")
+	if err != nil {
+		t.Fatalf("http.Get failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("GET /reload returned %d, expected %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
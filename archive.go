@@ -0,0 +1,229 @@
+package templatebox
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+)
+
+// archiveVersion is the version of the format written by Export.
+const archiveVersion = 1
+
+// archive is the gob encoded form of a Box written by Export.
+type archive struct {
+	Version int
+
+	// Files holds the content of every file used by a FileSet, keyed by
+	// the cleaned, slash separated filename.
+	Files map[string][]byte
+
+	FileSets     []archivedFileSet
+	TemplateSets []archivedTemplateSet
+}
+
+type archivedFileSet struct {
+	Name      string
+	Filenames []string
+	Meta      *Meta
+	Output    Output
+	Cache     *archivedCache
+}
+
+type archivedTemplateSet struct {
+	Name      string
+	Templates []string
+	Meta      *Meta
+	Output    Output
+	Cache     *archivedCache
+}
+
+type archivedCache struct {
+	TTL                  time.Duration
+	StaleWhileRevalidate time.Duration
+}
+
+// ImportOptions supplies the parts of a Box that Export cannot serialize:
+// functions, engines and configuration.
+type ImportOptions struct {
+	// Config is the configuration of the imported Box. If nil the default
+	// configuration is used.
+	Config *Config
+
+	// Prepare, if set, is called with the new Box before any template is
+	// parsed, to set the global FuncMap, register unsafe functions, or set
+	// the Sanitizer and other dependencies of the templates.
+	Prepare func(b *Box) error
+
+	// FileSet and TemplateSet, if set, are called for each template
+	// before it is parsed, to restore its FuncMap, Engine or Cache.Key.
+	FileSet     func(name string, s *FileSet)
+	TemplateSet func(name string, s *TemplateSet)
+}
+
+// Export writes the registered templates to w as a single gob encoded
+// archive, for ImportBox to load in another process without access to the
+// template directory. The archive contains the current content of every
+// file used by a FileSet, the template strings of every TemplateSet, and
+// the Meta, Output and Cache TTLs of each template.
+//
+// Parse trees cannot be serialized, so ImportBox parses the templates
+// again. FuncMaps, Engines and CacheOptions.Key functions are not exported
+// and must be restored through ImportOptions.
+func (b *Box) Export(w io.Writer) error {
+	a := archive{
+		Version: archiveVersion,
+		Files:   make(map[string][]byte),
+	}
+
+	fileSets := b.registeredFileSets()
+	for _, name := range sortedKeys(fileSets) {
+		s := fileSets[name]
+		for _, filename := range s.Filenames {
+			key := archiveKey(filename)
+			if _, ok := a.Files[key]; ok {
+				continue
+			}
+			text, err := b.readFile(filename)
+			if err != nil {
+				return fmt.Errorf("export template %s: %w", name, err)
+			}
+			a.Files[key] = text
+		}
+		a.FileSets = append(a.FileSets, archivedFileSet{
+			Name:      name,
+			Filenames: s.Filenames,
+			Meta:      s.Meta,
+			Output:    s.Output,
+			Cache:     archiveCache(s.Cache),
+		})
+	}
+
+	b.muFileSets.RLock()
+	rawSets := make(map[string]TemplateSet, len(b.rawSets))
+	for k, v := range b.rawSets {
+		rawSets[k] = v
+	}
+	b.muFileSets.RUnlock()
+
+	for _, name := range sortedKeys(rawSets) {
+		s := rawSets[name]
+		a.TemplateSets = append(a.TemplateSets, archivedTemplateSet{
+			Name:      name,
+			Templates: s.Templates,
+			Meta:      s.Meta,
+			Output:    s.Output,
+			Cache:     archiveCache(s.Cache),
+		})
+	}
+
+	if err := gob.NewEncoder(w).Encode(&a); err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+	return nil
+}
+
+// ImportBox reads an archive written by Export and returns a new Box with
+// its templates added. Template files are read from the archive, so the
+// Box needs no template directory and is never rebuilt in debug mode.
+func ImportBox(r io.Reader, opts *ImportOptions) (*Box, error) {
+	if opts == nil {
+		opts = &ImportOptions{}
+	}
+	cfg := opts.Config
+	if cfg == nil {
+		cfg = defaultConfig
+	}
+
+	var a archive
+	if err := gob.NewDecoder(r).Decode(&a); err != nil {
+		return nil, fmt.Errorf("import failed: %w", err)
+	}
+	if a.Version != archiveVersion {
+		return nil, fmt.Errorf("import failed: unsupported archive version %d", a.Version)
+	}
+
+	box := newBox(cfg)
+	box.files = a.Files
+	if box.files == nil {
+		box.files = make(map[string][]byte)
+	}
+
+	if opts.Prepare != nil {
+		if err := opts.Prepare(box); err != nil {
+			return nil, fmt.Errorf("import failed: %w", err)
+		}
+	}
+
+	for _, as := range a.FileSets {
+		s := FileSet{
+			Filenames: as.Filenames,
+			Meta:      as.Meta,
+			Output:    as.Output,
+			Cache:     as.Cache.options(),
+		}
+		if opts.FileSet != nil {
+			opts.FileSet(as.Name, &s)
+		}
+		if err := box.AddTemplate(as.Name, s); err != nil {
+			return nil, fmt.Errorf("import template %s: %w", as.Name, err)
+		}
+	}
+	for _, as := range a.TemplateSets {
+		s := TemplateSet{
+			Templates: as.Templates,
+			Meta:      as.Meta,
+			Output:    as.Output,
+			Cache:     as.Cache.options(),
+		}
+		if opts.TemplateSet != nil {
+			opts.TemplateSet(as.Name, &s)
+		}
+		if err := box.AddTemplateRaw(as.Name, s); err != nil {
+			return nil, fmt.Errorf("import template %s: %w", as.Name, err)
+		}
+	}
+	return box, nil
+}
+
+// readArchiveFile returns the content of the filename from the archive the
+// Box was imported from.
+func (b *Box) readArchiveFile(filename string) ([]byte, error) {
+	text, ok := b.files[archiveKey(filename)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: filename, Err: fs.ErrNotExist}
+	}
+	return text, nil
+}
+
+// archiveKey returns the key of the filename in archive.Files.
+func archiveKey(filename string) string {
+	return path.Clean(slashPath(filename))
+}
+
+func archiveCache(o *CacheOptions) *archivedCache {
+	if o == nil {
+		return nil
+	}
+	return &archivedCache{TTL: o.TTL, StaleWhileRevalidate: o.StaleWhileRevalidate}
+}
+
+func (c *archivedCache) options() *CacheOptions {
+	if c == nil {
+		return nil
+	}
+	return &CacheOptions{TTL: c.TTL, StaleWhileRevalidate: c.StaleWhileRevalidate}
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,82 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestExportImport(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplates(t, dir, map[string]string{
+		"layout.html":     `<main>{{ template "content" . }}</main>`,
+		"pages/home.html": `{{ define "content" }}{{ shout .Name }}{{ end }}`,
+	})
+
+	box, err := templatebox.NewBoxFromOSDir(dir, nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetGlobalFuncMap(templatebox.FuncMap{"shout": strings.ToUpper})
+
+	err = box.AddTemplate("home", templatebox.FileSet{
+		Filenames: []string{"layout.html", "pages/home.html"},
+		Output:    templatebox.OutputFragment,
+	})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+	err = box.AddTemplateRaw("greet", templatebox.TemplateSet{
+		Templates: []string{`Hi {{ tag .Name }}`},
+		FuncMap:   templatebox.FuncMap{"tag": func(s string) string { return "@" + s }},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var archive bytes.Buffer
+	if err := box.Export(&archive); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	imported, err := templatebox.ImportBox(&archive, &templatebox.ImportOptions{
+		Prepare: func(b *templatebox.Box) error {
+			b.SetGlobalFuncMap(templatebox.FuncMap{"shout": strings.ToUpper})
+			return nil
+		},
+		TemplateSet: func(name string, s *templatebox.TemplateSet) {
+			s.FuncMap = templatebox.FuncMap{"tag": func(s string) string { return "@" + s }}
+		},
+	})
+	if err != nil {
+		t.Fatalf("ImportBox failed: %v", err)
+	}
+
+	data := map[string]string{"Name": "ann"}
+	for name, expected := range map[string]string{
+		"home":  "<main>ANN</main>",
+		"greet": "Hi @ann",
+	} {
+		var buf bytes.Buffer
+		if err := imported.RenderHTML(&buf, name, data); err != nil {
+			t.Fatalf("RenderHTML %s failed: %v", name, err)
+		}
+		if buf.String() != expected {
+			t.Fatalf("RenderHTML %s returned %s, expected %s", name, buf.String(), expected)
+		}
+	}
+
+	if out, _ := imported.OutputOf("home"); out != templatebox.OutputFragment {
+		t.Fatalf("OutputOf returned %v, expected %v", out, templatebox.OutputFragment)
+	}
+
+	missing, err := imported.MissingFiles()
+	if err != nil {
+		t.Fatalf("MissingFiles failed: %v", err)
+	}
+	if len(missing) != 0 {
+		t.Fatalf("MissingFiles returned %v, expected none", missing)
+	}
+}
@@ -0,0 +1,170 @@
+package templatebox
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Assets configures how the asset, srcset and imgTag template functions
+// turn asset paths into URLs.
+type Assets struct {
+	// BaseURL is prepended to every asset path, for example
+	// "https://cdn.example.com/static".
+	BaseURL string
+
+	// Manifest optionally maps logical asset paths to published paths, such
+	// as the fingerprinted filenames written by a frontend build step.
+	// Paths not found in the Manifest are used unchanged.
+	Manifest map[string]string
+
+	// Resize returns the URL of the given asset URL resized to width
+	// pixels. If nil a "w" query parameter is added to the URL, which is
+	// the convention used by most image CDNs.
+	Resize func(assetURL string, width int) string
+}
+
+// Image describes an image rendered by the imgTag template function. Widths
+// lists the widths of the resized variants to include in the srcset
+// attribute.
+type Image struct {
+	Src    string
+	Alt    string
+	Width  int
+	Height int
+	Widths []int
+	Sizes  string
+	Class  string
+}
+
+// SetAssets sets the asset configuration used by the asset, srcset and
+// imgTag template functions.
+func (b *Box) SetAssets(a Assets) {
+	b.assets = a
+}
+
+// assetURL implements the asset template function. It resolves p through
+// the Manifest and prepends the BaseURL.
+func (b *Box) assetURL(p string) string {
+	if m, ok := b.assets.Manifest[p]; ok {
+		p = m
+	}
+	if b.assets.BaseURL == "" {
+		return p
+	}
+	return strings.TrimSuffix(b.assets.BaseURL, "/") + "/" + strings.TrimPrefix(p, "/")
+}
+
+// resize returns the URL of the variant of u with the given width.
+func (b *Box) resize(u string, width int) string {
+	if b.assets.Resize != nil {
+		return b.assets.Resize(u, width)
+	}
+
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+	q := parsed.Query()
+	q.Set("w", strconv.Itoa(width))
+	parsed.RawQuery = q.Encode()
+	return parsed.String()
+}
+
+// srcsetValue returns the srcset attribute value for src at each width.
+func (b *Box) srcsetValue(src string, widths []int) string {
+	u := b.assetURL(src)
+
+	parts := make([]string, len(widths))
+	for i, w := range widths {
+		parts[i] = fmt.Sprintf("%s %dw", b.resize(u, w), w)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// srcset implements the srcset template function. The image may be a path
+// string or an Image, for example {{ srcset .Photo 320 640 1280 }}.
+func (b *Box) srcset(img any, widths ...int) (template.Srcset, error) {
+	src, err := imageSrc(img)
+	if err != nil {
+		return "", err
+	}
+	if len(widths) == 0 {
+		return "", fmt.Errorf("srcset expects at least one width")
+	}
+	return template.Srcset(b.srcsetValue(src, widths)), nil
+}
+
+// imgTag implements the imgTag template function. It renders a complete
+// <img> element for a path string or an Image, including srcset, sizes and
+// lazy loading when the Image lists variant widths.
+func (b *Box) imgTag(img any) (template.HTML, error) {
+	var im Image
+	switch v := img.(type) {
+	case Image:
+		im = v
+	case *Image:
+		if v == nil {
+			return "", fmt.Errorf("imgTag called with a nil *Image")
+		}
+		im = *v
+	case string:
+		im = Image{Src: v}
+	default:
+		return "", fmt.Errorf("imgTag expects a string or Image, got %T", img)
+	}
+	if im.Src == "" {
+		return "", fmt.Errorf("imgTag called with an empty image source")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<img src="`)
+	sb.WriteString(html.EscapeString(b.assetURL(im.Src)))
+	sb.WriteString(`" alt="`)
+	sb.WriteString(html.EscapeString(im.Alt))
+	sb.WriteString(`"`)
+	if len(im.Widths) > 0 {
+		sb.WriteString(` srcset="`)
+		sb.WriteString(html.EscapeString(b.srcsetValue(im.Src, im.Widths)))
+		sb.WriteString(`"`)
+		sizes := im.Sizes
+		if sizes == "" {
+			sizes = "100vw"
+		}
+		sb.WriteString(` sizes="`)
+		sb.WriteString(html.EscapeString(sizes))
+		sb.WriteString(`"`)
+	}
+	if im.Width > 0 {
+		fmt.Fprintf(&sb, ` width="%d"`, im.Width)
+	}
+	if im.Height > 0 {
+		fmt.Fprintf(&sb, ` height="%d"`, im.Height)
+	}
+	if im.Class != "" {
+		sb.WriteString(` class="`)
+		sb.WriteString(html.EscapeString(im.Class))
+		sb.WriteString(`"`)
+	}
+	sb.WriteString(` loading="lazy">`)
+	return template.HTML(sb.String()), nil
+}
+
+// imageSrc returns the source path of a string or Image.
+func imageSrc(img any) (string, error) {
+	switch v := img.(type) {
+	case string:
+		return v, nil
+	case Image:
+		return v.Src, nil
+	case *Image:
+		if v == nil {
+			return "", fmt.Errorf("nil *Image")
+		}
+		return v.Src, nil
+	}
+	return "", fmt.Errorf("expected a string or Image, got %T", img)
+}
@@ -0,0 +1,57 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestAssetFuncs(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	box.SetAssets(templatebox.Assets{
+		BaseURL: "https://cdn.example.com/",
+		Manifest: map[string]string{
+			"css/app.css": "css/app.3f2a1c.css",
+		},
+	})
+
+	err = box.AddTemplateRaw("assets", templatebox.TemplateSet{
+		Templates: []string{
+			`<link href="{{ asset "css/app.css" }}">` +
+				`<img srcset="{{ srcset "a.jpg" 320 640 }}">` +
+				`{{ imgTag .Photo }}`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	data := map[string]any{
+		"Photo": templatebox.Image{
+			Src:    "b.jpg",
+			Alt:    `Tom & "Jerry"`,
+			Width:  640,
+			Height: 480,
+			Widths: []int{320, 640},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "assets", data); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	expected := `<link href="https://cdn.example.com/css/app.3f2a1c.css">` +
+		`<img srcset="https://cdn.example.com/a.jpg?w=320 320w, https://cdn.example.com/a.jpg?w=640 640w">` +
+		`<img src="https://cdn.example.com/b.jpg" alt="Tom &amp; &#34;Jerry&#34;" ` +
+		`srcset="https://cdn.example.com/b.jpg?w=320 320w, https://cdn.example.com/b.jpg?w=640 640w" ` +
+		`sizes="100vw" width="640" height="480" loading="lazy">`
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+}
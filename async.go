@@ -0,0 +1,90 @@
+package templatebox
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+// RenderFuture is the pending result of a render started with RenderAsync.
+type RenderFuture struct {
+	done   chan struct{}
+	result RenderResult
+}
+
+// Done returns a channel that is closed when the render has finished.
+func (f *RenderFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Result waits for the render to finish and returns its result.
+func (f *RenderFuture) Result() RenderResult {
+	<-f.done
+	return f.result
+}
+
+// asyncJob is a render queued by RenderAsync.
+type asyncJob struct {
+	ctx    context.Context
+	job    RenderJob
+	future *RenderFuture
+}
+
+// RenderAsync queues the named template to be rendered on the Box worker
+// pool and returns immediately, for output such as email bodies and webhook
+// payloads that should not hold up the request path. The worker pool is
+// started by the first call, has Config.AsyncWorkers goroutines and is
+// stopped by Close.
+//
+// ctx is used both while waiting for room in the queue and for the render
+// itself, so a render started from an HTTP handler that should outlive the
+// request must be given context.WithoutCancel(r.Context()). After Close
+// the result reports ErrClosed.
+func (b *Box) RenderAsync(ctx context.Context, name string, data any) *RenderFuture {
+	f := &RenderFuture{done: make(chan struct{})}
+	fail := func(err error) *RenderFuture {
+		f.result = RenderResult{Name: name, Err: err}
+		close(f.done)
+		return f
+	}
+
+	b.muAsync.RLock()
+	defer b.muAsync.RUnlock()
+	if b.closed {
+		return fail(fmt.Errorf("%w: rendering %s", ErrClosed, name))
+	}
+	b.asyncOnce.Do(b.startAsync)
+
+	select {
+	case b.asyncJobs <- asyncJob{ctx: ctx, job: RenderJob{Name: name, Data: data}, future: f}:
+		return f
+	case <-ctx.Done():
+		return fail(ctx.Err())
+	case <-b.closing:
+		return fail(fmt.Errorf("%w: rendering %s", ErrClosed, name))
+	}
+}
+
+// startAsync starts the worker pool used by RenderAsync.
+func (b *Box) startAsync() {
+	workers := b.cfg.AsyncWorkers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	queue := b.cfg.AsyncQueueSize
+	if queue <= 0 {
+		queue = 64 * workers
+	}
+
+	b.asyncJobs = make(chan asyncJob, queue)
+	b.background.Add(workers)
+	for range workers {
+		go func() {
+			defer b.background.Done()
+			for j := range b.asyncJobs {
+				j.future.result = b.renderJob(j.ctx, j.job)
+				close(j.future.done)
+			}
+		}()
+	}
+}
@@ -0,0 +1,45 @@
+package templatebox_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestRenderAsync(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		AsyncWorkers: 2,
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("email", templatebox.TemplateSet{
+		Templates: []string{`Welcome {{ .Name }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	f := box.RenderAsync(context.Background(), "email", map[string]string{"Name": "Andy"})
+	<-f.Done()
+	r := f.Result()
+	if r.Err != nil {
+		t.Fatalf("RenderAsync failed: %v", r.Err)
+	}
+	expected := "Welcome Andy"
+	if string(r.Output) != expected {
+		t.Fatalf("RenderAsync returned %s, expected %s", r.Output, expected)
+	}
+
+	if r := box.RenderAsync(context.Background(), "missing", nil).Result(); r.Err == nil {
+		t.Fatalf("RenderAsync of a missing template succeeded, expected an error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if r := box.RenderAsync(ctx, "email", nil).Result(); r.Err == nil {
+		t.Fatalf("RenderAsync with a cancelled context succeeded, expected an error")
+	}
+}
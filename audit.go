@@ -0,0 +1,323 @@
+package templatebox
+
+import (
+	"fmt"
+	"strings"
+	"text/template/parse"
+)
+
+// Sink contexts reported in Sink.Context.
+const (
+	// SinkScript is an action inside a <script> element.
+	SinkScript = "script"
+
+	// SinkEventHandler is an action inside an on* attribute value such as
+	// onclick.
+	SinkEventHandler = "event-handler"
+
+	// SinkStyle is an action inside a <style> element or a style
+	// attribute value.
+	SinkStyle = "style"
+
+	// SinkJavaScriptURL is an action inside an attribute value that
+	// starts with javascript:.
+	SinkJavaScriptURL = "javascript-url"
+)
+
+// Sink is a place where a template interpolates data into a JavaScript or
+// CSS context. html/template escapes these contexts, but they are where a
+// mistake or an escaping bypass is most dangerous, so security reviews
+// usually want them listed.
+type Sink struct {
+	// Template is the name of the registered template the sink was found
+	// in. Sinks in files shared by several templates are reported once,
+	// against the first template in name order.
+	Template string
+
+	// Location is the file or template name, line and column of the
+	// action, for example "a.html:3:12".
+	Location string
+
+	// Context is one of SinkScript, SinkEventHandler, SinkStyle or
+	// SinkJavaScriptURL.
+	Context string
+
+	// Attr is the lower-cased attribute name for sinks inside an
+	// attribute value and empty otherwise.
+	Attr string
+
+	// Action is the source of the action, for example {{.Name}}.
+	Action string
+}
+
+// Sinks parses the source of every registered template and returns the
+// actions that interpolate data into <script> or <style> elements, on*
+// or style attributes, or javascript: URLs. The sinks are sorted by
+// template name and then by position within each source.
+//
+// The HTML is scanned without executing the templates, so the context is
+// tracked through the text of each template in source order and both
+// branches of an if, with or range start from the context before it.
+// Templates parsed by an Engine other than html/template are skipped.
+func (b *Box) Sinks() ([]Sink, error) {
+	var sinks []Sink
+	seen := make(map[string]bool)
+	for _, name := range b.htmlNames() {
+		srcs, err := b.templateSources(name)
+		if err != nil {
+			return nil, fmt.Errorf("audit template %s: %w", name, err)
+		}
+		trees, err := parseTrees(srcs)
+		if err != nil {
+			return nil, fmt.Errorf("audit template %s: %w", name, err)
+		}
+
+		for _, tree := range sortedTrees(trees) {
+			a := &auditor{tree: tree}
+			a.walkList(tree.Root)
+			for _, sink := range a.sinks {
+				if seen[sink.Location] {
+					continue
+				}
+				seen[sink.Location] = true
+				sink.Template = name
+				sinks = append(sinks, sink)
+			}
+		}
+	}
+	return sinks, nil
+}
+
+// htmlState is the position of the scanner within the HTML text.
+type htmlState int
+
+const (
+	stText htmlState = iota
+	stTagName
+	stTag
+	stAttrName
+	stAfterAttrName
+	stBeforeValue
+	stValue
+	stRaw
+	stComment
+)
+
+// htmlContext is the HTML context at a point in a template.
+type htmlContext struct {
+	state   htmlState
+	tag     string // name of the tag being read
+	closing bool   // the tag is an end tag
+	attr    string // name of the current attribute
+	quote   byte   // quote of the attribute value, 0 when unquoted
+	value   string // the start of the attribute value
+	raw     string // script or style while inside that element
+}
+
+// maxValuePrefix is how much of an attribute value is kept to detect
+// javascript: URLs.
+const maxValuePrefix = len("javascript:") + 8
+
+// auditor walks a single parse tree tracking the HTML context.
+type auditor struct {
+	tree  *parse.Tree
+	ctx   htmlContext
+	sinks []Sink
+}
+
+func (a *auditor) walkList(list *parse.ListNode) {
+	if list == nil {
+		return
+	}
+	for _, n := range list.Nodes {
+		a.walk(n)
+	}
+}
+
+func (a *auditor) walk(n parse.Node) {
+	switch n := n.(type) {
+	case *parse.TextNode:
+		a.ctx = a.ctx.scan(string(n.Text))
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) > 0 {
+			return
+		}
+		a.check(n)
+	case *parse.IfNode:
+		a.walkBranch(&n.BranchNode)
+	case *parse.WithNode:
+		a.walkBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		a.walkBranch(&n.BranchNode)
+	}
+}
+
+func (a *auditor) walkBranch(n *parse.BranchNode) {
+	before := a.ctx
+	a.walkList(n.List)
+	after := a.ctx
+
+	if n.ElseList != nil {
+		a.ctx = before
+		a.walkList(n.ElseList)
+	}
+	a.ctx = after
+}
+
+// check records n if it is in a risky context.
+func (a *auditor) check(n *parse.ActionNode) {
+	ctx := a.ctx
+	if ctx.state == stBeforeValue {
+		// An action straight after the = starts an unquoted value.
+		a.ctx.state = stValue
+		a.ctx.quote = 0
+		a.ctx.value = ""
+		ctx = a.ctx
+	}
+
+	var kind, attr string
+	switch {
+	case ctx.state == stRaw && ctx.raw == "script":
+		kind = SinkScript
+	case ctx.state == stRaw && ctx.raw == "style":
+		kind = SinkStyle
+	case ctx.state == stValue && strings.HasPrefix(ctx.attr, "on"):
+		kind, attr = SinkEventHandler, ctx.attr
+	case ctx.state == stValue && ctx.attr == "style":
+		kind, attr = SinkStyle, ctx.attr
+	case ctx.state == stValue && strings.HasPrefix(strings.ToLower(strings.TrimSpace(ctx.value)), "javascript:"):
+		kind, attr = SinkJavaScriptURL, ctx.attr
+	}
+	if ctx.state == stValue {
+		a.ctx.value = appendValue(a.ctx.value, "x")
+	}
+	if kind == "" {
+		return
+	}
+
+	loc, _ := a.tree.ErrorContext(n)
+	a.sinks = append(a.sinks, Sink{
+		Location: loc,
+		Context:  kind,
+		Attr:     attr,
+		Action:   n.String(),
+	})
+}
+
+// scan returns the context after the text s.
+func (c htmlContext) scan(s string) htmlContext {
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch c.state {
+		case stText:
+			switch {
+			case strings.HasPrefix(s[i:], "<!--"):
+				c.state = stComment
+				i += len("<!--") - 1
+			case ch == '<' && i+1 < len(s) && isASCIILetter(s[i+1]):
+				c.state, c.tag, c.closing = stTagName, "", false
+			case ch == '<' && i+2 < len(s) && s[i+1] == '/' && isASCIILetter(s[i+2]):
+				c.state, c.tag, c.closing = stTagName, "", true
+				i++
+			}
+		case stTagName:
+			switch {
+			case ch == '>':
+				c = c.endTag()
+			case isSpace(ch) || ch == '/':
+				c.state = stTag
+			default:
+				c.tag += strings.ToLower(string(ch))
+			}
+		case stTag:
+			switch {
+			case ch == '>':
+				c = c.endTag()
+			case isSpace(ch) || ch == '/':
+			default:
+				c.state, c.attr = stAttrName, strings.ToLower(string(ch))
+			}
+		case stAttrName:
+			switch {
+			case ch == '>':
+				c = c.endTag()
+			case ch == '=':
+				c.state = stBeforeValue
+			case isSpace(ch):
+				c.state = stAfterAttrName
+			default:
+				c.attr += strings.ToLower(string(ch))
+			}
+		case stAfterAttrName:
+			switch {
+			case ch == '>':
+				c = c.endTag()
+			case ch == '=':
+				c.state = stBeforeValue
+			case isSpace(ch) || ch == '/':
+			default:
+				c.state, c.attr = stAttrName, strings.ToLower(string(ch))
+			}
+		case stBeforeValue:
+			switch {
+			case ch == '>':
+				c = c.endTag()
+			case ch == '"' || ch == '\'':
+				c.state, c.quote, c.value = stValue, ch, ""
+			case isSpace(ch):
+			default:
+				c.state, c.quote, c.value = stValue, 0, string(ch)
+			}
+		case stValue:
+			switch {
+			case c.quote != 0 && ch == c.quote:
+				c.state = stTag
+			case c.quote == 0 && isSpace(ch):
+				c.state = stTag
+			case c.quote == 0 && ch == '>':
+				c = c.endTag()
+			default:
+				c.value = appendValue(c.value, string(ch))
+			}
+		case stRaw:
+			end := "</" + c.raw
+			if len(s)-i >= len(end) && strings.EqualFold(s[i:i+len(end)], end) {
+				c.state, c.tag, c.closing = stTagName, c.raw, true
+				c.raw = ""
+				i += len(end) - 1
+			}
+		case stComment:
+			if strings.HasPrefix(s[i:], "-->") {
+				c.state = stText
+				i += len("-->") - 1
+			}
+		}
+	}
+	return c
+}
+
+// endTag returns the context after the > of a tag.
+func (c htmlContext) endTag() htmlContext {
+	c.state = stText
+	if !c.closing && (c.tag == "script" || c.tag == "style") {
+		c.state, c.raw = stRaw, c.tag
+	}
+	c.attr, c.value = "", ""
+	return c
+}
+
+// appendValue appends s to the attribute value prefix v.
+func appendValue(v, s string) string {
+	if len(v) >= maxValuePrefix {
+		return v
+	}
+	return v + s
+}
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
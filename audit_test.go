@@ -0,0 +1,50 @@
+package templatebox_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestSinks(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("page", templatebox.TemplateSet{
+		Templates: []string{
+			`<p title="{{ .Title }}">{{ .Body }}</p>` + "\n" +
+				`<script>var user = {{ .User }};</script>` + "\n" +
+				`<button onclick="go({{ .ID }})" style="color: {{ .Color }}">Go</button>` + "\n" +
+				`<a href="javascript:open({{ .URL }})">x</a><a href="{{ .URL }}">y</a>` + "\n" +
+				`<style>p { color: {{ .Color }} }</style><!-- <script>{{ .Hidden }}</script> -->`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	sinks, err := box.Sinks()
+	if err != nil {
+		t.Fatalf("Sinks failed: %v", err)
+	}
+
+	var got []string
+	for _, s := range sinks {
+		got = append(got, fmt.Sprintf("%s %s %s %s %s", s.Template, s.Location, s.Context, s.Attr, s.Action))
+	}
+
+	expected := []string{
+		"page page:2:22 script  {{.User}}",
+		"page page:3:23 event-handler onclick {{.ID}}",
+		"page page:3:49 style style {{.Color}}",
+		"page page:4:28 javascript-url href {{.URL}}",
+		"page page:5:21 style  {{.Color}}",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("Sinks returned\n%s\nexpected\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+}
@@ -0,0 +1,73 @@
+package templatebox
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"sync"
+)
+
+// RenderJob is a single render request passed to RenderMany.
+type RenderJob struct {
+	Name string
+	Data any
+}
+
+// RenderResult is the outcome of a RenderJob. Output holds the rendered
+// bytes if Err is nil.
+type RenderResult struct {
+	Name   string
+	Output []byte
+	Err    error
+}
+
+// RenderMany renders every job and returns one RenderResult per job in the
+// same order as jobs. Jobs are rendered concurrently using up to GOMAXPROCS
+// goroutines. A failing job does not affect the others; its error is
+// reported in its own RenderResult. Jobs not yet started when ctx is done
+// are not rendered and report ctx.Err().
+func (b *Box) RenderMany(ctx context.Context, jobs []RenderJob) []RenderResult {
+	results := make([]RenderResult, len(jobs))
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = b.renderJob(ctx, jobs[i])
+			}
+		}()
+	}
+
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return results
+}
+
+// renderJob renders a single job into a new buffer.
+func (b *Box) renderJob(ctx context.Context, job RenderJob) RenderResult {
+	r := RenderResult{Name: job.Name}
+	if err := ctx.Err(); err != nil {
+		r.Err = err
+		return r
+	}
+
+	var buf bytes.Buffer
+	if err := b.RenderHTMLContext(ctx, &buf, job.Name, job.Data); err != nil {
+		r.Err = err
+		return r
+	}
+	r.Output = buf.Bytes()
+	return r
+}
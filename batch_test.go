@@ -0,0 +1,66 @@
+package templatebox_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestRenderMany(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("greeting", templatebox.TemplateSet{
+		Templates: []string{`Hello {{ .Name }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var jobs []templatebox.RenderJob
+	for i := range 20 {
+		jobs = append(jobs, templatebox.RenderJob{
+			Name: "greeting",
+			Data: map[string]string{"Name": fmt.Sprintf("user%d", i)},
+		})
+	}
+	jobs = append(jobs, templatebox.RenderJob{Name: "missing"})
+
+	results := box.RenderMany(context.Background(), jobs)
+	if len(results) != len(jobs) {
+		t.Fatalf("RenderMany returned %d results, expected %d", len(results), len(jobs))
+	}
+
+	for i := range 20 {
+		if results[i].Err != nil {
+			t.Fatalf("job %d failed: %v", i, results[i].Err)
+		}
+		expected := fmt.Sprintf("Hello user%d", i)
+		if string(results[i].Output) != expected {
+			t.Fatalf("job %d returned %s, expected %s", i, results[i].Output, expected)
+		}
+	}
+
+	if results[20].Err == nil {
+		t.Fatalf("job for missing template succeeded, expected an error")
+	}
+}
+
+func TestRenderManyCanceled(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := box.RenderMany(ctx, []templatebox.RenderJob{{Name: "a"}})
+	if results[0].Err != context.Canceled {
+		t.Fatalf("RenderMany returned %v, expected %v", results[0].Err, context.Canceled)
+	}
+}
@@ -0,0 +1,133 @@
+// Package boxhttp provides HTTP helpers for serving templates from a
+// templatebox.Box.
+//
+//	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+//		if err := boxhttp.Render(w, r, box, http.StatusOK, "home", data); err != nil {
+//			http.Error(w, "internal error", http.StatusInternalServerError)
+//		}
+//	})
+//	http.ListenAndServe(":8080", boxhttp.AccessLog(box)(mux))
+package boxhttp
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+// Render renders the named template and writes it to w with the given
+// status code, setting the Content-Type to HTML if it is not already set.
+// The template is rendered into a buffer first, so if rendering fails
+// nothing is written and the caller can still send an error response.
+func Render(w http.ResponseWriter, r *http.Request, box *templatebox.Box, status int, name string, data any) error {
+	if e, ok := r.Context().Value(entryKey{}).(*entry); ok {
+		e.template = name
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTMLContext(r.Context(), &buf, name, data); err != nil {
+		return err
+	}
+
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+	w.WriteHeader(status)
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// IsHTMX reports whether r was sent by htmx, other than a request of a
+// boosted link or form, which expects a full page.
+func IsHTMX(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true" && r.Header.Get("HX-Boosted") != "true"
+}
+
+// RenderHTMX renders the fragment of the named page for htmx requests, as
+// reported by IsHTMX, and the full page otherwise. The fragment is the
+// template defined in the page under the name fragment, rendered with the
+// templatebox name "page#fragment". The Vary header is set so that caches
+// keep the two responses apart.
+//
+//	// contacts.html defines {{ define "rows" }}...{{ end }}
+//	err := boxhttp.RenderHTMX(w, r, box, http.StatusOK, "contacts", "rows", data)
+func RenderHTMX(w http.ResponseWriter, r *http.Request, box *templatebox.Box, status int, page, fragment string, data any) error {
+	w.Header().Add("Vary", "HX-Request")
+	name := page
+	if IsHTMX(r) {
+		name = page + "#" + fragment
+	}
+	return Render(w, r, box, status, name, data)
+}
+
+// entryKey is the context key of the access log entry of a request.
+type entryKey struct{}
+
+// entry collects the access log fields of a request.
+type entry struct {
+	template string
+}
+
+// AccessLog returns middleware that logs the template name, status code,
+// latency and response size of every response rendered with Render,
+// through the Logger of the box configuration or slog.Default(). Responses
+// that did not render a template are not logged.
+func AccessLog(box *templatebox.Box) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			e := &entry{}
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), entryKey{}, e)))
+
+			if e.template == "" {
+				return
+			}
+			logger := box.Config().Logger
+			if logger == nil {
+				logger = slog.Default()
+			}
+			logger.LogAttrs(r.Context(), slog.LevelInfo, "templatebox: response",
+				slog.String("template", e.template),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", rec.status),
+				slog.Int64("bytes", rec.bytes),
+				slog.Duration("duration", time.Since(start)),
+			)
+		})
+	}
+}
+
+// recorder is an http.ResponseWriter recording the status code and the
+// number of bytes written.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *recorder) Write(p []byte) (int, error) {
+	rec.wroteHeader = true
+	n, err := rec.ResponseWriter.Write(p)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (rec *recorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
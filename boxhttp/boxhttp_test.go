@@ -0,0 +1,104 @@
+package boxhttp_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+	"github.com/andyfusniak/templatebox/boxhttp"
+)
+
+func TestAccessLog(t *testing.T) {
+	var logs bytes.Buffer
+	box, err := templatebox.NewBoxFromOSDir(t.TempDir(), &templatebox.Config{
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("missing", templatebox.TemplateSet{Templates: []string{`Not found: {{ . }}`}})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		if err := boxhttp.Render(w, r, box, http.StatusNotFound, "missing", "x"); err != nil {
+			t.Errorf("Render failed: %v", err)
+		}
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("plain"))
+	})
+	handler := boxhttp.AccessLog(box)(mux)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/page", nil))
+	if rec.Code != http.StatusNotFound || rec.Body.String() != "Not found: x" {
+		t.Fatalf("response was %d %q, expected 404 %q", rec.Code, rec.Body.String(), "Not found: x")
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Fatalf("Content-Type was %s, expected text/html; charset=utf-8", ct)
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/plain", nil))
+
+	out := logs.String()
+	for _, s := range []string{"template=missing", "path=/page", "status=404", "bytes=12", "duration="} {
+		if !strings.Contains(out, s) {
+			t.Fatalf("log %q does not contain %s", out, s)
+		}
+	}
+	if strings.Contains(out, "/plain") {
+		t.Fatalf("log %q contains the untemplated response", out)
+	}
+}
+
+func TestRenderHTMX(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("contacts", templatebox.TemplateSet{
+		Templates: []string{
+			`<html><table>{{ template "rows" . }}</table></html>`,
+			`{{ define "rows" }}{{ range . }}<tr><td>{{ . }}</td></tr>{{ end }}{{ end }}`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := boxhttp.RenderHTMX(w, r, box, http.StatusOK, "contacts", "rows", []string{"Ada"}); err != nil {
+			t.Errorf("RenderHTMX failed: %v", err)
+		}
+	})
+
+	tests := []struct {
+		headers  map[string]string
+		expected string
+	}{
+		{nil, "<html><table><tr><td>Ada</td></tr></table></html>"},
+		{map[string]string{"HX-Request": "true"}, "<tr><td>Ada</td></tr>"},
+		{map[string]string{"HX-Request": "true", "HX-Boosted": "true"}, "<html><table><tr><td>Ada</td></tr></table></html>"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/contacts", nil)
+		for k, v := range tt.headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Body.String() != tt.expected {
+			t.Fatalf("response with headers %v was %q, expected %q", tt.headers, rec.Body.String(), tt.expected)
+		}
+		if vary := rec.Header().Get("Vary"); vary != "HX-Request" {
+			t.Fatalf("Vary was %q, expected HX-Request", vary)
+		}
+	}
+}
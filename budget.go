@@ -0,0 +1,55 @@
+package templatebox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// ErrBudgetExceeded is returned, wrapped, by RenderHTMLContext when the
+// render budget of the context is used up.
+var ErrBudgetExceeded = errors.New("templatebox: render budget exceeded")
+
+// budgetKey is the context key of the render budget.
+type budgetKey struct{}
+
+// budget is the number of renders left for a context.
+type budget struct {
+	limit     int64
+	remaining atomic.Int64
+}
+
+// WithBudget returns a copy of ctx limiting the number of renders made with
+// it through RenderHTMLContext, RenderMany and RenderDocument to n. Further
+// renders fail with ErrBudgetExceeded. Attach a budget to each request to
+// catch handlers that accidentally render in a loop:
+//
+//	ctx := templatebox.WithBudget(r.Context(), 3)
+func WithBudget(ctx context.Context, n int) context.Context {
+	bg := &budget{limit: int64(n)}
+	bg.remaining.Store(int64(n))
+	return context.WithValue(ctx, budgetKey{}, bg)
+}
+
+// spendBudget takes one render from the budget of ctx, if it has one.
+func spendBudget(ctx context.Context, name string) error {
+	bg, ok := ctx.Value(budgetKey{}).(*budget)
+	if !ok {
+		return nil
+	}
+	if bg.remaining.Add(-1) < 0 {
+		return fmt.Errorf("%w: rendering %s after %d renders", ErrBudgetExceeded, name, bg.limit)
+	}
+	return nil
+}
+
+// RenderHTMLContext is like RenderHTML but counts the render against the
+// budget of ctx set with WithBudget.
+func (b *Box) RenderHTMLContext(ctx context.Context, w io.Writer, name string, data any) error {
+	if err := spendBudget(ctx, name); err != nil {
+		return err
+	}
+	return b.render(ctx, w, name, data)
+}
@@ -0,0 +1,42 @@
+package templatebox_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestWithBudget(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("layout", templatebox.TemplateSet{Templates: []string{`layout`}})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	ctx := templatebox.WithBudget(context.Background(), 2)
+	for i := range 2 {
+		if err := box.RenderHTMLContext(ctx, io.Discard, "layout", nil); err != nil {
+			t.Fatalf("RenderHTMLContext %d failed: %v", i, err)
+		}
+	}
+
+	err = box.RenderHTMLContext(ctx, io.Discard, "layout", nil)
+	if !errors.Is(err, templatebox.ErrBudgetExceeded) {
+		t.Fatalf("RenderHTMLContext returned %v, expected ErrBudgetExceeded", err)
+	}
+
+	results := box.RenderMany(ctx, []templatebox.RenderJob{{Name: "layout"}})
+	if !errors.Is(results[0].Err, templatebox.ErrBudgetExceeded) {
+		t.Fatalf("RenderMany returned %v, expected ErrBudgetExceeded", results[0].Err)
+	}
+
+	if err := box.RenderHTMLContext(context.Background(), io.Discard, "layout", nil); err != nil {
+		t.Fatalf("RenderHTMLContext without a budget failed: %v", err)
+	}
+}
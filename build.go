@@ -0,0 +1,61 @@
+package templatebox
+
+import (
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// BuildInfo describes the build of the running program, for footers and
+// meta tags showing what is deployed.
+type BuildInfo struct {
+	Version string
+	Commit  string
+	Time    time.Time
+}
+
+// SetBuildInfo sets the BuildInfo returned by the version, commit and
+// buildTime template functions. Until it is set they report the module
+// version and the VCS revision and time stamped into the binary by the Go
+// toolchain, which are empty when the program is not built from a module
+// checkout, for example under go run.
+func (b *Box) SetBuildInfo(info BuildInfo) {
+	b.buildInfo = &info
+}
+
+// build returns the BuildInfo set with SetBuildInfo or read from the
+// binary.
+func (b *Box) build() BuildInfo {
+	if b.buildInfo != nil {
+		return *b.buildInfo
+	}
+	return binaryBuildInfo()
+}
+
+// binaryBuildInfo reads the BuildInfo stamped into the binary once.
+var binaryBuildInfo = sync.OnceValue(func() BuildInfo {
+	var info BuildInfo
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	if bi.Main.Version != "(devel)" {
+		info.Version = bi.Main.Version
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Commit = s.Value
+		case "vcs.time":
+			info.Time, _ = time.Parse(time.RFC3339, s.Value)
+		}
+	}
+	return info
+})
+
+// buildVersion, buildCommit and buildTime implement the version, commit and
+// buildTime template functions.
+func (b *Box) buildVersion() string { return b.build().Version }
+func (b *Box) buildCommit() string  { return b.build().Commit }
+func (b *Box) buildTime() time.Time { return b.build().Time }
@@ -0,0 +1,330 @@
+package templatebox
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// CacheOptions enables memoization of a template's rendered output. When
+// set on a FileSet or TemplateSet, RenderHTML serves previously rendered
+// bytes for the same template and data key until the TTL expires or the
+// template changes, without any change at the call site. Memoization is
+// disabled in debug mode so that template edits are always visible.
+type CacheOptions struct {
+	// TTL is how long rendered output is reused. Must be positive.
+	TTL time.Duration
+
+	// Key returns the cache key for the render data. Renders whose data
+	// produces the same key share cached output. If nil the data is
+	// marshaled to JSON and the JSON is used as the key. If the key cannot
+	// be made the template is rendered without the cache.
+	Key func(data any) (string, error)
+
+	// StaleWhileRevalidate, if positive, lets output older than the TTL but
+	// younger than TTL plus StaleWhileRevalidate be served immediately
+	// while a single background goroutine per key re-renders it. The data
+	// passed to RenderHTML is retained by that goroutine, so it must not be
+	// modified after RenderHTML returns.
+	StaleWhileRevalidate time.Duration
+}
+
+// cacheKey returns the key under which the output of rendering the named
+// template with data is cached. The key includes sum, the hash of the
+// template sources, so output rendered before a Reload or debug rebuild
+// is not served afterwards.
+func cacheKey(name, sum string, data any, opts *CacheOptions) (string, error) {
+	var k []byte
+	if opts.Key != nil {
+		s, err := opts.Key(data)
+		if err != nil {
+			return "", fmt.Errorf("cache key for template %s failed: %w", name, err)
+		}
+		k = []byte(s)
+	} else {
+		var err error
+		k, err = json.Marshal(data)
+		if err != nil {
+			return "", fmt.Errorf("cache key for template %s failed: %w", name, err)
+		}
+	}
+
+	h := sha256.New()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(sum))
+	h.Write([]byte{0})
+	h.Write(k)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// renderCached writes the cached output for name and data to w, rendering
+// and caching it first if there is no usable entry. Cached values are
+// prefixed with the time they were rendered so that stale entries can be
+// told apart from fresh ones.
+func (b *Box) renderCached(w io.Writer, name string, t Template, data any, opts *CacheOptions) error {
+	if opts.TTL <= 0 {
+		return fmt.Errorf("template %s cache TTL must be positive", name)
+	}
+
+	b.mu.RLock()
+	sum := b.hashes[name].Sum
+	b.mu.RUnlock()
+	key, err := cacheKey(name, sum, data, opts)
+	if err != nil {
+		b.logger().Warn("templatebox: cache key failed; rendering uncached",
+			"template", name, "error", err)
+		return t.Execute(w, data)
+	}
+
+	ctx := context.Background()
+	cache := b.renderCache()
+
+	v, ok, err := cache.Get(ctx, key)
+	if err != nil {
+		b.logger().Warn("templatebox: cache get failed; rendering uncached",
+			"template", name, "error", err)
+	}
+	if renderedAt, out, valid := decodeCached(v); ok && valid {
+		age := time.Since(renderedAt)
+		if age <= opts.TTL {
+			_, err := w.Write(out)
+			return err
+		}
+		if age <= opts.TTL+opts.StaleWhileRevalidate {
+			b.refreshAsync(name, key, data, opts)
+			_, err := w.Write(out)
+			return err
+		}
+	}
+
+	out, err := b.storeCached(ctx, name, key, t, data, opts)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// storeCached renders t, the named template, with data and stores the
+// output in the Cache under key, returning the output.
+func (b *Box) storeCached(ctx context.Context, name, key string, t Template, data any, opts *CacheOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(encodeCachedTime(time.Now()))
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+
+	ttl := opts.TTL + opts.StaleWhileRevalidate
+	if err := b.renderCache().Set(ctx, key, buf.Bytes(), ttl); err != nil {
+		b.logger().Warn("templatebox: cache set failed",
+			"template", name, "error", err)
+	}
+	return buf.Bytes()[cachedTimeLen:], nil
+}
+
+// refreshAsync starts refreshCached in the background unless a refresh of
+// the key is already in flight or the Box is closed.
+func (b *Box) refreshAsync(name, key string, data any, opts *CacheOptions) {
+	b.muAsync.RLock()
+	defer b.muAsync.RUnlock()
+	if b.closed {
+		return
+	}
+
+	// a pooled Data is kept from the pool until the refresh is done
+	pooled := b.retainData(data)
+	b.background.Add(1)
+	started := b.revalidate.doAsync(key, func() error {
+		defer b.background.Done()
+		if pooled {
+			defer b.releaseData(data)
+		}
+		return b.refreshCached(name, key, data, opts)
+	})
+	if !started {
+		b.background.Done()
+		if pooled {
+			b.releaseData(data)
+		}
+	}
+}
+
+// refreshCached re-renders the named template in the background to replace
+// a stale cache entry. Failures are logged and the stale entry is left to
+// expire.
+func (b *Box) refreshCached(name, key string, data any, opts *CacheOptions) error {
+	t, _, err := b.lookup(name)
+	if err == nil {
+		_, err = b.storeCached(context.Background(), name, key, t, data, opts)
+	}
+	if err != nil {
+		b.logger().Error("templatebox: background revalidation failed",
+			"template", name, "error", err)
+	}
+	return err
+}
+
+// cachedTimeLen is the length of the render time prefix of cached values.
+const cachedTimeLen = 8
+
+// encodeCachedTime returns the render time prefix for a cached value.
+func encodeCachedTime(t time.Time) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(t.UnixNano()))
+}
+
+// decodeCached splits a cached value into its render time and output. The
+// boolean is false if v is too short to be a cached value.
+func decodeCached(v []byte) (time.Time, []byte, bool) {
+	if len(v) < cachedTimeLen {
+		return time.Time{}, nil, false
+	}
+	nanos := int64(binary.BigEndian.Uint64(v[:cachedTimeLen]))
+	return time.Unix(0, nanos), v[cachedTimeLen:], true
+}
+
+// defaultCacheSize is the number of entries held by the ShardedLRUCache used
+// when no Cache is set on the Box.
+const defaultCacheSize = 1024
+
+// Cache stores rendered output for the caching features of the Box. The
+// in-memory ShardedLRUCache is used by default; implement Cache on top of
+// Redis or memcached to share cached output between multiple instances of
+// an application. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored under key. The boolean is false if the
+	// key is not present or has expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores value under key for the given ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// SetCache sets the Cache used by every caching feature of the Box. It
+// should be called before the first render.
+func (b *Box) SetCache(c Cache) {
+	b.muCache.Lock()
+	b.cache = c
+	b.muCache.Unlock()
+}
+
+// renderCache returns the Cache set on the Box, creating the default
+// ShardedLRUCache on first use.
+func (b *Box) renderCache() Cache {
+	b.muCache.RLock()
+	c := b.cache
+	b.muCache.RUnlock()
+	if c != nil {
+		return c
+	}
+
+	b.muCache.Lock()
+	defer b.muCache.Unlock()
+	if b.cache == nil {
+		b.cache = NewShardedLRUCache(defaultCacheSize)
+	}
+	return b.cache
+}
+
+// LRUCache is an in-memory Cache holding at most a fixed number of entries.
+// When full, the least recently used entry is evicted.
+type LRUCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	entries    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRUCache returns an LRUCache holding at most maxEntries entries. A
+// maxEntries less than one is treated as one.
+func NewLRUCache(maxEntries int) *LRUCache {
+	if maxEntries < 1 {
+		maxEntries = 1
+	}
+	return &LRUCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the unexpired value stored under key.
+func (c *LRUCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	e := el.Value.(*lruEntry)
+	if time.Now().After(e.expires) {
+		c.removeElement(el)
+		return nil, false, nil
+	}
+	c.ll.MoveToFront(el)
+	return e.value, true, nil
+}
+
+// Set stores value under key for ttl, evicting the least recently used
+// entry if the cache is full.
+func (c *LRUCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*lruEntry)
+		e.value = value
+		e.expires = expires
+		c.ll.MoveToFront(el)
+		return nil
+	}
+
+	c.entries[key] = c.ll.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	for c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+	}
+	return nil
+}
+
+// Delete removes key from the cache.
+func (c *LRUCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.removeElement(el)
+	}
+	return nil
+}
+
+// Len returns the number of entries in the cache, including expired
+// entries not yet removed.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func (c *LRUCache) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.entries, el.Value.(*lruEntry).key)
+}
@@ -0,0 +1,328 @@
+package templatebox_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestCacheOptionsMemoizesRender(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	calls := 0
+	err = box.AddTemplateRaw("report", templatebox.TemplateSet{
+		Templates: []string{`{{ expensive }} {{ .ID }}`},
+		FuncMap: templatebox.FuncMap{
+			"expensive": func() int {
+				calls++
+				return calls
+			},
+		},
+		Cache: &templatebox.CacheOptions{
+			TTL: time.Minute,
+			Key: func(data any) (string, error) {
+				return data.(map[string]string)["ID"], nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	render := func(id string) string {
+		t.Helper()
+		s, err := box.RenderString("report", map[string]string{"ID": id})
+		if err != nil {
+			t.Fatalf("RenderString failed: %v", err)
+		}
+		return s
+	}
+
+	if got := render("x"); got != "1 x" {
+		t.Fatalf("RenderString returned %s, expected %s", got, "1 x")
+	}
+	if got := render("x"); got != "1 x" {
+		t.Fatalf("RenderString returned %s, expected cached %s", got, "1 x")
+	}
+	if got := render("y"); got != "2 y" {
+		t.Fatalf("RenderString returned %s, expected %s", got, "2 y")
+	}
+	if calls != 2 {
+		t.Fatalf("template executed %d times, expected 2", calls)
+	}
+}
+
+func TestCacheOptionsExpires(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	calls := 0
+	err = box.AddTemplateRaw("report", templatebox.TemplateSet{
+		Templates: []string{`{{ expensive }}`},
+		FuncMap: templatebox.FuncMap{
+			"expensive": func() int {
+				calls++
+				return calls
+			},
+		},
+		Cache: &templatebox.CacheOptions{TTL: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	if _, err := box.RenderString("report", nil); err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	s, err := box.RenderString("report", nil)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	if s != "2" {
+		t.Fatalf("RenderString returned %s, expected %s after the TTL expired", s, "2")
+	}
+}
+
+func TestCacheOptionsTemplateChange(t *testing.T) {
+	path := t.TempDir()
+	writeTemplates(t, path, map[string]string{"report.html": `version 1`})
+	box, err := templatebox.NewBoxFromOSDir(path, nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplate("report", templatebox.FileSet{
+		Filenames: []string{"report.html"},
+		Cache:     &templatebox.CacheOptions{TTL: time.Minute},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+	if _, err := box.RenderString("report", nil); err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+
+	// output cached before a reload is not served after it
+	writeTemplates(t, path, map[string]string{"report.html": `version 2`})
+	if err := box.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	s, err := box.RenderString("report", nil)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	if expected := "version 2"; s != expected {
+		t.Fatalf("RenderString returned %s, expected %s", s, expected)
+	}
+}
+
+func TestCacheOptionsKeyFailure(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	calls := 0
+	err = box.AddTemplateRaw("report", templatebox.TemplateSet{
+		Templates: []string{`{{ expensive }}`},
+		FuncMap: templatebox.FuncMap{
+			"expensive": func() int {
+				calls++
+				return calls
+			},
+		},
+		Cache: &templatebox.CacheOptions{
+			TTL: time.Minute,
+			Key: func(data any) (string, error) {
+				return "", fmt.Errorf("no key")
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	// a failed key renders without the cache rather than failing
+	for _, expected := range []string{"1", "2"} {
+		s, err := box.RenderString("report", nil)
+		if err != nil {
+			t.Fatalf("RenderString failed: %v", err)
+		}
+		if s != expected {
+			t.Fatalf("RenderString returned %s, expected %s", s, expected)
+		}
+	}
+}
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := templatebox.NewLRUCache(2)
+
+	c.Set(ctx, "a", []byte("1"), time.Minute)
+	c.Set(ctx, "b", []byte("2"), time.Minute)
+
+	// touch a so that b becomes the least recently used entry
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Fatalf("Get(a) returned false, expected true")
+	}
+	c.Set(ctx, "c", []byte("3"), time.Minute)
+
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Fatalf("Get(b) returned true, expected b to be evicted")
+	}
+	if v, ok, _ := c.Get(ctx, "a"); !ok || string(v) != "1" {
+		t.Fatalf("Get(a) returned %s %v, expected 1 true", v, ok)
+	}
+	if c.Len() != 2 {
+		t.Fatalf("Len returned %d, expected 2", c.Len())
+	}
+
+	c.Delete(ctx, "a")
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Fatalf("Get(a) returned true after Delete")
+	}
+}
+
+func TestShardedLRUCache(t *testing.T) {
+	ctx := context.Background()
+	c := templatebox.NewShardedLRUCache(64)
+
+	for i := range 1000 {
+		c.Set(ctx, fmt.Sprint(i), []byte(fmt.Sprint(i)), time.Minute)
+	}
+	// 64 entries are spread over 32 shards of 2
+	if c.Len() != 64 {
+		t.Fatalf("Len returned %d, expected 64", c.Len())
+	}
+	if v, ok, _ := c.Get(ctx, "999"); !ok || string(v) != "999" {
+		t.Fatalf("Get(999) returned %s %v, expected 999 true", v, ok)
+	}
+	if _, ok, _ := c.Get(ctx, "0"); ok {
+		t.Fatalf("Get(0) returned true, expected 0 to be evicted")
+	}
+
+	c.Delete(ctx, "999")
+	if _, ok, _ := c.Get(ctx, "999"); ok {
+		t.Fatalf("Get(999) returned true after Delete")
+	}
+}
+
+// countingCache is a Cache recording the number of Set calls.
+type countingCache struct {
+	*templatebox.LRUCache
+	sets int
+}
+
+func (c *countingCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.sets++
+	return c.LRUCache.Set(ctx, key, value, ttl)
+}
+
+func TestSetCache(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	cache := &countingCache{LRUCache: templatebox.NewLRUCache(10)}
+	box.SetCache(cache)
+
+	err = box.AddTemplateRaw("hello", templatebox.TemplateSet{
+		Templates: []string{`Hello {{ . }}`},
+		Cache:     &templatebox.CacheOptions{TTL: time.Minute},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	for range 3 {
+		if _, err := box.RenderString("hello", "ada"); err != nil {
+			t.Fatalf("RenderString failed: %v", err)
+		}
+	}
+	if cache.sets != 1 {
+		t.Fatalf("Cache.Set called %d times, expected 1", cache.sets)
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	var calls atomic.Int32
+	err = box.AddTemplateRaw("report", templatebox.TemplateSet{
+		Templates: []string{`{{ expensive }}`},
+		FuncMap: templatebox.FuncMap{
+			"expensive": func() int32 { return calls.Add(1) },
+		},
+		Cache: &templatebox.CacheOptions{
+			TTL:                  10 * time.Millisecond,
+			StaleWhileRevalidate: time.Minute,
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	if _, err := box.RenderString("report", nil); err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// the first stale render is served the stale output
+	s, err := box.RenderString("report", nil)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	if s != "1" {
+		t.Fatalf("RenderString returned %s, expected stale %s", s, "1")
+	}
+
+	// concurrent renders never wait for the single background render
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, err := box.RenderString("report", nil)
+			if err != nil {
+				t.Errorf("RenderString failed: %v", err)
+				return
+			}
+			if s != "1" && s != "2" {
+				t.Errorf("RenderString returned %s, expected %s or %s", s, "1", "2")
+			}
+		}()
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s, err := box.RenderString("report", nil)
+		if err != nil {
+			t.Fatalf("RenderString failed: %v", err)
+		}
+		if s == "2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for revalidation, last output %s", s)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("template executed %d times, expected 2", n)
+	}
+}
@@ -0,0 +1,91 @@
+package templatebox
+
+import (
+	"context"
+	"time"
+)
+
+// maxAuditEntries is the number of entries kept in memory for AuditLog.
+const maxAuditEntries = 1000
+
+// AuditAction is the kind of change recorded in an AuditEntry.
+type AuditAction string
+
+const (
+	// AuditRegister records a template added with AddTemplate,
+	// AddTemplateRaw or AddTemplateRawFunc.
+	AuditRegister AuditAction = "register"
+
+	// AuditReload records a successful Reload.
+	AuditReload AuditAction = "reload"
+
+	// AuditRebuild records a template rebuilt in debug mode because the
+	// strings returned by its AddTemplateRawFunc source, or its files in
+	// the scratch directory, changed.
+	AuditRebuild AuditAction = "rebuild"
+
+	// AuditSwap records a recorded version installed with SwapVersion.
+	AuditSwap AuditAction = "swap"
+
+	// AuditRollback records a recorded version installed with Rollback.
+	AuditRollback AuditAction = "rollback"
+)
+
+// AuditEntry records a change to the templates of a Box.
+type AuditEntry struct {
+	Time   time.Time   `json:"time"`
+	Action AuditAction `json:"action"`
+
+	// Templates are the names of the templates registered or rebuilt, or
+	// changed by a reload, swap or rollback.
+	Templates []string `json:"templates"`
+
+	// Actor is the actor of the context set with WithActor, empty for
+	// changes made without one.
+	Actor string `json:"actor,omitempty"`
+
+	// Fingerprint is the Fingerprint of the Box after the change.
+	Fingerprint string `json:"fingerprint"`
+}
+
+// actorKey is the context key of the actor.
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying the user or process making
+// changes through it, such as an AddTemplateContext or ReloadContext call,
+// for the audit log.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// AuditLog returns the most recent changes to the templates of the Box,
+// oldest first. Only the last 1000 entries are kept in memory; set
+// Config.AuditSink to keep a complete log.
+func (b *Box) AuditLog() []AuditEntry {
+	b.muAudit.Lock()
+	defer b.muAudit.Unlock()
+	return append([]AuditEntry(nil), b.auditLog...)
+}
+
+// audit records a change to the named templates made with ctx.
+func (b *Box) audit(ctx context.Context, action AuditAction, names ...string) {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	e := AuditEntry{
+		Time:        time.Now(),
+		Action:      action,
+		Templates:   names,
+		Actor:       actor,
+		Fingerprint: b.Fingerprint(),
+	}
+
+	b.muAudit.Lock()
+	b.auditLog = append(b.auditLog, e)
+	if extra := len(b.auditLog) - maxAuditEntries; extra > 0 {
+		b.auditLog = append([]AuditEntry(nil), b.auditLog[extra:]...)
+	}
+	b.muAudit.Unlock()
+
+	if b.cfg.AuditSink != nil {
+		b.cfg.AuditSink(e)
+	}
+}
@@ -0,0 +1,209 @@
+package templatebox_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestAuditLog(t *testing.T) {
+	path := t.TempDir()
+	writeTemplates(t, path, map[string]string{"a.html": `v1`, "b.html": `b`})
+
+	var sunk []templatebox.AuditEntry
+	box, err := templatebox.NewBoxFromOSDir(path, &templatebox.Config{
+		AuditSink: func(e templatebox.AuditEntry) { sunk = append(sunk, e) },
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	if err := box.AddTemplate("a", templatebox.FileSet{Filenames: []string{"a.html"}}); err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+	if err := box.AddTemplate("b", templatebox.FileSet{Filenames: []string{"b.html"}}); err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	writeTemplates(t, path, map[string]string{"a.html": `v2`})
+	ctx := templatebox.WithActor(context.Background(), "alice@example.com")
+	if err := box.ReloadContext(ctx); err != nil {
+		t.Fatalf("ReloadContext failed: %v", err)
+	}
+
+	log := box.AuditLog()
+	if !reflect.DeepEqual(log, sunk) {
+		t.Fatalf("AuditLog returned %+v, expected the entries sent to AuditSink %+v", log, sunk)
+	}
+	if len(log) != 3 {
+		t.Fatalf("AuditLog returned %d entries, expected 3", len(log))
+	}
+
+	reload := log[2]
+	if reload.Action != templatebox.AuditReload || reload.Actor != "alice@example.com" {
+		t.Fatalf("AuditLog entry is %s by %s, expected reload by alice@example.com", reload.Action, reload.Actor)
+	}
+	expected := []string{"a"}
+	if !reflect.DeepEqual(reload.Templates, expected) {
+		t.Fatalf("reload changed %v, expected %v", reload.Templates, expected)
+	}
+	if reload.Fingerprint != box.Fingerprint() {
+		t.Fatalf("reload fingerprint is %s, expected %s", reload.Fingerprint, box.Fingerprint())
+	}
+}
+
+// auditActions returns the action, actor and templates of each entry.
+func auditActions(log []templatebox.AuditEntry) []string {
+	var got []string
+	for _, e := range log {
+		got = append(got, fmt.Sprintf("%s %s %v", e.Action, e.Actor, e.Templates))
+	}
+	return got
+}
+
+func TestAuditLogRegisterActor(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	ctx := templatebox.WithActor(context.Background(), "alice@example.com")
+	if err := box.AddTemplateContext(ctx, "a", templatebox.FileSet{Filenames: []string{"a.html"}}); err != nil {
+		t.Fatalf("AddTemplateContext failed: %v", err)
+	}
+	if err := box.AddTemplateRawContext(ctx, "raw", templatebox.TemplateSet{Templates: []string{`raw`}}); err != nil {
+		t.Fatalf("AddTemplateRawContext failed: %v", err)
+	}
+	err = box.AddTemplateRawFuncContext(ctx, "func", func() []string { return []string{`func`} })
+	if err != nil {
+		t.Fatalf("AddTemplateRawFuncContext failed: %v", err)
+	}
+
+	expected := []string{
+		"register alice@example.com [a]",
+		"register alice@example.com [raw]",
+		"register alice@example.com [func]",
+	}
+	if got := auditActions(box.AuditLog()); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("AuditLog returned %q, expected %q", got, expected)
+	}
+}
+
+func TestAuditLogRebuild(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{Debug: true})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	// a template whose source function returns new strings is rebuilt
+	text := `v1`
+	if err := box.AddTemplateRawFunc("cms", func() []string { return []string{text} }); err != nil {
+		t.Fatalf("AddTemplateRawFunc failed: %v", err)
+	}
+	text = `v2`
+	if _, err := box.RenderString("cms", nil); err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+
+	// as is a raw template edited in the scratch directory
+	if err := box.AddTemplateRaw("inline", templatebox.TemplateSet{Templates: []string{`v1`}}); err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	dir := t.TempDir()
+	if err := box.SetScratchDir(dir); err != nil {
+		t.Fatalf("SetScratchDir failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "inline", "0.html"), []byte(`v2`), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if _, err := box.RenderString("inline", nil); err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+
+	// renders without changes are not rebuilds
+	if _, err := box.RenderString("cms", nil); err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+
+	expected := []string{
+		"register  [cms]",
+		"rebuild  [cms]",
+		"register  [inline]",
+		"rebuild  [inline]",
+	}
+	if got := auditActions(box.AuditLog()); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("AuditLog returned %q, expected %q", got, expected)
+	}
+}
+
+func TestAuditLogSwapAndRollback(t *testing.T) {
+	path := t.TempDir()
+	writeTemplates(t, path, map[string]string{"a.html": `v1`, "b.html": `b`})
+
+	box, err := templatebox.NewBoxFromOSDir(path, &templatebox.Config{KeepVersions: 5})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateMap(map[string]templatebox.FileSet{
+		"a": {Filenames: []string{"a.html"}},
+		"b": {Filenames: []string{"b.html"}},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateMap failed: %v", err)
+	}
+	writeTemplates(t, path, map[string]string{"a.html": `v2`})
+	if err := box.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	versions := box.Versions()
+
+	render := func(expected string) {
+		t.Helper()
+		s, err := box.RenderString("a", nil)
+		if err != nil {
+			t.Fatalf("RenderString failed: %v", err)
+		}
+		if s != expected {
+			t.Fatalf("RenderString returned %s, expected %s", s, expected)
+		}
+	}
+
+	ctx := templatebox.WithActor(context.Background(), "alice@example.com")
+	if err := box.RollbackContext(ctx); err != nil {
+		t.Fatalf("RollbackContext failed: %v", err)
+	}
+	render("v1")
+	if box.Fingerprint() != versions[0].ID {
+		t.Fatalf("Fingerprint after rollback is %s, expected %s", box.Fingerprint(), versions[0].ID)
+	}
+
+	// there is nothing before the first version
+	if err := box.Rollback(); err == nil {
+		t.Fatalf("Rollback from the first version succeeded, expected an error")
+	}
+
+	if err := box.SwapVersionContext(ctx, versions[1].ID); err != nil {
+		t.Fatalf("SwapVersionContext failed: %v", err)
+	}
+	render("v2")
+	if err := box.SwapVersion("unknown"); err == nil {
+		t.Fatalf("SwapVersion of an unknown version succeeded, expected an error")
+	}
+
+	log := box.AuditLog()
+	expected := []string{
+		"reload  [a]",
+		"rollback alice@example.com [a]",
+		"swap alice@example.com [a]",
+	}
+	if got := auditActions(log[len(log)-3:]); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("AuditLog returned %q, expected %q", got, expected)
+	}
+	if last := log[len(log)-1]; last.Fingerprint != versions[1].ID {
+		t.Fatalf("swap fingerprint is %s, expected %s", last.Fingerprint, versions[1].ID)
+	}
+}
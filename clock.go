@@ -0,0 +1,12 @@
+package templatebox
+
+import "time"
+
+// now implements the now template function, returning the time of the
+// Config.Clock.
+func (b *Box) now() time.Time {
+	if b.cfg.Clock != nil {
+		return b.cfg.Clock()
+	}
+	return time.Now()
+}
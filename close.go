@@ -0,0 +1,55 @@
+package templatebox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrClosed is returned, wrapped, by RenderAsync and Suspense.Defer after
+// Close has been called.
+var ErrClosed = errors.New("templatebox: box closed")
+
+// Close stops the background work of the Box: the RenderAsync worker pool,
+// HandleSignals, stale-while-revalidate cache refreshes and Suspense
+// renders. Renders already queued with RenderAsync or deferred with
+// Suspense.Defer are completed, and later calls to RenderAsync and Defer
+// fail with ErrClosed. Close waits for the background goroutines to finish
+// or for ctx to be done, whichever is first, so a server can bound the time
+// spent shutting down:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	if err := box.Close(ctx); err != nil {
+//	    log.Printf("templatebox shutdown: %v", err)
+//	}
+//
+// Synchronous renders such as RenderHTML continue to work after Close.
+// Calling Close more than once is safe.
+func (b *Box) Close(ctx context.Context) error {
+	b.closeOnce.Do(func() {
+		close(b.closing)
+
+		// wait for senders blocked in RenderAsync to give up before
+		// closing the queue
+		b.muAsync.Lock()
+		b.closed = true
+		if b.asyncJobs != nil {
+			close(b.asyncJobs)
+		}
+		b.muAsync.Unlock()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		b.background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("close box: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,37 @@
+package templatebox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestClose(t *testing.T) {
+	box, started, release := newBlockingBox(t, &templatebox.Config{AsyncWorkers: 1})
+
+	f := box.RenderAsync(context.Background(), "slow", nil)
+	<-started
+
+	// Close waits for the queued render until its context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := box.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close returned %v, expected context.DeadlineExceeded", err)
+	}
+
+	r := box.RenderAsync(context.Background(), "slow", nil).Result()
+	if !errors.Is(r.Err, templatebox.ErrClosed) {
+		t.Fatalf("RenderAsync returned %v, expected ErrClosed", r.Err)
+	}
+
+	close(release)
+	if err := box.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if r := f.Result(); r.Err != nil {
+		t.Fatalf("RenderAsync failed: %v", r.Err)
+	}
+}
@@ -0,0 +1,264 @@
+package templatebox
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Slot assigns a registered template to a named slot of a layout, for
+// Compose.
+type Slot struct {
+	name     string
+	template string
+}
+
+// With returns a Slot filling the named slot of a layout with the named
+// template. The template is the main template of a registered template
+// set, such as "pages/about", or a fragment of the form "page#fragment".
+func With(slot, name string) Slot {
+	return Slot{name: slot, template: name}
+}
+
+// Composition is a layout with its slots filled by registered templates,
+// returned by Compose.
+type Composition struct {
+	box    *Box
+	layout string
+	slots  []Slot
+}
+
+// Compose returns a Composition rendering the named layout with its slots
+// filled by other registered templates, chosen at render time rather than
+// registering a template set for every combination:
+//
+//	err := box.Compose("layout",
+//		templatebox.With("content", "pages/about"),
+//		templatebox.With("sidebar", "partials/blog-sidebar"),
+//	).Render(w, data)
+//
+// A slot is a template the layout executes by name, such as
+// {{ template "content" . }} or {{ block "sidebar" . }}{{ end }}. The
+// layout and its slot templates must be parsed with html/template. A slot
+// given to With overrides the default content of a block. Rendering fails
+// if a slot is neither filled nor has default content, or With names a
+// slot the layout does not have; see Validate. The composed template set
+// is built on first use and kept until any of the templates it is built
+// from changes.
+func (b *Box) Compose(layout string, slots ...Slot) *Composition {
+	return &Composition{box: b, layout: layout, slots: slots}
+}
+
+// Render renders the composition to w with data.
+func (c *Composition) Render(w io.Writer, data any) error {
+	return c.RenderContext(context.Background(), w, data)
+}
+
+// RenderContext renders the composition to w with data, waiting for a
+// render slot until ctx is done when Config.MaxConcurrentRenders is set.
+func (c *Composition) RenderContext(ctx context.Context, w io.Writer, data any) error {
+	b := c.box
+	if b.retainData(data) {
+		defer b.releaseData(data)
+	}
+	release, err := b.acquireRender(ctx, c.layout)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	t, err := b.composed(c)
+	if err != nil {
+		return err
+	}
+	if err := b.execute(w, c.layout, t, b.composedOptions(c), data); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, page := range c.pages()[1:] {
+		b.markRendered(page, now)
+	}
+	return nil
+}
+
+// composedOptions returns the render options of the composition: those of
+// the layout, without its output cache as the output depends on the slots,
+// requiring the fields required by the layout and by each slot filled with
+// a whole template. A fragment needs no more than its page's fields.
+func (b *Box) composedOptions(c *Composition) renderOptions {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	opts := b.opts[c.layout]
+	opts.cache = nil
+	opts.requires = slices.Clone(opts.requires)
+	for _, s := range c.slots {
+		if !strings.Contains(s.template, "#") {
+			opts.requires = append(opts.requires, b.opts[s.template].requires...)
+		}
+	}
+	return opts
+}
+
+// composition is a template set built by Compose and the hashes of the
+// templates it was built from.
+type composition struct {
+	t      *template.Template
+	hashes []string
+}
+
+// compositions caches the template sets built by Compose.
+type compositions struct {
+	mu sync.Mutex
+	m  map[string]composition
+}
+
+// key returns the key of the composition in the cache.
+func (c *Composition) key() string {
+	var sb strings.Builder
+	sb.WriteString(c.layout)
+	for _, s := range c.slots {
+		sb.WriteString("\x00" + s.name + "\x00" + s.template)
+	}
+	return sb.String()
+}
+
+// pages returns the names of the registered templates the composition is
+// built from.
+func (c *Composition) pages() []string {
+	names := []string{c.layout}
+	for _, s := range c.slots {
+		page, _, _ := strings.Cut(s.template, "#")
+		names = append(names, page)
+	}
+	return names
+}
+
+// composed returns the template set of the composition, building it if
+// it is not cached or any of its templates has changed since.
+func (b *Box) composed(c *Composition) (*template.Template, error) {
+	pages := c.pages()
+	hashes := make([]string, len(pages))
+	for i, name := range pages {
+		// look the template up first so it is rebuilt in debug mode
+		if _, _, err := b.lookup(name); err != nil {
+			return nil, err
+		}
+		b.mu.RLock()
+		hashes[i] = b.hashes[name].Sum
+		b.mu.RUnlock()
+	}
+
+	key := c.key()
+	b.compositions.mu.Lock()
+	cached, ok := b.compositions.m[key]
+	b.compositions.mu.Unlock()
+	if ok && slices.Equal(cached.hashes, hashes) {
+		return cached.t, nil
+	}
+
+	t, err := b.compose(c)
+	if err != nil {
+		return nil, err
+	}
+	b.compositions.mu.Lock()
+	if b.compositions.m == nil {
+		b.compositions.m = make(map[string]composition)
+	}
+	b.compositions.m[key] = composition{t: t, hashes: hashes}
+	b.compositions.mu.Unlock()
+	return t, nil
+}
+
+// compose builds the template set of the composition: the sources of the
+// layout and of each slot template parsed together, with each slot
+// defined as the template filling it.
+func (b *Box) compose(c *Composition) (*template.Template, error) {
+	layout, err := b.registeredSet(c.layout)
+	if err != nil {
+		return nil, err
+	}
+	if !isHTMLEngine(layout.engine) {
+		return nil, fmt.Errorf("compose %s: layouts are only supported by html/template", c.layout)
+	}
+	if err := c.validate(layout.srcs); err != nil {
+		return nil, err
+	}
+
+	sets := make([]registeredSet, len(c.slots))
+	funcs := layout.funcs
+	for i, s := range c.slots {
+		page, _, _ := strings.Cut(s.template, "#")
+		if sets[i], err = b.registeredSet(page); err != nil {
+			return nil, fmt.Errorf("compose %s: slot %s: %w", c.layout, s.name, err)
+		}
+		if !isHTMLEngine(sets[i].engine) {
+			return nil, fmt.Errorf("compose %s: slot %s: %s is not an html/template", c.layout, s.name, page)
+		}
+		funcs = withFuncs(funcs, sets[i].funcs)
+	}
+
+	t, err := b.parse(layout.srcs[0].Name, layout.srcs, layout.engine, layout.meta, funcs)
+	if err != nil {
+		return nil, fmt.Errorf("compose %s: %w", c.layout, err)
+	}
+	ht := t.(*template.Template)
+
+	for i, s := range c.slots {
+		set := sets[i]
+		for _, src := range set.srcs {
+			if _, err := ht.New(src.Name).Parse(string(src.Text)); err != nil {
+				return nil, fmt.Errorf("compose %s: slot %s: %w", c.layout, s.name, err)
+			}
+		}
+		entry := set.srcs[0].Name
+		if _, fragment, ok := strings.Cut(s.template, "#"); ok {
+			entry = fragment
+		}
+		et := ht.Lookup(entry)
+		if et == nil || et.Tree == nil {
+			return nil, fmt.Errorf("compose %s: slot %s: template %s not found", c.layout, s.name, s.template)
+		}
+		if _, err := ht.AddParseTree(s.name, et.Tree); err != nil {
+			return nil, fmt.Errorf("compose %s: slot %s: %w", c.layout, s.name, err)
+		}
+	}
+	return ht, nil
+}
+
+// registeredSet is the sources and settings of a registered template.
+type registeredSet struct {
+	srcs   []Source
+	meta   *Meta
+	funcs  FuncMap
+	engine Engine
+}
+
+// registeredSet returns the sources and settings of the named template
+// added with AddTemplate or AddTemplateRaw.
+func (b *Box) registeredSet(name string) (registeredSet, error) {
+	b.muFileSets.RLock()
+	fs, isFile := b.fileSets[name]
+	rs, isRaw := b.rawSets[name]
+	b.muFileSets.RUnlock()
+
+	var set registeredSet
+	switch {
+	case isFile:
+		set = registeredSet{meta: fs.Meta, funcs: fs.FuncMap, engine: fs.Engine}
+	case isRaw:
+		set = registeredSet{meta: rs.Meta, funcs: rs.FuncMap, engine: rs.Engine}
+	default:
+		return set, fmt.Errorf("template %s not found", name)
+	}
+	srcs, err := b.templateSources(name)
+	if err != nil {
+		return set, err
+	}
+	set.srcs = srcs
+	return set, nil
+}
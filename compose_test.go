@@ -0,0 +1,217 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestCompose(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	sets := map[string]templatebox.TemplateSet{
+		"layout": {Templates: []string{
+			`<main>{{ template "content" . }}</main><aside>{{ block "sidebar" . }}default{{ end }}</aside>`,
+		}},
+		"pages/about": {Templates: []string{`About {{ shout .Name }}`}, FuncMap: templatebox.FuncMap{
+			"shout": strings.ToUpper,
+		}},
+		"blog": {Templates: []string{
+			`{{ template "sidebar" . }}`,
+			`{{ define "sidebar" }}Posts by {{ .Name }}{{ end }}`,
+		}},
+	}
+	for name, s := range sets {
+		if err := box.AddTemplateRaw(name, s); err != nil {
+			t.Fatalf("AddTemplateRaw failed: %v", err)
+		}
+	}
+
+	data := map[string]string{"Name": "<Ada>"}
+	var buf bytes.Buffer
+	err = box.Compose("layout",
+		templatebox.With("content", "pages/about"),
+		templatebox.With("sidebar", "blog#sidebar"),
+	).Render(&buf, data)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `<main>About &lt;ADA&gt;</main><aside>Posts by &lt;Ada&gt;</aside>`
+	if buf.String() != expected {
+		t.Fatalf("Render returned %s, expected %s", buf.String(), expected)
+	}
+
+	// an unfilled block keeps its default content
+	buf.Reset()
+	if err := box.Compose("layout", templatebox.With("content", "pages/about")).Render(&buf, data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `<main>About &lt;ADA&gt;</main><aside>default</aside>`; buf.String() != expected {
+		t.Fatalf("Render returned %s, expected %s", buf.String(), expected)
+	}
+
+	// a changed slot template is used by the next render
+	err = box.AddTemplateRaw("pages/about", templatebox.TemplateSet{Templates: []string{`About us`}})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	buf.Reset()
+	if err := box.Compose("layout", templatebox.With("content", "pages/about")).Render(&buf, data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := `<main>About us</main><aside>default</aside>`; buf.String() != expected {
+		t.Fatalf("Render returned %s, expected %s", buf.String(), expected)
+	}
+
+	err = box.Compose("layout", templatebox.With("content", "missing")).Render(&buf, data)
+	if err == nil || !strings.Contains(err.Error(), "template missing not found") {
+		t.Fatalf("Render returned %v, expected a missing template error", err)
+	}
+}
+
+func TestSlots(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{StrictSlots: true})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	if err := box.AddTemplate("a", templatebox.FileSet{Filenames: []string{"layout.html", "a.html"}}); err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+	slots, err := box.Slots("a")
+	if err != nil {
+		t.Fatalf("Slots failed: %v", err)
+	}
+	expected := []templatebox.SlotInfo{{Name: "content", FilledBy: "a.html"}}
+	if !reflect.DeepEqual(slots, expected) {
+		t.Fatalf("Slots returned %+v, expected %+v", slots, expected)
+	}
+
+	// a page that fills no slot of its layout fails to register
+	err = box.AddTemplateRaw("empty", templatebox.TemplateSet{Templates: []string{
+		`<main>{{ block "content" . }}{{ end }}</main><aside>{{ block "sidebar" . }}default{{ end }}</aside>`,
+		`{{ define "contnet" }}About{{ end }}`,
+	}})
+	if err == nil || !strings.Contains(err.Error(), "slot content of empty is not filled") {
+		t.Fatalf("AddTemplateRaw returned %v, expected a missing slot error", err)
+	}
+
+	// an empty definition keeps the default content
+	err = box.AddTemplateRaw("about", templatebox.TemplateSet{Templates: []string{
+		`<main>{{ block "content" . }}{{ end }}</main><aside>{{ block "sidebar" . }}default{{ end }}</aside>`,
+		`{{ define "content" }}About{{ end }}{{ define "sidebar" }}{{ end }}`,
+	}})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	if slots, err = box.Slots("about"); err != nil {
+		t.Fatalf("Slots failed: %v", err)
+	}
+	expected = []templatebox.SlotInfo{
+		{Name: "content", FilledBy: "about at index 1"},
+		{Name: "sidebar", Default: true},
+	}
+	if !reflect.DeepEqual(slots, expected) {
+		t.Fatalf("Slots returned %+v, expected %+v", slots, expected)
+	}
+}
+
+func TestComposeValidate(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	sets := map[string]templatebox.TemplateSet{
+		"layout": {Templates: []string{
+			`<main>{{ block "content" . }}{{ end }}</main><aside>{{ block "sidebar" . }}default{{ end }}</aside>`,
+		}},
+		"pages/about": {Templates: []string{`About`}},
+	}
+	for name, s := range sets {
+		if err := box.AddTemplateRaw(name, s); err != nil {
+			t.Fatalf("AddTemplateRaw failed: %v", err)
+		}
+	}
+
+	c := box.Compose("layout", templatebox.With("content", "pages/about"))
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	slots, err := c.Slots()
+	if err != nil {
+		t.Fatalf("Slots failed: %v", err)
+	}
+	expected := []templatebox.SlotInfo{
+		{Name: "content", FilledBy: "pages/about"},
+		{Name: "sidebar", Default: true},
+	}
+	if !reflect.DeepEqual(slots, expected) {
+		t.Fatalf("Slots returned %+v, expected %+v", slots, expected)
+	}
+
+	// a misspelt slot is reported along with the slot it leaves empty
+	c = box.Compose("layout", templatebox.With("contnet", "pages/about"))
+	err = c.Validate()
+	if err == nil || !strings.Contains(err.Error(), "slot content is not filled") ||
+		!strings.Contains(err.Error(), "layout has no slot contnet") {
+		t.Fatalf("Validate returned %v, expected missing and unknown slot errors", err)
+	}
+	var buf bytes.Buffer
+	if err := c.Render(&buf, nil); err == nil || buf.Len() > 0 {
+		t.Fatalf("Render returned %v with output %q, expected an error", err, buf.String())
+	}
+}
+
+func TestComposeRenderPipeline(t *testing.T) {
+	var teed []string
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		StrictData: true,
+		Tee: func(name string, output []byte) {
+			teed = append(teed, name)
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	sets := map[string]templatebox.TemplateSet{
+		"layout":      {Templates: []string{`<main>{{ template "content" . }}</main>`}},
+		"pages/about": {Templates: []string{`{{/* requires: Title string */}}<h1>{{ .Title }}</h1>`}},
+	}
+	for name, s := range sets {
+		if err := box.AddTemplateRaw(name, s); err != nil {
+			t.Fatalf("AddTemplateRaw failed: %v", err)
+		}
+	}
+	c := box.Compose("layout", templatebox.With("content", "pages/about"))
+
+	// the fields required by a slot template are checked
+	var buf bytes.Buffer
+	err = c.Render(&buf, map[string]any{})
+	if err == nil || !strings.Contains(err.Error(), "missing field Title") {
+		t.Fatalf("Render returned %v, expected a missing field error", err)
+	}
+
+	// pooled data is released and the output is sent to Tee
+	data := box.Data()
+	data["Title"] = "About"
+	if err := c.Render(&buf, data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := "<main><h1>About</h1></main>"; buf.String() != expected {
+		t.Fatalf("Render returned %s, expected %s", buf.String(), expected)
+	}
+	if len(data) != 0 {
+		t.Fatalf("Data has %d entries after the render, expected it to be cleared", len(data))
+	}
+	if len(teed) != 1 || teed[0] != "layout" {
+		t.Fatalf("Tee called for %v, expected [layout]", teed)
+	}
+	if unrendered := box.Unrendered(time.Hour); len(unrendered) != 0 {
+		t.Fatalf("Unrendered returned %v, expected none", unrendered)
+	}
+}
@@ -0,0 +1,188 @@
+package templatebox
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+)
+
+var (
+	muDataFormats sync.RWMutex
+	dataFormats   = make(map[string]func(b []byte) (any, error))
+)
+
+// RegisterDataFormat makes LoadData, SSGOptions.DataDir and example data
+// files decode files with the extension ext, such as ".toml", with decode.
+// Maps in the decoded value with keys other than strings are converted to
+// map[string]any. JSON and CSV are built in; import the adapter/yaml
+// package to register .yaml and .yml, which keeps the YAML dependency out
+// of programs that do not use it:
+//
+//	import _ "github.com/andyfusniak/templatebox/adapter/yaml"
+func RegisterDataFormat(ext string, decode func(b []byte) (any, error)) {
+	muDataFormats.Lock()
+	defer muDataFormats.Unlock()
+	dataFormats[ext] = decode
+}
+
+// dataFormat returns the decode function registered for ext.
+func dataFormat(ext string) (func(b []byte) (any, error), bool) {
+	muDataFormats.RLock()
+	defer muDataFormats.RUnlock()
+	decode, ok := dataFormats[ext]
+	return decode, ok
+}
+
+// isDataFile reports whether LoadData reads files with the extension ext.
+// YAML files are read without a registered format so that the missing
+// import is reported rather than the files ignored.
+func isDataFile(ext string) bool {
+	switch ext {
+	case ".json", ".csv", ".yaml", ".yml":
+		return true
+	}
+	_, ok := dataFormat(ext)
+	return ok
+}
+
+// LoadData reads every JSON, YAML and CSV file in fsys, and every file of
+// a format added with RegisterDataFormat, into a map keyed by filename
+// without its extension, with a nested map for each directory, so that
+// data/products.yaml and data/shop/stores.csv are found at ["products"]
+// and ["shop"]["stores"]. Files with other extensions are ignored. YAML
+// files are an error unless the adapter/yaml package is imported.
+//
+// JSON and YAML objects become map[string]any, arrays []any, integers int
+// and other numbers float64, so values compare as expected with eq and lt
+// in templates. A CSV file becomes a []map[string]string with a map per
+// row keyed by the header in the first row.
+func LoadData(fsys fs.FS) (map[string]any, error) {
+	data := make(map[string]any)
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		ext := path.Ext(p)
+		if !isDataFile(ext) {
+			return nil
+		}
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		v, err := decodeData(ext, b)
+		if err != nil {
+			return fmt.Errorf("load data file %s: %w", p, err)
+		}
+
+		// nest the value under a map for each directory
+		m := data
+		dirs := strings.Split(path.Dir(p), "/")
+		for _, dir := range dirs {
+			if dir == "." {
+				continue
+			}
+			sub, ok := m[dir].(map[string]any)
+			if !ok {
+				if _, exists := m[dir]; exists {
+					return fmt.Errorf("load data file %s: %s is both a file and a directory", p, dir)
+				}
+				sub = make(map[string]any)
+				m[dir] = sub
+			}
+			m = sub
+		}
+
+		key := strings.TrimSuffix(path.Base(p), ext)
+		if _, exists := m[key]; exists {
+			return fmt.Errorf("load data file %s: more than one data file or directory named %s", p, key)
+		}
+		m[key] = v
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// decodeData decodes the content of a data file with the extension ext.
+func decodeData(ext string, b []byte) (any, error) {
+	switch ext {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		return normalizeData(v), nil
+	case ".csv":
+		records, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		rows := []map[string]string{}
+		if len(records) == 0 {
+			return rows, nil
+		}
+		for _, record := range records[1:] {
+			row := make(map[string]string, len(records[0]))
+			for i, h := range records[0] {
+				row[h] = record[i]
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	}
+
+	decode, ok := dataFormat(ext)
+	if !ok {
+		if ext == ".yaml" || ext == ".yml" {
+			return nil, fmt.Errorf("no data format for %s files: import github.com/andyfusniak/templatebox/adapter/yaml", ext)
+		}
+		return nil, fmt.Errorf("no data format for %s files", ext)
+	}
+	v, err := decode(b)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeData(v), nil
+}
+
+// normalizeData converts the maps decoded from YAML and other registered
+// formats to map[string]any and the json.Number values decoded from JSON
+// to int or float64.
+func normalizeData(v any) any {
+	switch v := v.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalizeData(e)
+		}
+		return m
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalizeData(e)
+		}
+		return v
+	case []any:
+		for i, e := range v {
+			v[i] = normalizeData(e)
+		}
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil && int64(int(i)) == i {
+			return int(i)
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return v
+}
@@ -0,0 +1,112 @@
+package templatebox_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestLoadData(t *testing.T) {
+	fsys := fstest.MapFS{
+		"products.json":    {Data: []byte(`[{"name": "Mug", "price": 8.5, "stock": 12}]`)},
+		"shop/stores.csv":  {Data: []byte("city,phone\nLondon,020\nLeeds,0113\n")},
+		"shop/config.json": {Data: []byte(`{"currency": "GBP", "open": true, "hours": [9, 17]}`)},
+		"README.md":        {Data: []byte("ignored")},
+	}
+
+	data, err := templatebox.LoadData(fsys)
+	if err != nil {
+		t.Fatalf("LoadData failed: %v", err)
+	}
+
+	expected := map[string]any{
+		"products": []any{
+			map[string]any{"name": "Mug", "price": 8.5, "stock": 12},
+		},
+		"shop": map[string]any{
+			"stores": []map[string]string{
+				{"city": "London", "phone": "020"},
+				{"city": "Leeds", "phone": "0113"},
+			},
+			"config": map[string]any{"currency": "GBP", "open": true, "hours": []any{9, 17}},
+		},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Fatalf("LoadData returned %#v, expected %#v", data, expected)
+	}
+}
+
+func TestLoadDataFormat(t *testing.T) {
+	templatebox.RegisterDataFormat(".lines", func(b []byte) (any, error) {
+		return map[any]any{"lines": strings.Split(strings.TrimSpace(string(b)), "\n")}, nil
+	})
+	fsys := fstest.MapFS{
+		"menu.lines": {Data: []byte("Home\nAbout\n")},
+	}
+
+	data, err := templatebox.LoadData(fsys)
+	if err != nil {
+		t.Fatalf("LoadData failed: %v", err)
+	}
+
+	expected := map[string]any{
+		"menu": map[string]any{"lines": []string{"Home", "About"}},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Fatalf("LoadData returned %#v, expected %#v", data, expected)
+	}
+}
+
+func TestLoadDataYAMLWithoutAdapter(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config.yaml": {Data: []byte("currency: GBP\n")},
+	}
+
+	_, err := templatebox.LoadData(fsys)
+	if err == nil || !strings.Contains(err.Error(), "adapter/yaml") {
+		t.Fatalf("LoadData returned error %v, expected a missing adapter/yaml error", err)
+	}
+}
+
+func TestRenderAllDataDir(t *testing.T) {
+	dataDir := t.TempDir()
+	writeTemplates(t, dataDir, map[string]string{
+		"products.json": `[{"name": "Mug", "stock": 3}, {"name": "Cap", "stock": 0}]`,
+	})
+
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("shop", templatebox.TemplateSet{
+		Templates: []string{`<h1>{{ .Data }}</h1>{{ range .Site.Data.products }}{{ if gt .stock 0 }}<p>{{ .name }}</p>{{ end }}{{ end }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	_, err = box.RenderAll(context.Background(), templatebox.SSGOptions{
+		Dir:     dir,
+		DataDir: dataDir,
+		Pages:   []templatebox.Page{{Path: "/", Template: "shop", Data: "Shop"}},
+	})
+	if err != nil {
+		t.Fatalf("RenderAll failed: %v", err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatalf("os.ReadFile failed: %v", err)
+	}
+	expected := `<h1>Shop</h1><p>Mug</p>`
+	if string(b) != expected {
+		t.Fatalf("RenderAll wrote %s, expected %s", b, expected)
+	}
+}
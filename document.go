@@ -0,0 +1,76 @@
+package templatebox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// Converter converts rendered HTML into another document format, such as
+// PDF. Adapters for external tools live in the adapter subpackages.
+type Converter interface {
+	Convert(ctx context.Context, html []byte) ([]byte, error)
+}
+
+// ConverterFunc is an adapter to allow the use of an ordinary function as a
+// Converter.
+type ConverterFunc func(ctx context.Context, html []byte) ([]byte, error)
+
+// Convert calls f(ctx, html).
+func (f ConverterFunc) Convert(ctx context.Context, html []byte) ([]byte, error) {
+	return f(ctx, html)
+}
+
+// RenderDocument renders the named template with the given data and pipes
+// the resulting HTML through c, returning the converted bytes. It is
+// typically used to generate invoices and other printable documents.
+func (b *Box) RenderDocument(ctx context.Context, name string, data any, c Converter) ([]byte, error) {
+	if c == nil {
+		return nil, fmt.Errorf("converter cannot be nil")
+	}
+
+	var buf bytes.Buffer
+	if err := b.RenderHTMLContext(ctx, &buf, name, data); err != nil {
+		return nil, err
+	}
+
+	out, err := c.Convert(ctx, buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("convert document %s failed: %w", name, err)
+	}
+	return out, nil
+}
+
+// ConverterPool limits the number of concurrent conversions performed by
+// the wrapped Converter. Document converters usually start an external
+// process or browser per call, so bounding them protects the host when many
+// documents are generated at once.
+type ConverterPool struct {
+	c   Converter
+	sem chan struct{}
+}
+
+// NewConverterPool returns a ConverterPool running at most size conversions
+// at a time. A size less than one is treated as one.
+func NewConverterPool(c Converter, size int) *ConverterPool {
+	if size < 1 {
+		size = 1
+	}
+	return &ConverterPool{
+		c:   c,
+		sem: make(chan struct{}, size),
+	}
+}
+
+// Convert waits for a free worker slot and then calls the wrapped Converter.
+// It returns ctx.Err() if ctx is done before a slot becomes free.
+func (p *ConverterPool) Convert(ctx context.Context, html []byte) ([]byte, error) {
+	select {
+	case p.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-p.sem }()
+
+	return p.c.Convert(ctx, html)
+}
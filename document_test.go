@@ -0,0 +1,60 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestRenderDocument(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("invoice", templatebox.TemplateSet{
+		Templates: []string{`<h1>Invoice {{ .Number }}</h1>`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var running, peak atomic.Int32
+	upper := templatebox.ConverterFunc(func(ctx context.Context, html []byte) ([]byte, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return bytes.ToUpper(html), nil
+	})
+	pool := templatebox.NewConverterPool(upper, 2)
+
+	done := make(chan error)
+	for range 6 {
+		go func() {
+			out, err := box.RenderDocument(context.Background(), "invoice", map[string]int{"Number": 7}, pool)
+			if err == nil && string(out) != "<H1>INVOICE 7</H1>" {
+				t.Errorf("RenderDocument returned %s", out)
+			}
+			done <- err
+		}()
+	}
+	for range 6 {
+		if err := <-done; err != nil {
+			t.Fatalf("RenderDocument failed: %v", err)
+		}
+	}
+
+	if peak.Load() > 2 {
+		t.Fatalf("ConverterPool ran %d conversions at once, expected at most 2", peak.Load())
+	}
+}
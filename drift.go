@@ -0,0 +1,133 @@
+package templatebox
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// Orphans returns the sorted paths, relative to the template directory, of
+// the files found in the template directory that are not used by any
+// FileSet added to the Box. Calling it at startup or from a test catches
+// templates left behind after a refactoring. Example data files, such as
+// hello.data.json, are not templates and are not reported. A Box from
+// NewBoxFromFiles has no template directory and so never has orphans.
+func (b *Box) Orphans() ([]string, error) {
+	if b.fs == nil && b.files == nil && b.templateDir == "" {
+		return nil, nil
+	}
+
+	used := make(map[string]bool)
+	for _, s := range b.registeredFileSets() {
+		for _, f := range s.Filenames {
+			used[path.Clean(slashPath(f))] = true
+		}
+	}
+
+	var orphans []string
+	walk := func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if !used[p] && !isExampleData(p) {
+			orphans = append(orphans, p)
+		}
+		return nil
+	}
+
+	var err error
+	switch {
+	case b.files != nil:
+		for _, p := range sortedKeys(b.files) {
+			if !used[p] && !isExampleData(p) {
+				orphans = append(orphans, p)
+			}
+		}
+	case b.fs == nil:
+		err = fs.WalkDir(os.DirFS(b.templateDir), ".", walk)
+	default:
+		root := path.Clean(slashPath(b.templateDir))
+		var sub fs.FS
+		sub, err = fs.Sub(b.fs, root)
+		if err == nil {
+			err = fs.WalkDir(sub, ".", walk)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("walk template directory failed: %w", err)
+	}
+
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+// MissingFiles returns the sorted, de-duplicated filenames referenced by
+// the FileSets added to the Box that no longer exist in the template
+// directory. A non-empty result means a later rebuild or Reload of the
+// affected templates will fail.
+func (b *Box) MissingFiles() ([]string, error) {
+	seen := make(map[string]bool)
+	var missing []string
+	for _, s := range b.registeredFileSets() {
+		for _, f := range s.Filenames {
+			if seen[f] {
+				continue
+			}
+			seen[f] = true
+
+			ok, err := b.fileExists(f)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				missing = append(missing, f)
+			}
+		}
+	}
+
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// fileExists reports whether the filename, relative to the template
+// directory, exists in the Box filesystem.
+func (b *Box) fileExists(filename string) (bool, error) {
+	var err error
+	fsys, p, isRoot := b.root(filename)
+	switch {
+	case b.files != nil:
+		_, err = b.readArchiveFile(filename)
+	case isRoot:
+		_, err = fs.Stat(fsys, p)
+	case b.fs == nil:
+		_, err = os.Stat(filepath.Join(b.templateDir, filename))
+	default:
+		_, err = fs.Stat(b.fs, b.fsPath(filename))
+	}
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return false, fmt.Errorf("stat %s failed: %w", filename, err)
+}
+
+// registeredFileSets returns a copy of the FileSets added to the Box.
+func (b *Box) registeredFileSets() map[string]FileSet {
+	b.muFileSets.RLock()
+	defer b.muFileSets.RUnlock()
+
+	m := make(map[string]FileSet, len(b.fileSets))
+	for k, v := range b.fileSets {
+		m[k] = v
+	}
+	return m
+}
@@ -0,0 +1,90 @@
+package templatebox_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestOrphansAndMissingFiles(t *testing.T) {
+	path := t.TempDir()
+
+	for _, name := range []string{"layout.html", "a.html", "unused.html", "partials/nav.html"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(path, name)), 0755); err != nil {
+			t.Fatalf("os.MkdirAll failed: %v", err)
+		}
+		err := os.WriteFile(filepath.Join(path, name), []byte(`{{ define "x" }}{{ end }}`), 0644)
+		if err != nil {
+			t.Fatalf("os.WriteFile failed: %v", err)
+		}
+	}
+
+	box, err := templatebox.NewBoxFromOSDir(path, nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplate("a", templatebox.FileSet{
+		Filenames: []string{"layout.html", "a.html"},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	orphans, err := box.Orphans()
+	if err != nil {
+		t.Fatalf("Orphans failed: %v", err)
+	}
+	expected := []string{"partials/nav.html", "unused.html"}
+	if !reflect.DeepEqual(orphans, expected) {
+		t.Fatalf("Orphans returned %v, expected %v", orphans, expected)
+	}
+
+	if err := os.Remove(filepath.Join(path, "a.html")); err != nil {
+		t.Fatalf("os.Remove failed: %v", err)
+	}
+
+	missing, err := box.MissingFiles()
+	if err != nil {
+		t.Fatalf("MissingFiles failed: %v", err)
+	}
+	expected = []string{"a.html"}
+	if !reflect.DeepEqual(missing, expected) {
+		t.Fatalf("MissingFiles returned %v, expected %v", missing, expected)
+	}
+}
+
+func TestOrphansFSDir(t *testing.T) {
+	box, err := templatebox.NewBoxFromFSDir(&templateFS, "testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromFSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateMap(map[string]templatebox.FileSet{
+		"a": {Filenames: []string{"layout.html", "a.html"}},
+		"b": {Filenames: []string{"layout.html", "b.html"}},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateMap failed: %v", err)
+	}
+
+	orphans, err := box.Orphans()
+	if err != nil {
+		t.Fatalf("Orphans failed: %v", err)
+	}
+	expected := []string{"c.html", "d.html"}
+	if !reflect.DeepEqual(orphans, expected) {
+		t.Fatalf("Orphans returned %v, expected %v", orphans, expected)
+	}
+
+	missing, err := box.MissingFiles()
+	if err != nil {
+		t.Fatalf("MissingFiles failed: %v", err)
+	}
+	if len(missing) != 0 {
+		t.Fatalf("MissingFiles returned %v, expected none", missing)
+	}
+}
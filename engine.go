@@ -0,0 +1,101 @@
+package templatebox
+
+import (
+	"html/template"
+	"io"
+)
+
+// Template is a parsed template that can be executed.
+type Template interface {
+	Execute(w io.Writer, data any) error
+}
+
+// Engine parses template sources into a Template. It lets templates written
+// in another syntax use the loading, debug rebuilding, reloading, caching
+// and rendering of the Box.
+//
+// The sources are those of a FileSet or TemplateSet in order. The name is
+// the Name of the first source for a FileSet and the template name for a
+// TemplateSet. The FuncMap holds the builtin functions, the functions
+// registered with RegisterUnsafeFunc, the global FuncMap and the local
+// FuncMap, later ones replacing earlier ones of the same name. Engines
+// without functions may ignore it.
+type Engine interface {
+	Parse(name string, srcs []Source, funcs FuncMap) (Template, error)
+}
+
+// HTMLEngine is the default Engine, using html/template. Sources are parsed
+// in the same way as template.ParseFiles, and the Template returned is a
+// *template.Template.
+type HTMLEngine struct{}
+
+// Parse implements Engine.
+func (HTMLEngine) Parse(name string, srcs []Source, funcs FuncMap) (Template, error) {
+	t := template.New(name).Funcs(template.FuncMap(funcs))
+	return parseSources(t, srcs)
+}
+
+// isHTMLEngine reports whether e parses html/template syntax.
+func isHTMLEngine(e Engine) bool {
+	switch e.(type) {
+	case nil, HTMLEngine, *HTMLEngine:
+		return true
+	}
+	return false
+}
+
+// funcs returns the FuncMap of a template: the builtin functions, metaTags
+// bound to the meta defaults, the functions registered with
+// RegisterUnsafeFunc, the global FuncMap and the local FuncMap, in that
+// order.
+func (b *Box) funcs(meta *Meta, local FuncMap) FuncMap {
+	m := FuncMap(b.builtinFuncs())
+	m["metaTags"] = metaTagsFunc(meta)
+	for name, f := range b.unsafeFuncs {
+		m[name] = f.fn
+	}
+	for name, fn := range b.globalFuncMap {
+		m[name] = fn
+	}
+	for name, fn := range local {
+		m[name] = fn
+	}
+	return m
+}
+
+// parse parses the sources with the engine, html/template if nil, and adds
+// source comments to html/template templates.
+func (b *Box) parse(name string, srcs []Source, e Engine, meta *Meta, local FuncMap) (Template, error) {
+	if e == nil {
+		e = HTMLEngine{}
+	}
+	t, err := e.Parse(name, srcs, b.funcs(meta, local))
+	if err != nil {
+		return nil, err
+	}
+	if ht, ok := t.(*template.Template); ok {
+		if err := b.annotate(ht); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// htmlNames returns the sorted names of the templates parsed as
+// html/template, which are the ones the analysis functions can inspect.
+func (b *Box) htmlNames() []string {
+	b.muFileSets.RLock()
+	defer b.muFileSets.RUnlock()
+
+	var names []string
+	for _, name := range b.Names() {
+		if s, ok := b.fileSets[name]; ok && !isHTMLEngine(s.Engine) {
+			continue
+		}
+		if s, ok := b.rawSets[name]; ok && !isHTMLEngine(s.Engine) {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
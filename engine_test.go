@@ -0,0 +1,77 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+// replaceEngine is an Engine replacing ${key} with the value of key in a
+// map[string]string, using the upper func from the FuncMap on every value.
+type replaceEngine struct{}
+
+type replaceTemplate struct {
+	text  string
+	upper func(string) string
+}
+
+func (replaceEngine) Parse(name string, srcs []templatebox.Source, funcs templatebox.FuncMap) (templatebox.Template, error) {
+	var sb strings.Builder
+	for _, src := range srcs {
+		sb.Write(src.Text)
+	}
+	return &replaceTemplate{text: sb.String(), upper: funcs["upper"].(func(string) string)}, nil
+}
+
+func (t *replaceTemplate) Execute(w io.Writer, data any) error {
+	var pairs []string
+	for k, v := range data.(map[string]string) {
+		pairs = append(pairs, "${"+k+"}", t.upper(v))
+	}
+	_, err := io.WriteString(w, strings.NewReplacer(pairs...).Replace(t.text))
+	return err
+}
+
+func TestEngine(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplates(t, dir, map[string]string{
+		"a.txt": "Hello ${name}. ",
+		"b.txt": "Bye ${name}.",
+	})
+
+	box, err := templatebox.NewBoxFromOSDir(dir, nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetGlobalFuncMap(templatebox.FuncMap{"upper": strings.ToUpper})
+
+	err = box.AddTemplate("greeting", templatebox.FileSet{
+		Filenames: []string{"a.txt", "b.txt"},
+		Engine:    replaceEngine{},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "greeting", map[string]string{"name": "ann"}); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	expected := "Hello ANN. Bye ANN."
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+
+	// templates of other engines are not analysed as html/template
+	issues, err := box.Lint()
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Fatalf("Lint returned %v, expected no issues", issues)
+	}
+}
@@ -0,0 +1,42 @@
+package templatebox
+
+import "fmt"
+
+// Environment names the deployment environment of the program.
+type Environment string
+
+// Environments recognised by the isDev, isStaging and isProd template
+// functions. Any other value is allowed and reported by env.
+const (
+	EnvDev     Environment = "dev"
+	EnvStaging Environment = "staging"
+	EnvProd    Environment = "prod"
+)
+
+// debug reports whether debug mode is enabled. Debug mode and the features
+// depending on it, such as rebuilding, the overlay directory, source
+// comments and recorded data, are never enabled in EnvProd, so a Debug flag
+// left on by mistake cannot expose them in production, nor with
+// Config.FastPath.
+func (b *Box) debug() bool {
+	return b.cfg.Debug && !b.cfg.FastPath && b.cfg.Environment != EnvProd
+}
+
+// env implements the env template function. Without arguments it returns
+// the configured Environment. Given a name, as in {{ env "PORT" }}, it
+// returns the allowed value of the provider set with SetEnvProvider.
+func (b *Box) env(name ...string) (string, error) {
+	switch len(name) {
+	case 0:
+		return string(b.cfg.Environment), nil
+	case 1:
+		return b.envSource.lookup("env", name[0])
+	}
+	return "", fmt.Errorf("env expects at most one argument, got %d", len(name))
+}
+
+// isDev, isStaging and isProd implement the template functions of the same
+// names, for example {{ if isDev }}<div class="dev-banner">DEV</div>{{ end }}.
+func (b *Box) isDev() bool     { return b.cfg.Environment == EnvDev }
+func (b *Box) isStaging() bool { return b.cfg.Environment == EnvStaging }
+func (b *Box) isProd() bool    { return b.cfg.Environment == EnvProd }
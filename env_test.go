@@ -0,0 +1,87 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestEnvironment(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		Debug:       true,
+		Environment: templatebox.EnvProd,
+		Logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	if box.DebugEffective() {
+		t.Fatalf("DebugEffective returned true, expected false in the prod environment")
+	}
+
+	err = box.AddTemplateRaw("env", templatebox.TemplateSet{
+		Templates: []string{`{{ env }}{{ if isProd }} live{{ end }}{{ if isDev }} dev{{ end }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "env", nil); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	expected := "prod live"
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+}
+
+func TestEnvAndSecretProviders(t *testing.T) {
+	t.Setenv("TEMPLATEBOX_PORT", "8080")
+	t.Setenv("TEMPLATEBOX_TOKEN", "hidden")
+
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetEnvProvider(templatebox.OSEnv, "TEMPLATEBOX_PORT")
+	box.SetSecretProvider(templatebox.ValueProviderFunc(func(name string) (string, bool, error) {
+		v, ok := map[string]string{"db_password": "s3cret"}[name]
+		return v, ok, nil
+	}), "db_password", "api_key")
+
+	templates := map[string]string{
+		"config":     `port={{ env "TEMPLATEBOX_PORT" }} password={{ secret "db_password" }}`,
+		"notAllowed": `{{ env "TEMPLATEBOX_TOKEN" }}`,
+		"notSet":     `{{ secret "api_key" }}`,
+	}
+	for name, text := range templates {
+		if err := box.AddTemplateRaw(name, templatebox.TemplateSet{Templates: []string{text}}); err != nil {
+			t.Fatalf("AddTemplateRaw failed: %v", err)
+		}
+	}
+
+	got, err := box.RenderString("config", nil)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	expected := "port=8080 password=s3cret"
+	if got != expected {
+		t.Fatalf("RenderString returned %s, expected %s", got, expected)
+	}
+
+	for name, expected := range map[string]string{
+		"notAllowed": `env "TEMPLATEBOX_TOKEN" is not in the allowlist`,
+		"notSet":     `secret "api_key" is not set`,
+	} {
+		_, err := box.RenderString(name, nil)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("RenderString of %s returned %v, expected an error containing %s", name, err, expected)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+//go:build ignore
+// +build ignore
+
+// This program measures the overhead of rendering a template with a
+// templatebox Box compared with executing the same html/template directly,
+// with and without Config.FastPath.
+//
+// Run it from the repository root with:
+//
+//	go run example/benchmark/main.go
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+const page = `<!DOCTYPE html>
+<html lang="en">
+<head><title>{{ .Title }}</title></head>
+<body>
+  <ul>{{ range .Items }}<li>{{ .Name }}: {{ .Price }}</li>{{ end }}</ul>
+</body>
+</html>`
+
+type item struct {
+	Name  string
+	Price float64
+}
+
+var data = struct {
+	Title string
+	Items []item
+}{
+	Title: "Shop",
+	Items: []item{{"Tea", 2.5}, {"Cake", 3}, {"Jam", 4.25}},
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	raw, err := template.New("page").Parse(page)
+	if err != nil {
+		return fmt.Errorf("template.Parse failed: %w", err)
+	}
+	normal, err := newBox(&templatebox.Config{})
+	if err != nil {
+		return err
+	}
+	fast, err := newBox(&templatebox.Config{FastPath: true})
+	if err != nil {
+		return err
+	}
+
+	base := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				raw.Execute(io.Discard, data)
+			}
+		})
+	})
+	fmt.Printf("%-22s %s\n", "template.Execute", base.String()+base.MemString())
+
+	for _, c := range []struct {
+		name string
+		box  *templatebox.Box
+	}{
+		{"Box", normal},
+		{"Box with FastPath", fast},
+	} {
+		r := testing.Benchmark(func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					c.box.RenderHTML(io.Discard, "page", data)
+				}
+			})
+		})
+		overhead := r.NsPerOp() - base.NsPerOp()
+		fmt.Printf("%-22s %s  (%+d ns/op)\n", c.name, r.String()+r.MemString(), overhead)
+	}
+	return nil
+}
+
+func newBox(cfg *templatebox.Config) (*templatebox.Box, error) {
+	box := templatebox.NewBoxFromFiles(cfg)
+	err := box.AddTemplateRaw("page", templatebox.TemplateSet{Templates: []string{page}})
+	if err != nil {
+		return nil, fmt.Errorf("box.AddTemplateRaw failed: %w", err)
+	}
+	return box, nil
+}
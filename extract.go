@@ -0,0 +1,116 @@
+package templatebox
+
+import (
+	"html"
+	"strings"
+)
+
+// ExtractText returns a Tee function that converts each rendered page to
+// plain text and passes it to fn, so sites can feed a search index directly
+// from the render path:
+//
+//	cfg := &templatebox.Config{
+//		Tee: templatebox.ExtractText(func(name, text string) {
+//			index.Index(name, text)
+//		}),
+//	}
+//
+// Tags, comments and the content of script, style and template elements are
+// removed, entities are decoded and runs of whitespace are collapsed.
+func ExtractText(fn func(name, text string)) func(name string, output []byte) {
+	return func(name string, output []byte) {
+		fn(name, stripTags(string(output)))
+	}
+}
+
+// MultiTee returns a Tee function that calls each of the given Tee
+// functions in turn.
+func MultiTee(tees ...func(name string, output []byte)) func(name string, output []byte) {
+	return func(name string, output []byte) {
+		for _, tee := range tees {
+			tee(name, output)
+		}
+	}
+}
+
+// rawTextElements are elements whose content is not text.
+var rawTextElements = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"template": true,
+	"noscript": true,
+}
+
+// stripTags returns the text content of the HTML s with tags removed,
+// entities decoded and whitespace collapsed. Tags are replaced by a space
+// so that the text of adjacent elements is not joined into one word.
+func stripTags(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] != '<' {
+			j := strings.IndexByte(s[i:], '<')
+			if j < 0 {
+				j = len(s) - i
+			}
+			sb.WriteString(s[i : i+j])
+			i += j
+			continue
+		}
+
+		// comments
+		if strings.HasPrefix(s[i:], "<!--") {
+			j := strings.Index(s[i+4:], "-->")
+			if j < 0 {
+				break
+			}
+			i += 4 + j + 3
+			continue
+		}
+
+		end := tagEnd(s, i)
+		name := tagName(s[i:end])
+		i = end
+		sb.WriteByte(' ')
+
+		// skip the content of raw text elements up to the closing tag
+		if rawTextElements[name] {
+			j := strings.Index(strings.ToLower(s[i:]), "</"+name)
+			if j < 0 {
+				break
+			}
+			i = tagEnd(s, i+j)
+		}
+	}
+	return strings.Join(strings.Fields(html.UnescapeString(sb.String())), " ")
+}
+
+// tagEnd returns the index just after the '>' closing the tag that starts
+// at i, skipping over quoted attribute values.
+func tagEnd(s string, i int) int {
+	var quote byte
+	for j := i + 1; j < len(s); j++ {
+		c := s[j]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// tagName returns the lower-cased element name of an opening tag, or an
+// empty string for closing tags, doctypes and processing instructions.
+func tagName(tag string) string {
+	tag = strings.TrimPrefix(tag, "<")
+	end := strings.IndexAny(tag, " \t\n\r\f/>")
+	if end < 0 {
+		end = len(tag)
+	}
+	return strings.ToLower(tag[:end])
+}
@@ -0,0 +1,33 @@
+package templatebox
+
+// publishLocked replaces the snapshot of templates read by the fast path
+// with a copy of the registered templates, leaving out those with
+// CacheOptions, which need the normal path. The caller must hold b.mu for
+// writing.
+func (b *Box) publishLocked() {
+	if !b.cfg.FastPath {
+		return
+	}
+	m := make(map[string]Template, len(b.html))
+	for name, t := range b.html {
+		if b.opts[name].cache == nil {
+			m[name] = t
+		}
+	}
+	b.fast.Store(&m)
+}
+
+// fastLookup returns the named template from the fast path snapshot, and
+// false if the fast path is disabled, a render hook is configured or the
+// template is not in the snapshot.
+func (b *Box) fastLookup(name string) (Template, bool) {
+	if !b.cfg.FastPath || b.cfg.Tee != nil || b.cfg.StrictData || b.renderSlots != nil || b.recordDir != "" {
+		return nil, false
+	}
+	m := b.fast.Load()
+	if m == nil {
+		return nil, false
+	}
+	t, ok := (*m)[name]
+	return t, ok
+}
@@ -0,0 +1,170 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestFastPath(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{FastPath: true})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplate("a", templatebox.FileSet{
+		Filenames: []string{"layout.html", "a.html"},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	raw, err := template.ParseFiles("testdata/templates/layout.html", "testdata/templates/a.html")
+	if err != nil {
+		t.Fatalf("ParseFiles failed: %v", err)
+	}
+	var expected bytes.Buffer
+	if err := raw.Execute(&expected, nil); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "a", nil); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+	if buf.String() != expected.String() {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected.String())
+	}
+	if _, ok := box.LastRendered("a"); ok {
+		t.Fatalf("LastRendered reported a render, expected none with FastPath")
+	}
+
+	// templates registered later are rendered by the fast path too
+	err = box.AddTemplateRaw("b", templatebox.TemplateSet{Templates: []string{`Hello {{ . }}`}})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	got, err := box.RenderString("b", "<Ada>")
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	if expected := "Hello &lt;Ada&gt;"; got != expected {
+		t.Fatalf("RenderString returned %s, expected %s", got, expected)
+	}
+}
+
+// The benchmarks below compare the overhead of a Box with executing an
+// html/template directly:
+//
+//	go test -run '^$' -bench 'Overhead' -benchmem -count 10 | benchstat -
+func BenchmarkOverheadTemplateExecute(b *testing.B) {
+	t, err := template.ParseFiles("testdata/templates/layout.html", "testdata/templates/a.html")
+	if err != nil {
+		b.Fatalf("ParseFiles failed: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := t.Execute(io.Discard, nil); err != nil {
+				b.Fatalf("Execute failed: %v", err)
+			}
+		}
+	})
+}
+
+func BenchmarkOverheadBox(b *testing.B) {
+	benchmarkOverhead(b, &templatebox.Config{})
+}
+
+func BenchmarkOverheadBoxFastPath(b *testing.B) {
+	benchmarkOverhead(b, &templatebox.Config{FastPath: true})
+}
+
+func benchmarkOverhead(b *testing.B, cfg *templatebox.Config) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", cfg)
+	if err != nil {
+		b.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplate("a", templatebox.FileSet{
+		Filenames: []string{"layout.html", "a.html"},
+	})
+	if err != nil {
+		b.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := box.RenderHTML(io.Discard, "a", nil); err != nil {
+				b.Fatalf("RenderHTML failed: %v", err)
+			}
+		}
+	})
+}
+
+type overheadPage struct {
+	Title string
+	Body  string
+	Tags  [8]string
+	Views int
+}
+
+const overheadStructTemplate = `<h1>{{ .Title }}</h1><p>{{ .Body }}</p>{{ range .Tags }}{{ . }}{{ end }}{{ .Views }}`
+
+// The struct benchmarks render a fixed struct type on one goroutine, to
+// compare the allocations of a Box with those of template.Execute.
+func BenchmarkOverheadStructTemplateExecute(b *testing.B) {
+	t, err := template.New("page").Parse(overheadStructTemplate)
+	if err != nil {
+		b.Fatalf("Parse failed: %v", err)
+	}
+	data := overheadPage{Title: "Home", Body: "Welcome", Views: 3}
+
+	b.ReportAllocs()
+	for range b.N {
+		if err := t.Execute(io.Discard, data); err != nil {
+			b.Fatalf("Execute failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkOverheadStructBoxFastPath(b *testing.B) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{FastPath: true})
+	if err != nil {
+		b.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("page", templatebox.TemplateSet{Templates: []string{overheadStructTemplate}})
+	if err != nil {
+		b.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	data := overheadPage{Title: "Home", Body: "Welcome", Views: 3}
+
+	b.ReportAllocs()
+	for range b.N {
+		if err := box.RenderHTML(io.Discard, "page", data); err != nil {
+			b.Fatalf("RenderHTML failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkOverheadStructPointerBoxFastPath(b *testing.B) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{FastPath: true})
+	if err != nil {
+		b.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("page", templatebox.TemplateSet{Templates: []string{overheadStructTemplate}})
+	if err != nil {
+		b.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	data := &overheadPage{Title: "Home", Body: "Welcome", Views: 3}
+
+	b.ReportAllocs()
+	for range b.N {
+		if err := box.RenderHTML(io.Discard, "page", data); err != nil {
+			b.Fatalf("RenderHTML failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package templatebox
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	texttemplate "text/template"
+	"time"
+)
+
+// The reserved names of the built-in feed templates. Each renders a Feed
+// and can be used like any other template name, for example as the
+// Template of a Page built by RenderAll. A template added to the Box under
+// one of these names replaces the built-in template, for example to add
+// elements of a feed extension.
+const (
+	// FeedRSS renders an RSS 2.0 feed.
+	FeedRSS = "templatebox:rss"
+
+	// FeedAtom renders an Atom feed.
+	FeedAtom = "templatebox:atom"
+
+	// FeedJSON renders a JSON Feed 1.1 feed.
+	FeedJSON = "templatebox:jsonfeed"
+)
+
+// Feed is the data rendered by the built-in feed templates.
+type Feed struct {
+	Title       string
+	Description string
+
+	// Link is the absolute URL of the site, such as "https://example.com/".
+	Link string
+
+	// FeedURL is the absolute URL the feed is served at.
+	FeedURL string
+
+	Author string
+
+	// Updated is the time the feed last changed. If zero the Date of the
+	// most recent item is used.
+	Updated time.Time
+
+	Items []FeedItem
+}
+
+// FeedItem is an entry of a Feed, typically taken from the metadata of
+// rendered content.
+type FeedItem struct {
+	Title string
+
+	// URL is the absolute URL of the content.
+	URL string
+
+	Date    time.Time
+	Summary string
+
+	// ID identifies the item permanently. If empty the URL is used.
+	ID string
+}
+
+// LastUpdated returns the Updated time of the feed or, if it is zero, the
+// most recent Date of its items.
+func (f Feed) LastUpdated() time.Time {
+	if !f.Updated.IsZero() {
+		return f.Updated
+	}
+	var t time.Time
+	for _, it := range f.Items {
+		if it.Date.After(t) {
+			t = it.Date
+		}
+	}
+	return t
+}
+
+// GUID returns the ID of the item or, if it is empty, its URL.
+func (it FeedItem) GUID() string {
+	if it.ID != "" {
+		return it.ID
+	}
+	return it.URL
+}
+
+const rssTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+  <channel>
+    <title>{{ xml .Title }}</title>
+    <link>{{ xml .Link }}</link>
+    <description>{{ xml .Description }}</description>
+{{- if .FeedURL }}
+    <atom:link href="{{ xml .FeedURL }}" rel="self" type="application/rss+xml"/>
+{{- end }}
+{{- if not .LastUpdated.IsZero }}
+    <lastBuildDate>{{ rfc1123 .LastUpdated }}</lastBuildDate>
+{{- end }}
+{{- range .Items }}
+    <item>
+      <title>{{ xml .Title }}</title>
+      <link>{{ xml .URL }}</link>
+      <guid isPermaLink="{{ eq .GUID .URL }}">{{ xml .GUID }}</guid>
+{{- if not .Date.IsZero }}
+      <pubDate>{{ rfc1123 .Date }}</pubDate>
+{{- end }}
+{{- if .Summary }}
+      <description>{{ xml .Summary }}</description>
+{{- end }}
+    </item>
+{{- end }}
+  </channel>
+</rss>
+`
+
+const atomTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>{{ xml .Title }}</title>
+{{- if .Description }}
+  <subtitle>{{ xml .Description }}</subtitle>
+{{- end }}
+  <id>{{ xml (or .FeedURL .Link) }}</id>
+  <link href="{{ xml .Link }}"/>
+{{- if .FeedURL }}
+  <link href="{{ xml .FeedURL }}" rel="self"/>
+{{- end }}
+  <updated>{{ rfc3339 .LastUpdated }}</updated>
+{{- if .Author }}
+  <author>
+    <name>{{ xml .Author }}</name>
+  </author>
+{{- end }}
+{{- range .Items }}
+  <entry>
+    <title>{{ xml .Title }}</title>
+    <link href="{{ xml .URL }}"/>
+    <id>{{ xml .GUID }}</id>
+    <updated>{{ rfc3339 .Date }}</updated>
+{{- if .Summary }}
+    <summary>{{ xml .Summary }}</summary>
+{{- end }}
+  </entry>
+{{- end }}
+</feed>
+`
+
+const jsonFeedTemplate = `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": {{ json .Title }},
+  "home_page_url": {{ json .Link }}
+{{- if .FeedURL }},
+  "feed_url": {{ json .FeedURL }}
+{{- end }}
+{{- if .Description }},
+  "description": {{ json .Description }}
+{{- end }}
+{{- if .Author }},
+  "authors": [{"name": {{ json .Author }}}]
+{{- end }},
+  "items": [
+{{- range $i, $it := .Items }}{{ if $i }},{{ end }}
+    {
+      "id": {{ json .GUID }},
+      "url": {{ json .URL }},
+      "title": {{ json .Title }}
+{{- if not .Date.IsZero }},
+      "date_published": {{ json (rfc3339 .Date) }}
+{{- end }}
+{{- if .Summary }},
+      "summary": {{ json .Summary }}
+{{- end }}
+    }
+{{- end }}
+  ]
+}
+`
+
+// feedFuncs are the functions available to the built-in feed templates.
+var feedFuncs = texttemplate.FuncMap{
+	"xml": func(s string) (string, error) {
+		var buf bytes.Buffer
+		if err := xml.EscapeText(&buf, []byte(s)); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	},
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"rfc1123": func(t time.Time) string { return t.UTC().Format(time.RFC1123Z) },
+	"rfc3339": func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+}
+
+// builtinTemplates are the templates rendered under a reserved name that
+// has no template added to the Box.
+var builtinTemplates = map[string]Template{
+	FeedRSS:  mustParseBuiltin(FeedRSS, rssTemplate),
+	FeedAtom: mustParseBuiltin(FeedAtom, atomTemplate),
+	FeedJSON: mustParseBuiltin(FeedJSON, jsonFeedTemplate),
+}
+
+// mustParseBuiltin parses a built-in template with text/template, since
+// its output is not HTML, and panics if it fails to parse.
+func mustParseBuiltin(name, text string) Template {
+	t, err := texttemplate.New(name).Funcs(feedFuncs).Parse(text)
+	if err != nil {
+		panic(fmt.Sprintf("templatebox: parse built-in template %s: %v", name, err))
+	}
+	return t
+}
@@ -0,0 +1,127 @@
+package templatebox_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andyfusniak/templatebox"
+	"github.com/andyfusniak/templatebox/adapter/texttemplate"
+)
+
+var testFeed = templatebox.Feed{
+	Title:   "Example & Co",
+	Link:    "https://example.com/",
+	FeedURL: "https://example.com/feed.xml",
+	Author:  "Example",
+	Items: []templatebox.FeedItem{
+		{
+			Title:   "Hello <World>",
+			URL:     "https://example.com/blog/hello/",
+			Date:    time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+			Summary: "The first post.",
+		},
+		{
+			Title: "Second",
+			URL:   "https://example.com/blog/second/",
+			Date:  time.Date(2024, 4, 2, 9, 0, 0, 0, time.UTC),
+			ID:    "tag:example.com,2024:second",
+		},
+	},
+}
+
+func TestFeeds(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	rss, err := box.RenderString(templatebox.FeedRSS, testFeed)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	var channel struct {
+		Title         string `xml:"channel>title"`
+		LastBuildDate string `xml:"channel>lastBuildDate"`
+		Items         []struct {
+			Title string `xml:"title"`
+			GUID  string `xml:"guid"`
+		} `xml:"channel>item"`
+	}
+	if err := xml.Unmarshal([]byte(rss), &channel); err != nil {
+		t.Fatalf("xml.Unmarshal of RSS failed: %v\n%s", err, rss)
+	}
+	if channel.Title != testFeed.Title || len(channel.Items) != 2 || channel.Items[0].Title != "Hello <World>" {
+		t.Fatalf("RSS feed is %+v, expected the feed title and both items", channel)
+	}
+	if expected := "Tue, 02 Apr 2024 09:00:00 +0000"; channel.LastBuildDate != expected {
+		t.Fatalf("RSS lastBuildDate is %s, expected %s", channel.LastBuildDate, expected)
+	}
+	if expected := "tag:example.com,2024:second"; channel.Items[1].GUID != expected {
+		t.Fatalf("RSS guid is %s, expected %s", channel.Items[1].GUID, expected)
+	}
+
+	atom, err := box.RenderString(templatebox.FeedAtom, testFeed)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	var feed struct {
+		Updated string   `xml:"updated"`
+		Entries []string `xml:"entry>id"`
+	}
+	if err := xml.Unmarshal([]byte(atom), &feed); err != nil {
+		t.Fatalf("xml.Unmarshal of Atom failed: %v\n%s", err, atom)
+	}
+	if expected := "2024-04-02T09:00:00Z"; feed.Updated != expected || len(feed.Entries) != 2 {
+		t.Fatalf("Atom feed is %+v, expected updated %s and 2 entries", feed, expected)
+	}
+
+	jsonFeed, err := box.RenderString(templatebox.FeedJSON, testFeed)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	var jf struct {
+		Version string `json:"version"`
+		Items   []struct {
+			ID            string `json:"id"`
+			Title         string `json:"title"`
+			DatePublished string `json:"date_published"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(jsonFeed), &jf); err != nil {
+		t.Fatalf("json.Unmarshal of JSON Feed failed: %v\n%s", err, jsonFeed)
+	}
+	if len(jf.Items) != 2 || jf.Items[0].Title != "Hello <World>" || jf.Items[0].ID != testFeed.Items[0].URL {
+		t.Fatalf("JSON Feed is %+v, expected both items", jf)
+	}
+}
+
+func TestFeedOverride(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw(templatebox.FeedRSS, templatebox.TemplateSet{
+		Templates: []string{`{{ range .Items }}{{ .Title }};{{ end }}`},
+		Engine:    texttemplate.New(),
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	got, err := box.RenderString(templatebox.FeedRSS, testFeed)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	expected := "Hello <World>;Second;"
+	if got != expected {
+		t.Fatalf("RenderString returned %s, expected %s", got, expected)
+	}
+
+	if _, err := box.RenderString("templatebox:unknown", testFeed); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("RenderString returned %v, expected a not found error", err)
+	}
+}
@@ -0,0 +1,69 @@
+package templatebox
+
+import "sync"
+
+// flightGroup ensures that only one call for a given key is in flight at a
+// time. It is a minimal version of golang.org/x/sync/singleflight that only
+// propagates errors.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	wg  sync.WaitGroup
+	err error
+}
+
+// do calls fn unless a call for key is already in flight, in which case it
+// waits for that call to finish and returns its error.
+func (g *flightGroup) do(key string, fn func() error) error {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.err
+	}
+	c := new(flightCall)
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	c.err = fn()
+	c.wg.Done()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	return c.err
+}
+
+// doAsync calls fn in a new goroutine unless a call for key is already in
+// flight. It reports whether a new call was started. It does not wait.
+func (g *flightGroup) doAsync(key string, fn func() error) bool {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if _, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		return false
+	}
+	c := new(flightCall)
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	go func() {
+		c.err = fn()
+		c.wg.Done()
+
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+	}()
+	return true
+}
@@ -0,0 +1,157 @@
+package templatebox
+
+import (
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+)
+
+// builtinFuncs returns the default FuncMap added to every template in the
+// Box. The global FuncMap and the FuncMap of each FileSet or TemplateSet are
+// added afterwards, so either can override a builtin function of the same
+// name.
+func (b *Box) builtinFuncs() template.FuncMap {
+	return template.FuncMap{
+		"dict":      dict,
+		"list":      listOf,
+		"seq":       seq,
+		"merge":     merge,
+		"classes":   classes,
+		"when":      when,
+		"hxHeaders": hxHeaders,
+		"await":     await,
+		"sanitize":  b.sanitize,
+		"markdown":  b.markdownHTML,
+		"highlight": b.highlightHTML,
+		"asset":     b.assetURL,
+		"srcset":    b.srcset,
+		"imgTag":    b.imgTag,
+		"icon":      b.icon,
+		"readFile":  b.readInclude,
+
+		"version":   b.buildVersion,
+		"commit":    b.buildCommit,
+		"buildTime": b.buildTime,
+		"now":       b.now,
+		"uuid":      b.uuid,
+		"randomHex": b.randomHex,
+
+		"env":       b.env,
+		"isDev":     b.isDev,
+		"isStaging": b.isStaging,
+		"isProd":    b.isProd,
+		"secret":    b.secret,
+
+		sourceFuncName: sourceComment,
+		bannerFuncName: b.bannerComment,
+	}
+}
+
+// dict builds a map from a list of alternating keys and values. It is
+// typically used to pass multiple values into a partial template, for
+// example {{ template "card" dict "Title" .Title "User" $.User }}.
+func dict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict expects an even number of arguments, got %d", len(pairs))
+	}
+
+	m := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		k, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict key at index %d must be a string, got %T", i, pairs[i])
+		}
+		m[k] = pairs[i+1]
+	}
+	return m, nil
+}
+
+// listOf implements the list template function. It returns its arguments
+// as a slice.
+func listOf(items ...any) []any {
+	if items == nil {
+		return []any{}
+	}
+	return items
+}
+
+// seq returns the sequence of integers 0 to n-1. A negative n is an error.
+func seq(n int) ([]int, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("seq expects a non-negative count, got %d", n)
+	}
+
+	s := make([]int, n)
+	for i := range s {
+		s[i] = i
+	}
+	return s, nil
+}
+
+// merge returns a new map containing the keys of all the given maps. When
+// the same key appears in more than one map the value from the right-most
+// map wins. The input maps are not modified.
+func merge(maps ...map[string]any) map[string]any {
+	m := make(map[string]any)
+	for _, src := range maps {
+		for k, v := range src {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// when implements the when template function. It returns class if cond is
+// true in the sense of the if action, and the empty string otherwise, for
+// use with classes.
+func when(cond any, class string) string {
+	if ok, _ := template.IsTrue(cond); ok {
+		return class
+	}
+	return ""
+}
+
+// classes builds the value of a class attribute from its arguments, for
+// example {{ classes "btn" (when .Active "btn-active") }}. Arguments may be
+// strings, which may hold several space separated classes, slices of
+// strings and maps of class names to booleans, which add the classes whose
+// value is true. Empty and repeated classes are left out.
+func classes(args ...any) (string, error) {
+	var list []string
+	seen := make(map[string]bool)
+	add := func(s string) {
+		for _, c := range strings.Fields(s) {
+			if !seen[c] {
+				seen[c] = true
+				list = append(list, c)
+			}
+		}
+	}
+
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case nil:
+		case string:
+			add(v)
+		case []string:
+			for _, s := range v {
+				add(s)
+			}
+		case map[string]bool:
+			names := make([]string, 0, len(v))
+			for name, ok := range v {
+				if ok {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				add(name)
+			}
+		default:
+			return "", fmt.Errorf("classes argument %d must be a string, []string or map[string]bool, got %T", i, arg)
+		}
+	}
+	return strings.Join(list, " "), nil
+}
@@ -0,0 +1,208 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"math/rand"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestBuiltinCompositionFuncs(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("funcs", templatebox.TemplateSet{
+		Templates: []string{
+			`{{ template "card" dict "Title" "Hi" "N" 2 }}|` +
+				`{{ range seq 3 }}{{ . }}{{ end }}|` +
+				`{{ range list "a" "b" }}{{ . }}{{ end }}|` +
+				`{{ $m := merge (dict "a" 1 "b" 2) (dict "b" 3) }}{{ $m.a }}{{ $m.b }}`,
+			`{{ define "card" }}{{ .Title }}-{{ .N }}{{ end }}`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "funcs", nil); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	expected := "Hi-2|012|ab|13"
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+}
+
+func TestBuiltinDictOddArguments(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("bad", templatebox.TemplateSet{
+		Templates: []string{`{{ $d := dict "a" }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "bad", nil); err == nil {
+		t.Fatalf("RenderHTML succeeded, expected an error for odd dict arguments")
+	}
+}
+
+func TestBuiltinFuncsCanBeOverridden(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("override", templatebox.TemplateSet{
+		Templates: []string{`{{ list }}`},
+		FuncMap: templatebox.FuncMap{
+			"list": func() string { return "custom" },
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "override", nil); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	if buf.String() != "custom" {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), "custom")
+	}
+}
+
+func TestBuildInfoFuncs(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetBuildInfo(templatebox.BuildInfo{
+		Version: "v1.4.2",
+		Commit:  "abc123",
+		Time:    time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+	})
+
+	err = box.AddTemplateRaw("footer", templatebox.TemplateSet{
+		Templates: []string{`{{ version }} {{ commit }} {{ buildTime.Format "2006-01-02" }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "footer", nil); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	expected := "v1.4.2 abc123 2024-05-06"
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+}
+
+func TestClock(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		Clock: func() time.Time { return time.Date(2024, 12, 31, 23, 59, 0, 0, time.UTC) },
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("copyright", templatebox.TemplateSet{
+		Templates: []string{`© {{ now.Year }} generated {{ now.Format "2006-01-02T15:04" }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "copyright", nil); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	expected := "© 2024 generated 2024-12-31T23:59"
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+}
+
+func TestRandomFuncs(t *testing.T) {
+	render := func() string {
+		box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+			Random: rand.New(rand.NewSource(1)),
+		})
+		if err != nil {
+			t.Fatalf("NewBoxFromOSDir failed: %v", err)
+		}
+		err = box.AddTemplateRaw("form", templatebox.TemplateSet{
+			Templates: []string{`{{ $id := uuid }}<label for="{{ $id }}"></label><input id="{{ $id }}" name="{{ randomHex 8 }}">`},
+		})
+		if err != nil {
+			t.Fatalf("AddTemplateRaw failed: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := box.RenderHTML(&buf, "form", nil); err != nil {
+			t.Fatalf("RenderHTML failed: %v", err)
+		}
+		return buf.String()
+	}
+
+	got := render()
+	pattern := regexp.MustCompile(`^<label for="([0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12})"></label><input id="([^"]+)" name="[0-9a-f]{16}">$`)
+	m := pattern.FindStringSubmatch(got)
+	if m == nil || m[1] != m[2] {
+		t.Fatalf("RenderHTML returned %s, expected a version 4 UUID used twice and 16 hex digits", got)
+	}
+
+	// the same seed renders the same output
+	if again := render(); again != got {
+		t.Fatalf("RenderHTML returned %s, expected %s", again, got)
+	}
+}
+
+func TestClasses(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("button", templatebox.TemplateSet{
+		Templates: []string{
+			`<button class="{{ classes "btn btn-lg" (when .Active "btn-active") (when .Disabled "btn-disabled") .Extra .Flags "btn" }}"></button>`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	data := map[string]any{
+		"Active":   true,
+		"Disabled": false,
+		"Extra":    []string{"mt-2", `"><script>`},
+		"Flags":    map[string]bool{"wide": true, "dark": true, "hidden": false},
+	}
+	if err := box.RenderHTML(&buf, "button", data); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	expected := `<button class="btn btn-lg btn-active mt-2 &#34;&gt;&lt;script&gt; dark wide"></button>`
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+}
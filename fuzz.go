@@ -0,0 +1,314 @@
+package templatebox
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"text/template/parse"
+)
+
+// FuzzFailure is a problem found by Fuzz.
+type FuzzFailure struct {
+	// Iteration is the render, from zero, that failed.
+	Iteration int
+
+	// Data is the generated data the template was rendered with, to
+	// reproduce the failure in a test.
+	Data any
+
+	// Panic is true if the render panicked, including a runtime panic in a
+	// template function, which text/template returns as an error.
+	Panic bool
+
+	// Err describes the failure: the panic with its stack trace, or the
+	// unescaped markup found in the output.
+	Err error
+}
+
+// fuzzMarker is markup included in generated strings. Escaped output never
+// contains it, so finding it in the output means data reached the page
+// unescaped, such as through a function returning template.HTML.
+const fuzzMarker = "<tbfuzz>"
+
+// fuzzStrings are the hostile strings used for generated data.
+var fuzzStrings = []string{
+	"",
+	" ",
+	fuzzMarker + "<script>alert(1)</script>",
+	`"'><img src=x onerror=alert(1)>` + fuzzMarker,
+	"javascript:alert(1)//" + fuzzMarker,
+	"{{ .Secret }}" + fuzzMarker,
+	"\u202eevil\u202c\u200b\ufeff" + fuzzMarker,
+	"\x00\x01\x1b[31m" + fuzzMarker,
+	"\xff\xfe\xfd" + fuzzMarker,
+	"𝕿𝖊𝖒𝖕𝖑𝖆𝖙𝖊 ﷽ Z̴̡̛a̶l̷g̸o̵ 👩‍👩‍👧‍👦" + fuzzMarker,
+	strings.Repeat("A", 64<<10) + fuzzMarker,
+	strings.Repeat("<tbfuzz>&amp;", 1000),
+}
+
+// Fuzz renders the named template iterations times with randomly generated
+// hostile data, to shake out panics and escaping problems before real data
+// finds them. The data has the shape the template uses, found by analysing
+// its parse trees: maps for the fields it reads and slices for the values
+// it ranges over, holding huge strings, markup, control characters, invalid
+// UTF-8, nils and extreme numbers.
+//
+// Fuzz returns a FuzzFailure for every render that panicked, such as a
+// template function indexing past the end of a slice, or that wrote
+// generated markup without escaping it. Other render errors are expected
+// with such data, such as a function refusing a nil, and are not reported.
+// The template is executed directly, without the render hooks of the Box
+// such as Config.Tee, Config.StrictData and the statistics. The data is
+// generated from Config.Random if set, so a seeded source reproduces the
+// same data.
+func (b *Box) Fuzz(name string, iterations int) ([]FuzzFailure, error) {
+	t, _, err := b.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	page, entry, isFragment := strings.Cut(name, "#")
+	srcs, err := b.templateSources(page)
+	if err != nil {
+		return nil, err
+	}
+	trees, err := parseTrees(srcs)
+	if err != nil {
+		return nil, fmt.Errorf("fuzz %s: %w", name, err)
+	}
+	if !isFragment {
+		entry = srcs[0].Name
+	}
+
+	root := &fuzzShape{}
+	if tree, ok := trees[entry]; ok {
+		a := &fuzzAnalysis{trees: trees, root: root, seen: make(map[fuzzVisit]bool)}
+		a.walk(tree.Root, root)
+	}
+
+	seed, err := b.randomBytes(8)
+	if err != nil {
+		return nil, err
+	}
+	rnd := rand.New(rand.NewSource(int64(binary.LittleEndian.Uint64(seed))))
+
+	var failures []FuzzFailure
+	for i := 0; i < iterations; i++ {
+		data := root.generate(rnd, 0)
+		out, panicked, err := fuzzExecute(t, data)
+		var rerr runtime.Error
+		if errors.As(err, &rerr) {
+			panicked = true
+		}
+		switch {
+		case panicked:
+			failures = append(failures, FuzzFailure{Iteration: i, Data: data, Panic: true, Err: err})
+		case err == nil && bytes.Contains(out, []byte(fuzzMarker)):
+			j := bytes.Index(out, []byte(fuzzMarker))
+			failures = append(failures, FuzzFailure{
+				Iteration: i,
+				Data:      data,
+				Err:       fmt.Errorf("unescaped markup in output: %q", excerpt(out, j)),
+			})
+		}
+	}
+	return failures, nil
+}
+
+// fuzzExecute executes t with data, recovering a panic.
+func fuzzExecute(t Template, data any) (out []byte, panicked bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	var buf bytes.Buffer
+	err = t.Execute(&buf, data)
+	return buf.Bytes(), false, err
+}
+
+// excerpt returns up to 40 bytes of output either side of offset i.
+func excerpt(out []byte, i int) string {
+	start, end := max(i-40, 0), min(i+len(fuzzMarker)+40, len(out))
+	return string(out[start:end])
+}
+
+// fuzzShape is the shape of the data a template uses: the fields read from
+// a value and the shape of its elements when ranged over.
+type fuzzShape struct {
+	fields map[string]*fuzzShape
+	elem   *fuzzShape
+}
+
+// field returns the shape of the named field, adding it if needed.
+func (s *fuzzShape) field(name string) *fuzzShape {
+	if s.fields == nil {
+		s.fields = make(map[string]*fuzzShape)
+	}
+	f, ok := s.fields[name]
+	if !ok {
+		f = &fuzzShape{}
+		s.fields[name] = f
+	}
+	return f
+}
+
+// path returns the shape at the field path below s.
+func (s *fuzzShape) path(idents []string) *fuzzShape {
+	for _, name := range idents {
+		s = s.field(name)
+	}
+	return s
+}
+
+// maxFuzzDepth bounds the nesting of generated data.
+const maxFuzzDepth = 8
+
+// generate returns random data of the shape. Values ranged over are slices
+// of up to three elements, or occasionally nil. Values with fields are
+// maps, occasionally nil. Other values are a random hostile value.
+func (s *fuzzShape) generate(rnd *rand.Rand, depth int) any {
+	if depth > maxFuzzDepth || rnd.Intn(10) == 0 {
+		return nil
+	}
+	switch {
+	case s.elem != nil:
+		n := rnd.Intn(4)
+		v := make([]any, n)
+		for i := range v {
+			v[i] = s.elem.generate(rnd, depth+1)
+		}
+		return v
+	case s.fields != nil:
+		m := make(map[string]any, len(s.fields))
+		for name, f := range s.fields {
+			m[name] = f.generate(rnd, depth+1)
+		}
+		return m
+	}
+	return fuzzValue(rnd)
+}
+
+// fuzzValue returns a random hostile scalar value.
+func fuzzValue(rnd *rand.Rand) any {
+	switch rnd.Intn(8) {
+	case 0:
+		return 0
+	case 1:
+		return -1
+	case 2:
+		return math.MaxInt64
+	case 3:
+		return []float64{math.NaN(), math.Inf(1), math.Copysign(0, -1), 1e308}[rnd.Intn(4)]
+	case 4:
+		return rnd.Intn(2) == 0
+	}
+	return fuzzStrings[rnd.Intn(len(fuzzStrings))]
+}
+
+// fuzzVisit is a template walked with a dot of a given shape, recorded to
+// stop recursive templates from being walked forever.
+type fuzzVisit struct {
+	name string
+	dot  *fuzzShape
+}
+
+// fuzzAnalysis finds the shape of the data used by a set of templates.
+type fuzzAnalysis struct {
+	trees map[string]*parse.Tree
+	root  *fuzzShape
+	seen  map[fuzzVisit]bool
+}
+
+// walk records the data used by n, with dot the shape of the value of dot.
+func (a *fuzzAnalysis) walk(n parse.Node, dot *fuzzShape) {
+	switch n := n.(type) {
+	case nil:
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			a.walk(c, dot)
+		}
+	case *parse.ActionNode:
+		a.pipe(n.Pipe, dot)
+	case *parse.IfNode:
+		a.pipe(n.Pipe, dot)
+		a.walk(n.List, dot)
+		a.walk(n.ElseList, dot)
+	case *parse.RangeNode:
+		s := a.pipe(n.Pipe, dot)
+		if s == nil {
+			s = &fuzzShape{}
+		}
+		if s.elem == nil {
+			s.elem = &fuzzShape{}
+		}
+		a.walk(n.List, s.elem)
+		a.walk(n.ElseList, dot)
+	case *parse.WithNode:
+		s := a.pipe(n.Pipe, dot)
+		if s == nil {
+			s = &fuzzShape{}
+		}
+		a.walk(n.List, s)
+		a.walk(n.ElseList, dot)
+	case *parse.TemplateNode:
+		s := a.pipe(n.Pipe, dot)
+		tree, ok := a.trees[n.Name]
+		if s == nil || !ok {
+			return
+		}
+		if v := (fuzzVisit{n.Name, s}); !a.seen[v] {
+			a.seen[v] = true
+			a.walk(tree.Root, s)
+		}
+	}
+}
+
+// pipe records the data used by the pipeline and returns the shape of its
+// value when it is a single field, variable or dot, and nil otherwise.
+func (a *fuzzAnalysis) pipe(p *parse.PipeNode, dot *fuzzShape) *fuzzShape {
+	if p == nil {
+		return nil
+	}
+	var last *fuzzShape
+	for _, cmd := range p.Cmds {
+		last = nil
+		for _, arg := range cmd.Args {
+			last = a.arg(arg, dot)
+		}
+		if len(cmd.Args) != 1 {
+			last = nil
+		}
+	}
+	return last
+}
+
+// arg records the data used by a command argument and returns its shape.
+func (a *fuzzAnalysis) arg(n parse.Node, dot *fuzzShape) *fuzzShape {
+	switch n := n.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return dot.path(n.Ident)
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" {
+			return a.root.path(n.Ident[1:])
+		}
+	case *parse.PipeNode:
+		return a.pipe(n, dot)
+	case *parse.ChainNode:
+		if s := a.arg(n.Node, dot); s != nil {
+			return s.path(n.Field)
+		}
+	}
+	return nil
+}
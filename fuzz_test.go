@@ -0,0 +1,74 @@
+package templatebox_test
+
+import (
+	"fmt"
+	"html/template"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestFuzz(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		Random: rand.New(rand.NewSource(1)),
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("safe", templatebox.TemplateSet{
+		Templates: []string{
+			`<h1 title="{{ .Title }}">{{ .Title }}</h1>{{ range .Items }}{{ template "item" . }}{{ end }}`,
+			`{{ define "item" }}<a href="{{ .URL }}">{{ .Name }}</a>{{ end }}`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	failures, err := box.Fuzz("safe", 200)
+	if err != nil {
+		t.Fatalf("Fuzz failed: %v", err)
+	}
+	if len(failures) != 0 {
+		t.Fatalf("Fuzz returned %d failures, expected none: %v", len(failures), failures[0].Err)
+	}
+
+	err = box.AddTemplateRaw("unsafe", templatebox.TemplateSet{
+		Templates: []string{`{{ with .User }}<p>{{ raw .Bio }}</p>{{ end }}`},
+		FuncMap: templatebox.FuncMap{
+			"raw": func(v any) template.HTML { return template.HTML(fmt.Sprint(v)) },
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	failures, err = box.Fuzz("unsafe", 200)
+	if err != nil {
+		t.Fatalf("Fuzz failed: %v", err)
+	}
+	if len(failures) == 0 || failures[0].Panic || !strings.Contains(failures[0].Err.Error(), "unescaped markup") {
+		t.Fatalf("Fuzz returned %v, expected unescaped markup failures", failures)
+	}
+	if _, ok := failures[0].Data.(map[string]any)["User"].(map[string]any)["Bio"].(string); !ok {
+		t.Fatalf("Fuzz failure data %v, expected the generated User.Bio string", failures[0].Data)
+	}
+
+	err = box.AddTemplateRaw("panics", templatebox.TemplateSet{
+		Templates: []string{`{{ first .Tags }}`},
+		FuncMap: templatebox.FuncMap{
+			"first": func(v any) any { s, _ := v.([]any); return s[0] },
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	failures, err = box.Fuzz("panics", 50)
+	if err != nil {
+		t.Fatalf("Fuzz failed: %v", err)
+	}
+	if len(failures) == 0 || !failures[0].Panic {
+		t.Fatalf("Fuzz returned %v, expected panics", failures)
+	}
+}
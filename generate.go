@@ -0,0 +1,68 @@
+package templatebox
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// GenerateNames writes a Go source file for package pkg declaring a string
+// constant for the name of every registered template, so that renaming or
+// removing a template breaks the compilation of call sites instead of
+// producing a "template not found" error at runtime. Constant names are the
+// template names converted to CamelCase, for example "admin/users-edit"
+// becomes AdminUsersEdit. It is typically called from a small program run
+// by go generate after the templates have been added to the Box.
+func (b *Box) GenerateNames(w io.Writer, pkg string) error {
+	if !token.IsIdentifier(pkg) {
+		return fmt.Errorf("invalid package name %q", pkg)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by templatebox; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	fmt.Fprintf(&buf, "// Template names registered with the templatebox.Box.\n")
+	fmt.Fprintf(&buf, "const (\n")
+
+	seen := make(map[string]string)
+	for _, name := range b.Names() {
+		ident := constName(name)
+		if other, ok := seen[ident]; ok {
+			return fmt.Errorf("templates %q and %q both generate the constant %s", other, name, ident)
+		}
+		seen[ident] = name
+		fmt.Fprintf(&buf, "\t%s = %q\n", ident, name)
+	}
+	fmt.Fprintf(&buf, ")\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("format generated source failed: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// constName converts a template name to an exported Go identifier.
+func constName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var sb strings.Builder
+	for _, p := range parts {
+		r := []rune(p)
+		r[0] = unicode.ToUpper(r[0])
+		sb.WriteString(string(r))
+	}
+
+	ident := sb.String()
+	if ident == "" || !unicode.IsLetter([]rune(ident)[0]) {
+		ident = "T" + ident
+	}
+	return ident
+}
@@ -0,0 +1,61 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestGenerateNames(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	for _, name := range []string{"admin/users-edit", "home", "404"} {
+		err := box.AddTemplateRaw(name, templatebox.TemplateSet{Templates: []string{name}})
+		if err != nil {
+			t.Fatalf("AddTemplateRaw failed: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := box.GenerateNames(&buf, "templates"); err != nil {
+		t.Fatalf("GenerateNames failed: %v", err)
+	}
+
+	expected := `// Code generated by templatebox; DO NOT EDIT.
+
+package templates
+
+// Template names registered with the templatebox.Box.
+const (
+	T404           = "404"
+	AdminUsersEdit = "admin/users-edit"
+	Home           = "home"
+)
+`
+	if buf.String() != expected {
+		t.Fatalf("GenerateNames wrote\n%s\nexpected\n%s", buf.String(), expected)
+	}
+}
+
+func TestGenerateNamesCollision(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	for _, name := range []string{"user-edit", "user_edit"} {
+		err := box.AddTemplateRaw(name, templatebox.TemplateSet{Templates: []string{name}})
+		if err != nil {
+			t.Fatalf("AddTemplateRaw failed: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := box.GenerateNames(&buf, "templates"); err == nil {
+		t.Fatalf("GenerateNames succeeded, expected a collision error")
+	}
+}
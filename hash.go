@@ -0,0 +1,71 @@
+package templatebox
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// TemplateHash is the content hash of the sources a template was parsed
+// from.
+type TemplateHash struct {
+	// Sum is the hex encoded SHA-256 of the names and contents of the
+	// sources in order.
+	Sum string `json:"sum"`
+
+	// Files maps each filename of a FileSet to the hex encoded SHA-256 of
+	// its content. It is nil for templates added with AddTemplateRaw.
+	Files map[string]string `json:"files,omitempty"`
+}
+
+// hashSources returns the TemplateHash of srcs.
+func hashSources(srcs []Source) TemplateHash {
+	var h TemplateHash
+	sum := sha256.New()
+	for _, src := range srcs {
+		fmt.Fprintf(sum, "%s\x00%d\x00", src.Name, len(src.Text))
+		sum.Write(src.Text)
+
+		if src.Filename != "" {
+			if h.Files == nil {
+				h.Files = make(map[string]string)
+			}
+			fileSum := sha256.Sum256(src.Text)
+			h.Files[src.Filename] = hex.EncodeToString(fileSum[:])
+		}
+	}
+	h.Sum = hex.EncodeToString(sum.Sum(nil))
+	return h
+}
+
+// Hash returns the hash of the sources the named template was last parsed
+// from, and whether the template exists. In debug mode it reflects the
+// last rebuild.
+func (b *Box) Hash(name string) (TemplateHash, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	h, ok := b.hashes[name]
+	return h, ok
+}
+
+// Fingerprint returns a hex encoded SHA-256 over the names and hashes of
+// every registered template. Two Boxes with the same Fingerprint serve
+// identical template sources, so replicas can compare it to verify a
+// deployment, and it can be used as a stable cache key or ETag component
+// for the whole template set. FuncMaps and other settings are not included.
+func (b *Box) Fingerprint() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return fingerprint(b.hashes)
+}
+
+// fingerprint returns the hex encoded SHA-256 over the names and hashes of
+// the templates.
+func fingerprint(hashes map[string]TemplateHash) string {
+	sum := sha256.New()
+	for _, name := range sortedKeys(hashes) {
+		fmt.Fprintf(sum, "%s\x00%s\n", name, hashes[name].Sum)
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
@@ -0,0 +1,48 @@
+package templatebox_test
+
+import (
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestFingerprint(t *testing.T) {
+	newBox := func(dir string) *templatebox.Box {
+		box, err := templatebox.NewBoxFromOSDir(dir, nil)
+		if err != nil {
+			t.Fatalf("NewBoxFromOSDir failed: %v", err)
+		}
+		if err := box.AddTemplate("a", templatebox.FileSet{Filenames: []string{"layout.html", "a.html"}}); err != nil {
+			t.Fatalf("AddTemplate failed: %v", err)
+		}
+		return box
+	}
+
+	dir1, dir2 := t.TempDir(), t.TempDir()
+	files := map[string]string{"layout.html": `<main>{{ block "content" . }}{{ end }}</main>`, "a.html": `a`}
+	writeTemplates(t, dir1, files)
+	writeTemplates(t, dir2, files)
+
+	box1, box2 := newBox(dir1), newBox(dir2)
+	if box1.Fingerprint() != box2.Fingerprint() {
+		t.Fatalf("Fingerprint differs for identical templates: %s and %s", box1.Fingerprint(), box2.Fingerprint())
+	}
+
+	h, ok := box1.Hash("a")
+	if !ok {
+		t.Fatalf("Hash returned false, expected the template to exist")
+	}
+	// SHA-256 of "a"
+	expected := "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"
+	if h.Files["a.html"] != expected {
+		t.Fatalf("Hash file a.html returned %s, expected %s", h.Files["a.html"], expected)
+	}
+
+	writeTemplates(t, dir2, map[string]string{"a.html": `b`})
+	if err := box2.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if box1.Fingerprint() == box2.Fingerprint() {
+		t.Fatalf("Fingerprint unchanged after a template file changed")
+	}
+}
@@ -0,0 +1,64 @@
+package templatebox
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+)
+
+// Highlighter renders source code as syntax highlighted HTML. The HTML it
+// returns is trusted, so it must escape the code.
+type Highlighter interface {
+	Highlight(lang, code string) (string, error)
+}
+
+// HighlighterFunc is an adapter to allow the use of an ordinary function as
+// a Highlighter.
+type HighlighterFunc func(lang, code string) (string, error)
+
+// Highlight calls f(lang, code).
+func (f HighlighterFunc) Highlight(lang, code string) (string, error) {
+	return f(lang, code)
+}
+
+// SetHighlighter sets the Highlighter used by the highlight template
+// function and for the fenced code blocks of converted markdown.
+func (b *Box) SetHighlighter(h Highlighter) {
+	b.highlighter = h
+}
+
+// highlightHTML implements the highlight template function.
+func (b *Box) highlightHTML(lang, code string) (template.HTML, error) {
+	if b.highlighter == nil {
+		return "", fmt.Errorf("highlight called but no Highlighter is set")
+	}
+	out, err := b.highlighter.Highlight(lang, code)
+	if err != nil {
+		return "", fmt.Errorf("highlight %s code failed: %w", lang, err)
+	}
+	return template.HTML(out), nil
+}
+
+// codeBlockPattern matches a fenced code block as converted by CommonMark
+// implementations, capturing its language and escaped code.
+var codeBlockPattern = regexp.MustCompile(`(?s)<pre><code class="language-([^"\s]+)">(.*?)</code></pre>`)
+
+// highlightCodeBlocks replaces the fenced code blocks with a language in
+// converted markdown with their highlighted HTML, if a Highlighter is set.
+func (b *Box) highlightCodeBlocks(s string) (string, error) {
+	if b.highlighter == nil {
+		return s, nil
+	}
+
+	var firstErr error
+	out := codeBlockPattern.ReplaceAllStringFunc(s, func(m string) string {
+		sub := codeBlockPattern.FindStringSubmatch(m)
+		h, err := b.highlighter.Highlight(sub[1], html.UnescapeString(sub[2]))
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("highlight %s code block failed: %w", sub[1], err)
+		}
+		return h
+	})
+	return out, firstErr
+}
@@ -0,0 +1,73 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"html"
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+// bracketHighlighter wraps code in a span naming its language.
+var bracketHighlighter = templatebox.HighlighterFunc(func(lang, code string) (string, error) {
+	return `<pre class="hl"><span class="` + lang + `">` + html.EscapeString(code) + `</span></pre>`, nil
+})
+
+func TestHighlightFunc(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetHighlighter(bracketHighlighter)
+
+	err = box.AddTemplateRaw("snippet", templatebox.TemplateSet{
+		Templates: []string{`{{ highlight "go" .Code }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "snippet", map[string]any{"Code": `s := "<b>"`}); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	expected := `<pre class="hl"><span class="go">s := &#34;&lt;b&gt;&#34;</span></pre>`
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+}
+
+func TestMarkdownHighlightsCodeBlocks(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	// convert ```lang fenced blocks the way CommonMark implementations do
+	box.SetMarkdownConverter(templatebox.MarkdownConverterFunc(func(s string) (string, error) {
+		var out string
+		for _, block := range strings.Split(s, "\n\n") {
+			if lang, code, ok := strings.Cut(strings.TrimPrefix(block, "```"), "\n"); ok && strings.HasPrefix(block, "```") {
+				code = strings.TrimSuffix(code, "```")
+				out += `<pre><code class="language-` + lang + `">` + html.EscapeString(code) + "</code></pre>"
+				continue
+			}
+			out += "<p>" + block + "</p>"
+		}
+		return out, nil
+	}))
+	box.SetSanitizer(templatebox.SanitizerFunc(func(s string) string { return s }))
+	box.SetHighlighter(bracketHighlighter)
+
+	content, err := box.Markdown("Example:\n\n```go\nif a < b {}\n```")
+	if err != nil {
+		t.Fatalf("Markdown failed: %v", err)
+	}
+
+	expected := `<p>Example:</p><pre class="hl"><span class="go">if a &lt; b {}` + "\n" + `</span></pre>`
+	if string(content.HTML) != expected {
+		t.Fatalf("Markdown returned %s, expected %s", content.HTML, expected)
+	}
+}
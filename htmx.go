@@ -0,0 +1,49 @@
+package templatebox
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"html/template"
+)
+
+// lookupFragment returns the template named fragment defined by the
+// html/template page, for names of the form "page#fragment". This lets a
+// handler answering an htmx request render a single part of a page, such
+// as "contacts#row", from the same files as the full page.
+func (b *Box) lookupFragment(name, page, fragment string) (Template, renderOptions, error) {
+	t, _, err := b.lookup(page)
+	if err != nil {
+		return nil, renderOptions{}, err
+	}
+	ht, ok := t.(*template.Template)
+	if !ok {
+		return nil, renderOptions{}, fmt.Errorf("template %s: fragments are only supported by html/template", name)
+	}
+	f := ht.Lookup(fragment)
+	if f == nil {
+		return nil, renderOptions{}, fmt.Errorf("template %s not found: %s defines no template %s", name, page, fragment)
+	}
+	return f, renderOptions{output: OutputFragment}, nil
+}
+
+// hxHeaders implements the hxHeaders template function. It builds an
+// hx-headers attribute from alternating header names and values, for
+// example <body {{ hxHeaders "X-CSRF-Token" .CSRFToken }}>, so every htmx
+// request from the element sends the headers.
+func hxHeaders(pairs ...any) (template.HTMLAttr, error) {
+	m, err := dict(pairs...)
+	if err != nil {
+		return "", fmt.Errorf("hxHeaders: %w", err)
+	}
+	headers := make(map[string]string, len(m))
+	for k, v := range m {
+		headers[k] = fmt.Sprint(v)
+	}
+
+	j, err := json.Marshal(headers)
+	if err != nil {
+		return "", fmt.Errorf("hxHeaders: %w", err)
+	}
+	return template.HTMLAttr(`hx-headers="` + html.EscapeString(string(j)) + `"`), nil
+}
@@ -0,0 +1,69 @@
+package templatebox_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestFragments(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("contacts", templatebox.TemplateSet{
+		Templates: []string{
+			`<table>{{ template "rows" . }}</table>`,
+			`{{ define "rows" }}{{ range . }}<tr><td>{{ . }}</td></tr>{{ end }}{{ end }}`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	data := []string{"Ada", "<Grace>"}
+	got, err := box.RenderString("contacts#rows", data)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	expected := "<tr><td>Ada</td></tr><tr><td>&lt;Grace&gt;</td></tr>"
+	if got != expected {
+		t.Fatalf("RenderString returned %s, expected %s", got, expected)
+	}
+
+	page, err := box.RenderString("contacts", data)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	if expected := "<table>" + expected + "</table>"; page != expected {
+		t.Fatalf("RenderString returned %s, expected %s", page, expected)
+	}
+
+	_, err = box.RenderString("contacts#missing", data)
+	if err == nil || !strings.Contains(err.Error(), "contacts defines no template missing") {
+		t.Fatalf("RenderString returned %v, expected a missing fragment error", err)
+	}
+}
+
+func TestHXHeaders(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("body", templatebox.TemplateSet{
+		Templates: []string{`<body {{ hxHeaders "X-CSRF-Token" .Token "X-Tenant" 7 }}></body>`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	got, err := box.RenderString("body", map[string]string{"Token": `a"b<c>`})
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	expected := `<body hx-headers="{&#34;X-CSRF-Token&#34;:&#34;a\&#34;b\u003cc\u003e&#34;,&#34;X-Tenant&#34;:&#34;7&#34;}"></body>`
+	if got != expected {
+		t.Fatalf("RenderString returned %s, expected %s", got, expected)
+	}
+}
@@ -0,0 +1,165 @@
+package templatebox
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HumanizeFuncs returns the optional humanize functions, for dashboards and
+// other pages showing quantities to people. Add them with
+// SetGlobalFuncMap or to the FuncMap of a template set:
+//
+//   - humanBytes formats a size in bytes with SI units: {{ humanBytes 1400000 }} is "1.4 MB"
+//   - timeAgo formats a time relative to now: "3 minutes ago" or "in 2 days"
+//   - humanDuration formats a time.Duration in its largest unit: "3 hours"
+//   - ordinal formats a number as an ordinal: "1st", "22nd", "13th"
+//   - abbrevInt abbreviates a large number: {{ abbrevInt 12400 }} is "12.4k"
+//
+// timeAgo uses Config.Clock for the current time. The numeric functions
+// accept any integer or floating point type.
+func (b *Box) HumanizeFuncs() FuncMap {
+	return FuncMap{
+		"humanBytes":    humanBytes,
+		"timeAgo":       b.timeAgo,
+		"humanDuration": humanDuration,
+		"ordinal":       ordinal,
+		"abbrevInt":     abbrevInt,
+	}
+}
+
+// toFloat converts a number of any integer or floating point type to a
+// float64.
+func toFloat(fn string, v any) (float64, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	}
+	return 0, fmt.Errorf("%s expects a number, got %T", fn, v)
+}
+
+// scaled formats v divided by the largest power of base, up to the number
+// of units, that leaves it at least 1, with one decimal place unless it is
+// a whole number. Values below base are formatted without a unit.
+func scaled(v, base float64, sep string, units []string) string {
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	if v < base {
+		return sign + strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	i := -1
+	for i+1 < len(units) && v >= base {
+		v /= base
+		i++
+	}
+	// rounding can carry into the next unit, such as 999.96k to 1000.0k
+	if r := math.Round(v*10) / 10; r >= base && i+1 < len(units) {
+		v /= base
+		i++
+	}
+	s := strconv.FormatFloat(math.Round(v*10)/10, 'f', 1, 64)
+	return sign + strings.TrimSuffix(s, ".0") + sep + units[i]
+}
+
+// humanBytes implements the humanBytes function.
+func humanBytes(size any) (string, error) {
+	v, err := toFloat("humanBytes", size)
+	if err != nil {
+		return "", err
+	}
+	if math.Abs(v) < 1000 {
+		return scaled(v, 1000, "", nil) + " B", nil
+	}
+	return scaled(v, 1000, " ", []string{"kB", "MB", "GB", "TB", "PB", "EB"}), nil
+}
+
+// abbrevInt implements the abbrevInt function.
+func abbrevInt(n any) (string, error) {
+	v, err := toFloat("abbrevInt", n)
+	if err != nil {
+		return "", err
+	}
+	return scaled(math.Trunc(v), 1000, "", []string{"k", "M", "B", "T"}), nil
+}
+
+// durationUnits are the units of humanDuration, largest first. Months and
+// years are approximated as 30 and 365 days.
+var durationUnits = []struct {
+	d    time.Duration
+	name string
+}{
+	{365 * 24 * time.Hour, "year"},
+	{30 * 24 * time.Hour, "month"},
+	{7 * 24 * time.Hour, "week"},
+	{24 * time.Hour, "day"},
+	{time.Hour, "hour"},
+	{time.Minute, "minute"},
+	{time.Second, "second"},
+}
+
+// humanDuration implements the humanDuration function. The duration is
+// truncated to whole units, so 90 minutes is "1 hour".
+func humanDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	for _, u := range durationUnits {
+		if n := int64(d / u.d); n > 0 {
+			if n == 1 {
+				return "1 " + u.name
+			}
+			return strconv.FormatInt(n, 10) + " " + u.name + "s"
+		}
+	}
+	return "0 seconds"
+}
+
+// timeAgo implements the timeAgo function. Times less than a minute from
+// now are "just now".
+func (b *Box) timeAgo(t time.Time) string {
+	d := b.now().Sub(t)
+	switch {
+	case d > -time.Minute && d < time.Minute:
+		return "just now"
+	case d < 0:
+		return "in " + humanDuration(d)
+	}
+	return humanDuration(d) + " ago"
+}
+
+// ordinal implements the ordinal function.
+func ordinal(n any) (string, error) {
+	v, err := toFloat("ordinal", n)
+	if err != nil {
+		return "", err
+	}
+	i := int64(v)
+	m := i % 100
+	if m < 0 {
+		m = -m
+	}
+
+	suffix := "th"
+	if m < 11 || m > 13 {
+		switch m % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.FormatInt(i, 10) + suffix, nil
+}
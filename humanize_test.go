@@ -0,0 +1,64 @@
+package templatebox_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestHumanizeFuncs(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		Clock: func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetGlobalFuncMap(box.HumanizeFuncs())
+
+	tests := []struct {
+		template string
+		data     any
+		expected string
+	}{
+		{`{{ humanBytes . }}`, 512, "512 B"},
+		{`{{ humanBytes . }}`, int64(1400000), "1.4 MB"},
+		{`{{ humanBytes . }}`, uint64(1000), "1 kB"},
+		{`{{ humanBytes . }}`, 999960, "1 MB"},
+		{`{{ abbrevInt . }}`, 950, "950"},
+		{`{{ abbrevInt . }}`, 12400, "12.4k"},
+		{`{{ abbrevInt . }}`, -2500000, "-2.5M"},
+		{`{{ timeAgo . }}`, now.Add(-3 * time.Minute), "3 minutes ago"},
+		{`{{ timeAgo . }}`, now.Add(-26 * time.Hour), "1 day ago"},
+		{`{{ timeAgo . }}`, now.Add(-10 * time.Second), "just now"},
+		{`{{ timeAgo . }}`, now.Add(49 * time.Hour), "in 2 days"},
+		{`{{ humanDuration . }}`, 90 * time.Minute, "1 hour"},
+		{`{{ ordinal . }}`, 1, "1st"},
+		{`{{ ordinal . }}`, 22, "22nd"},
+		{`{{ ordinal . }}`, 13, "13th"},
+		{`{{ ordinal . }}`, 103, "103rd"},
+		{`{{ ordinal . }}`, 111, "111th"},
+	}
+	for i, tt := range tests {
+		err := box.AddTemplateRaw("humanize", templatebox.TemplateSet{Templates: []string{tt.template}})
+		if err != nil {
+			t.Fatalf("AddTemplateRaw failed: %v", err)
+		}
+		got, err := box.RenderString("humanize", tt.data)
+		if err != nil {
+			t.Fatalf("test %d: RenderString failed: %v", i, err)
+		}
+		if got != tt.expected {
+			t.Fatalf("test %d: %s returned %s, expected %s", i, tt.template, got, tt.expected)
+		}
+	}
+
+	err = box.AddTemplateRaw("humanize", templatebox.TemplateSet{Templates: []string{`{{ humanBytes . }}`}})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	if _, err := box.RenderString("humanize", "big"); err == nil {
+		t.Fatalf("RenderString succeeded, expected an error for a string")
+	}
+}
@@ -0,0 +1,161 @@
+package templatebox
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"io/fs"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Icons configures the icon template function, which renders an icon as
+// SVG markup with classes, for example {{ icon "trash" "size-4" }}.
+type Icons struct {
+	// SpriteURL is the path of an SVG sprite whose symbols have the names
+	// of the icons as ids, resolved in the same way as the asset function.
+	// Icons are rendered as an svg element using the symbol of the sprite,
+	// so the sprite is downloaded once and cached by the browser.
+	SpriteURL string
+
+	// Dir holds an SVG file per icon, named after the icon, such as
+	// trash.svg or solid/trash.svg, and is used when SpriteURL is empty.
+	// The markup of each icon is inlined with the classes added to its svg
+	// element. Files are read and parsed once, except in debug mode.
+	Dir fs.FS
+
+	// Class is added to every icon before the classes given to the icon
+	// function, for example "icon".
+	Class string
+}
+
+// SetIcons sets the icon configuration used by the icon template function.
+func (b *Box) SetIcons(ic Icons) {
+	b.muIcons.Lock()
+	defer b.muIcons.Unlock()
+	b.icons = ic
+	b.iconCache = make(map[string]*parsedIcon)
+}
+
+// parsedIcon is an SVG file of Icons.Dir split around the class attribute
+// of its svg element.
+type parsedIcon struct {
+	open        string // the svg start tag without class and closing bracket
+	class       string // classes of the svg element
+	ariaHidden  bool   // whether the svg element has an aria-hidden attribute
+	selfClosing bool
+	rest        string // the markup after the svg start tag
+}
+
+// icon implements the icon template function.
+func (b *Box) icon(name string, class ...string) (template.HTML, error) {
+	b.muIcons.RLock()
+	ic := b.icons
+	b.muIcons.RUnlock()
+
+	classes := strings.Join(strings.Fields(strings.Join(append([]string{ic.Class}, class...), " ")), " ")
+	if ic.SpriteURL != "" {
+		href := b.assetURL(ic.SpriteURL) + "#" + name
+		return template.HTML(fmt.Sprintf(`<svg class="%s" aria-hidden="true"><use href="%s"></use></svg>`,
+			html.EscapeString(classes), html.EscapeString(href))), nil
+	}
+	if ic.Dir == nil {
+		return "", fmt.Errorf("icon %s called but no Icons are set", name)
+	}
+
+	p, err := b.loadIcon(ic.Dir, name)
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(p.render(classes)), nil
+}
+
+// loadIcon returns the parsed SVG file of the named icon, from the cache
+// unless in debug mode.
+func (b *Box) loadIcon(dir fs.FS, name string) (*parsedIcon, error) {
+	b.muIcons.RLock()
+	p, ok := b.iconCache[name]
+	b.muIcons.RUnlock()
+	if ok && !b.debug() {
+		return p, nil
+	}
+
+	filename := name + ".svg"
+	if !fs.ValidPath(filename) {
+		return nil, fmt.Errorf("icon %s: %w: %s", name, ErrInvalidPath, filename)
+	}
+	svg, err := fs.ReadFile(dir, filename)
+	if err != nil {
+		return nil, fmt.Errorf("icon %s: %w", name, err)
+	}
+	p, err = parseIcon(string(svg))
+	if err != nil {
+		return nil, fmt.Errorf("icon %s: %w", name, err)
+	}
+
+	b.muIcons.Lock()
+	b.iconCache[name] = p
+	b.muIcons.Unlock()
+	return p, nil
+}
+
+// classAttrPattern matches a class attribute and its value.
+var classAttrPattern = regexp.MustCompile(`(?i)\sclass\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+// ariaHiddenPattern matches an aria-hidden attribute.
+var ariaHiddenPattern = regexp.MustCompile(`(?i)\saria-hidden\b`)
+
+// parseIcon splits the SVG markup around the class attribute of its svg
+// element. Anything before the svg element, such as an XML declaration or
+// comments, is dropped.
+func parseIcon(svg string) (*parsedIcon, error) {
+	for i := strings.IndexByte(svg, '<'); i >= 0; {
+		end := tagEnd(svg, i)
+		tag := svg[i:end]
+		if tagName(tag) == "svg" {
+			p := &parsedIcon{rest: strings.TrimRightFunc(svg[end:], unicode.IsSpace)}
+			if m := classAttrPattern.FindStringSubmatch(tag); m != nil {
+				p.class = html.UnescapeString(m[1] + m[2] + m[3])
+				tag = strings.Replace(tag, m[0], "", 1)
+			}
+			p.ariaHidden = ariaHiddenPattern.MatchString(tag)
+
+			tag = strings.TrimSuffix(tag, ">")
+			if strings.HasSuffix(tag, "/") {
+				p.selfClosing = true
+				tag = strings.TrimSuffix(tag, "/")
+			}
+			p.open = strings.TrimRightFunc(tag, unicode.IsSpace)
+			return p, nil
+		}
+
+		j := strings.IndexByte(svg[end:], '<')
+		if j < 0 {
+			break
+		}
+		i = end + j
+	}
+	return nil, fmt.Errorf("no svg element found")
+}
+
+// render returns the markup of the icon with the classes added to those of
+// its svg element. Icons are hidden from assistive technology unless the
+// file sets aria-hidden itself.
+func (p *parsedIcon) render(classes string) string {
+	var sb strings.Builder
+	sb.WriteString(p.open)
+	if c := strings.TrimSpace(p.class + " " + classes); c != "" {
+		fmt.Fprintf(&sb, ` class="%s"`, html.EscapeString(c))
+	}
+	if !p.ariaHidden {
+		sb.WriteString(` aria-hidden="true"`)
+	}
+	if p.selfClosing {
+		sb.WriteString("/>")
+		return sb.String()
+	}
+	sb.WriteByte('>')
+	sb.WriteString(p.rest)
+	return sb.String()
+}
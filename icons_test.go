@@ -0,0 +1,81 @@
+package templatebox_test
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestIconsDir(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetIcons(templatebox.Icons{
+		Dir: fstest.MapFS{
+			"trash.svg": {Data: []byte(`<?xml version="1.0"?>
+<!-- trash icon -->
+<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24" class="outline"><path d="M3 6h18"/></svg>
+`)},
+			"solid/dot.svg": {Data: []byte(`<svg viewBox="0 0 8 8" aria-hidden="false"/>`)},
+		},
+		Class: "icon",
+	})
+
+	err = box.AddTemplateRaw("icons", templatebox.TemplateSet{
+		Templates: []string{`{{ icon "trash" "size-4" }}|{{ icon "solid/dot" .Class }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	got, err := box.RenderString("icons", map[string]string{"Class": `"><script>`})
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	expected := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24" class="outline icon size-4" aria-hidden="true"><path d="M3 6h18"/></svg>|` +
+		`<svg viewBox="0 0 8 8" aria-hidden="false" class="icon &#34;&gt;&lt;script&gt;"/>`
+	if got != expected {
+		t.Fatalf("RenderString returned %s, expected %s", got, expected)
+	}
+
+	err = box.AddTemplateRaw("outside", templatebox.TemplateSet{
+		Templates: []string{`{{ icon "../secret" }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	if _, err := box.RenderString("outside", nil); !errors.Is(err, templatebox.ErrInvalidPath) {
+		t.Fatalf("RenderString returned %v, expected ErrInvalidPath", err)
+	}
+}
+
+func TestIconsSprite(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetAssets(templatebox.Assets{
+		BaseURL:  "https://cdn.example.com",
+		Manifest: map[string]string{"icons.svg": "icons.3f2a1c.svg"},
+	})
+	box.SetIcons(templatebox.Icons{SpriteURL: "icons.svg"})
+
+	err = box.AddTemplateRaw("icon", templatebox.TemplateSet{
+		Templates: []string{`<button>{{ icon "trash" "size-4" "text-red" }}</button>`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	got, err := box.RenderString("icon", nil)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	expected := `<button><svg class="size-4 text-red" aria-hidden="true"><use href="https://cdn.example.com/icons.3f2a1c.svg#trash"></use></svg></button>`
+	if got != expected {
+		t.Fatalf("RenderString returned %s, expected %s", got, expected)
+	}
+}
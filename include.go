@@ -0,0 +1,62 @@
+package templatebox
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+// includeRoot is the fs.FS read by the readFile template function and the
+// patterns of the files it may read.
+type includeRoot struct {
+	fsys     fs.FS
+	patterns []string
+}
+
+// SetIncludeRoot sets the fs.FS read by the readFile template function,
+// which includes the literal content of a file, such as license text or a
+// generated fragment, without registering it as a template:
+//
+//	{{ readFile "snippets/footer.txt" }}
+//
+// Use os.DirFS for a directory on disk, which follows symbolic links, so
+// keep links out of the directory. Paths are relative to the root and
+// cannot refer outside it. If patterns are given, in the syntax of
+// path.Match, only matching paths can be read, for example "snippets/*".
+// The content is escaped like any other string in html/template.
+func (b *Box) SetIncludeRoot(fsys fs.FS, patterns ...string) {
+	b.include = &includeRoot{fsys: fsys, patterns: patterns}
+}
+
+// readInclude implements the readFile template function.
+func (b *Box) readInclude(name string) (string, error) {
+	if b.include == nil {
+		return "", fmt.Errorf("readFile %s called but no include root is set", name)
+	}
+	if !fs.ValidPath(name) || name == "." {
+		return "", fmt.Errorf("readFile: %w: %s is outside the include root", ErrInvalidPath, name)
+	}
+	if !b.include.allowed(name) {
+		return "", fmt.Errorf("readFile: %w: %s does not match the allowed patterns", ErrInvalidPath, name)
+	}
+
+	text, err := fs.ReadFile(b.include.fsys, name)
+	if err != nil {
+		return "", fmt.Errorf("readFile: %w", err)
+	}
+	return string(text), nil
+}
+
+// allowed reports whether name matches one of the patterns, or whether no
+// patterns are set.
+func (r *includeRoot) allowed(name string) bool {
+	if len(r.patterns) == 0 {
+		return true
+	}
+	for _, p := range r.patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package templatebox_test
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestReadFile(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetIncludeRoot(fstest.MapFS{
+		"snippets/footer.txt": {Data: []byte("© 2024 <Example>")},
+		"private/key.pem":     {Data: []byte("secret")},
+	}, "snippets/*")
+
+	templates := map[string]string{
+		"footer":  `<footer>{{ readFile "snippets/footer.txt" }}</footer>`,
+		"private": `{{ readFile "private/key.pem" }}`,
+		"outside": `{{ readFile "../templatebox.go" }}`,
+	}
+	for name, text := range templates {
+		if err := box.AddTemplateRaw(name, templatebox.TemplateSet{Templates: []string{text}}); err != nil {
+			t.Fatalf("AddTemplateRaw failed: %v", err)
+		}
+	}
+
+	got, err := box.RenderString("footer", nil)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	expected := "<footer>© 2024 &lt;Example&gt;</footer>"
+	if got != expected {
+		t.Fatalf("RenderString returned %s, expected %s", got, expected)
+	}
+
+	for _, name := range []string{"private", "outside"} {
+		if _, err := box.RenderString(name, nil); !errors.Is(err, templatebox.ErrInvalidPath) {
+			t.Fatalf("RenderString of %s returned %v, expected ErrInvalidPath", name, err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package templatebox
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// buildManifestFile is the file in the output directory recording the
+// fingerprint of every page of an incremental build.
+const buildManifestFile = ".templatebox-build.json"
+
+// buildManifest maps each output file of an incremental build to the
+// fingerprint of the template and data it was rendered from.
+type buildManifest map[string]string
+
+// readBuildManifest reads the manifest of the previous incremental build
+// into dir. A missing or unreadable manifest gives an empty one, so that
+// every page is rendered.
+func readBuildManifest(dir string) buildManifest {
+	m := make(buildManifest)
+	b, err := os.ReadFile(filepath.Join(dir, buildManifestFile))
+	if err != nil {
+		return m
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return make(buildManifest)
+	}
+	return m
+}
+
+// writeBuildManifest writes the fingerprints of the output files to dir.
+func writeBuildManifest(dir string, m buildManifest) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode build manifest: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("write build manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, buildManifestFile), b, 0644); err != nil {
+		return fmt.Errorf("write build manifest: %w", err)
+	}
+	return nil
+}
+
+// unchanged returns the output of the file in dir if it was written by the
+// previous build with the same fingerprint and still exists.
+func (m buildManifest) unchanged(dir, file, key string) ([]byte, bool) {
+	if key == "" || m[file] != key {
+		return nil, false
+	}
+	out, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// pageKey returns the fingerprint of the sources of the named template and
+// the data a page is rendered with, or the empty string if the template
+// does not exist.
+func (b *Box) pageKey(name string, data any) string {
+	h, ok := b.Hash(name)
+	if !ok {
+		return ""
+	}
+	sum, _, _ := hashData(data)
+	return h.Sum + ":" + sum
+}
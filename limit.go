@@ -0,0 +1,36 @@
+package templatebox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrTooManyRenders is returned, wrapped, when Config.RejectWhenBusy is set
+// and Config.MaxConcurrentRenders renders are already in progress.
+var ErrTooManyRenders = errors.New("templatebox: too many concurrent renders")
+
+// acquireRender takes a render slot, returning a function to release it.
+// Without Config.MaxConcurrentRenders it returns immediately.
+func (b *Box) acquireRender(ctx context.Context, name string) (func(), error) {
+	if b.renderSlots == nil {
+		return func() {}, nil
+	}
+	release := func() { <-b.renderSlots }
+
+	if b.cfg.RejectWhenBusy {
+		select {
+		case b.renderSlots <- struct{}{}:
+			return release, nil
+		default:
+			return nil, fmt.Errorf("%w: rendering %s", ErrTooManyRenders, name)
+		}
+	}
+
+	select {
+	case b.renderSlots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting to render %s: %w", name, ctx.Err())
+	}
+}
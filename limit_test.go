@@ -0,0 +1,76 @@
+package templatebox_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+// newBlockingBox returns a Box whose "slow" template blocks until release
+// is closed, after signalling on started.
+func newBlockingBox(t *testing.T, cfg *templatebox.Config) (box *templatebox.Box, started, release chan struct{}) {
+	t.Helper()
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", cfg)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	started, release = make(chan struct{}), make(chan struct{})
+	err = box.AddTemplateRaw("slow", templatebox.TemplateSet{
+		Templates: []string{`{{ wait }}`},
+		FuncMap: templatebox.FuncMap{"wait": func() string {
+			started <- struct{}{}
+			<-release
+			return ""
+		}},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	return box, started, release
+}
+
+func TestMaxConcurrentRendersReject(t *testing.T) {
+	box, started, release := newBlockingBox(t, &templatebox.Config{
+		MaxConcurrentRenders: 1,
+		RejectWhenBusy:       true,
+	})
+
+	done := make(chan error)
+	go func() { done <- box.RenderHTML(io.Discard, "slow", nil) }()
+	<-started
+
+	err := box.RenderHTML(io.Discard, "slow", nil)
+	if !errors.Is(err, templatebox.ErrTooManyRenders) {
+		t.Fatalf("RenderHTML returned %v, expected ErrTooManyRenders", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+}
+
+func TestMaxConcurrentRendersWait(t *testing.T) {
+	box, started, release := newBlockingBox(t, &templatebox.Config{MaxConcurrentRenders: 1})
+
+	done := make(chan error)
+	go func() { done <- box.RenderHTML(io.Discard, "slow", nil) }()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := box.RenderHTMLContext(ctx, io.Discard, "slow", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("RenderHTMLContext returned %v, expected context.DeadlineExceeded", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package templatebox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// linkAttrs maps each element to the attribute holding the URL it links
+// to.
+var linkAttrs = map[string]string{
+	"a":      "href",
+	"link":   "href",
+	"area":   "href",
+	"img":    "src",
+	"script": "src",
+	"source": "src",
+	"iframe": "src",
+	"video":  "src",
+	"audio":  "src",
+}
+
+// extractLinks returns the URLs linked to by the HTML in output, in order.
+func extractLinks(output []byte) []string {
+	var links []string
+	scanTokens(output, func(tok htmlToken, line, col int) {
+		if tok.Type != startTagToken && tok.Type != selfClosingTagToken {
+			return
+		}
+		key, ok := linkAttrs[tok.Data]
+		if !ok {
+			return
+		}
+		if v, ok := attr(tok, key); ok && strings.TrimSpace(v) != "" {
+			links = append(links, strings.TrimSpace(v))
+		}
+	})
+	return links
+}
+
+// checkLinks returns the links in the outputs of the rendered pages, keyed
+// by page path, that do not resolve to a file in the output directory or, with
+// CheckExternalLinks, to a successful HTTP response.
+func checkLinks(ctx context.Context, opts SSGOptions, outputs map[string][]byte) []BrokenLink {
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var broken []BrokenLink
+	external := make(map[string]string) // URL to failure reason
+	for _, page := range sortedKeys(outputs) {
+		for _, link := range extractLinks(outputs[page]) {
+			u, err := url.Parse(link)
+			if err != nil {
+				broken = append(broken, BrokenLink{Page: page, URL: link, Reason: "invalid URL"})
+				continue
+			}
+
+			var reason string
+			switch {
+			case u.Scheme == "http" || u.Scheme == "https" || (u.Scheme == "" && u.Host != ""):
+				if !opts.CheckExternalLinks {
+					continue
+				}
+				if u.Scheme == "" {
+					u.Scheme = "https"
+				}
+				r, ok := external[u.String()]
+				if !ok {
+					r = checkExternalLink(ctx, client, u.String())
+					external[u.String()] = r
+				}
+				reason = r
+			case u.Scheme != "" || u.Path == "":
+				// mailto:, tel:, data: and fragment or query only links
+				continue
+			default:
+				reason = checkInternalLink(opts.Dir, page, u)
+			}
+			if reason != "" {
+				broken = append(broken, BrokenLink{Page: page, URL: link, Reason: reason})
+			}
+		}
+	}
+	return broken
+}
+
+// checkInternalLink returns why the link u of the page served at the URL
+// path page does not resolve to a file in dir, or the empty string if it
+// does. A link to a directory resolves to its index.html.
+func checkInternalLink(dir, page string, u *url.URL) string {
+	target := (&url.URL{Path: page}).ResolveReference(&url.URL{Path: u.Path}).Path
+	file := strings.TrimPrefix(path.Clean(target), "/")
+	if strings.HasSuffix(target, "/") || file == "" {
+		file = path.Join(file, "index.html")
+	}
+
+	filename := filepath.Join(dir, filepath.FromSlash(file))
+	fi, err := os.Stat(filename)
+	if err == nil && fi.IsDir() {
+		_, err = os.Stat(filepath.Join(filename, "index.html"))
+	}
+	if err != nil {
+		return fmt.Sprintf("%s not found", target)
+	}
+	return ""
+}
+
+// checkExternalLink sends a HEAD request, or a GET request to servers that
+// do not allow HEAD, to the URL and returns why it failed, or the empty
+// string if it succeeded.
+func checkExternalLink(ctx context.Context, client *http.Client, u string) string {
+	status := 0
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		req, err := http.NewRequestWithContext(ctx, method, u, nil)
+		if err != nil {
+			return err.Error()
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err.Error()
+		}
+		resp.Body.Close()
+
+		status = resp.StatusCode
+		if status != http.StatusMethodNotAllowed {
+			break
+		}
+	}
+	if status >= 400 {
+		return fmt.Sprintf("%d %s", status, http.StatusText(status))
+	}
+	return ""
+}
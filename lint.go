@@ -0,0 +1,231 @@
+package templatebox
+
+import (
+	"fmt"
+	"sort"
+	"text/template/parse"
+)
+
+// Lint rules reported in LintIssue.Rule.
+const (
+	// RuleUnusedVariable reports a variable declared with {{ $x := ... }}
+	// that is never used.
+	RuleUnusedVariable = "unused-variable"
+
+	// RuleShadowedVariable reports a variable, typically a range
+	// variable, declared with the same name as a variable of an enclosing
+	// scope, hiding the outer variable for the rest of the block.
+	RuleShadowedVariable = "shadowed-variable"
+)
+
+// LintIssue is a problem found in a template by Lint.
+type LintIssue struct {
+	// Template is the name of the registered template the issue was
+	// found in. Issues in files shared by several templates are reported
+	// once, against the first template in name order.
+	Template string
+
+	// Location is the file or template name, line and column of the
+	// issue, for example "a.html:3:12".
+	Location string
+
+	Rule    string
+	Message string
+}
+
+// String returns the issue in the conventional location: message form.
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s: %s (%s)", i.Location, i.Message, i.Rule)
+}
+
+// Lint parses the source of every registered template and reports likely
+// mistakes that html/template accepts silently. The issues are sorted by
+// template name and then by position within each source. Templates parsed
+// by an Engine other than html/template are skipped.
+func (b *Box) Lint() ([]LintIssue, error) {
+	var issues []LintIssue
+	seen := make(map[string]bool)
+	for _, name := range b.htmlNames() {
+		srcs, err := b.templateSources(name)
+		if err != nil {
+			return nil, fmt.Errorf("lint template %s: %w", name, err)
+		}
+		trees, err := parseTrees(srcs)
+		if err != nil {
+			return nil, fmt.Errorf("lint template %s: %w", name, err)
+		}
+
+		var found []LintIssue
+		for _, tree := range sortedTrees(trees) {
+			found = append(found, lintTree(tree)...)
+		}
+
+		for _, issue := range found {
+			key := issue.Location + "\x00" + issue.Rule + "\x00" + issue.Message
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			issue.Template = name
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// lintVar is a variable declared in a template.
+type lintVar struct {
+	node *parse.VariableNode
+	used bool
+}
+
+// linter walks a single parse tree tracking variable scopes.
+type linter struct {
+	tree   *parse.Tree
+	scopes []map[string]*lintVar
+	issues []lintIssue
+}
+
+// lintIssue is a LintIssue with the position it was found at.
+type lintIssue struct {
+	LintIssue
+	pos parse.Pos
+}
+
+// lintTree returns the issues found in tree.
+func lintTree(tree *parse.Tree) []LintIssue {
+	l := &linter{tree: tree}
+	l.push()
+	l.walkList(tree.Root)
+	l.pop()
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		return l.issues[i].pos < l.issues[j].pos
+	})
+	issues := make([]LintIssue, len(l.issues))
+	for i, issue := range l.issues {
+		issues[i] = issue.LintIssue
+	}
+	return issues
+}
+
+func (l *linter) report(n parse.Node, rule, msg string) {
+	loc, _ := l.tree.ErrorContext(n)
+	l.issues = append(l.issues, lintIssue{
+		LintIssue: LintIssue{Location: loc, Rule: rule, Message: msg},
+		pos:       n.Position(),
+	})
+}
+
+func (l *linter) push() {
+	l.scopes = append(l.scopes, make(map[string]*lintVar))
+}
+
+// pop closes the innermost scope and reports its unused variables.
+func (l *linter) pop() {
+	scope := l.scopes[len(l.scopes)-1]
+	l.scopes = l.scopes[:len(l.scopes)-1]
+
+	for name, v := range scope {
+		if !v.used {
+			l.report(v.node, RuleUnusedVariable, fmt.Sprintf("variable %s is declared but never used", name))
+		}
+	}
+}
+
+// declare adds the variables declared by pipe to the innermost scope. The
+// first of two range variables is exempt from the unused check because it
+// cannot be omitted when the second is needed.
+func (l *linter) declare(pipe *parse.PipeNode, isRange bool) {
+	if pipe == nil || pipe.IsAssign {
+		return
+	}
+
+	for i, v := range pipe.Decl {
+		name := v.Ident[0]
+		for _, outer := range l.scopes {
+			if _, ok := outer[name]; ok {
+				kind := "variable"
+				if isRange {
+					kind = "range variable"
+				}
+				l.report(v, RuleShadowedVariable, fmt.Sprintf("%s %s shadows a variable of the same name", kind, name))
+				break
+			}
+		}
+		exempt := isRange && len(pipe.Decl) == 2 && i == 0
+		l.scopes[len(l.scopes)-1][name] = &lintVar{node: v, used: exempt}
+	}
+}
+
+// use marks the variables referenced by the commands of pipe as used.
+// Assignments with = also count as a use of the assigned variable.
+func (l *linter) use(pipe *parse.PipeNode) {
+	if pipe == nil {
+		return
+	}
+	if pipe.IsAssign {
+		for _, v := range pipe.Decl {
+			l.markUsed(v.Ident[0])
+		}
+	}
+	for _, cmd := range pipe.Cmds {
+		walkNodes(cmd, func(n parse.Node) {
+			if v, ok := n.(*parse.VariableNode); ok {
+				l.markUsed(v.Ident[0])
+			}
+		})
+	}
+}
+
+// markUsed marks the innermost variable with the given name as used.
+func (l *linter) markUsed(name string) {
+	for i := len(l.scopes) - 1; i >= 0; i-- {
+		if v, ok := l.scopes[i][name]; ok {
+			v.used = true
+			return
+		}
+	}
+}
+
+func (l *linter) walkList(list *parse.ListNode) {
+	if list == nil {
+		return
+	}
+	for _, n := range list.Nodes {
+		l.walk(n)
+	}
+}
+
+func (l *linter) walk(n parse.Node) {
+	switch n := n.(type) {
+	case *parse.ActionNode:
+		l.use(n.Pipe)
+		l.declare(n.Pipe, false)
+	case *parse.TemplateNode:
+		l.use(n.Pipe)
+	case *parse.IfNode:
+		l.walkBranch(&n.BranchNode, false)
+	case *parse.WithNode:
+		l.walkBranch(&n.BranchNode, false)
+	case *parse.RangeNode:
+		l.walkBranch(&n.BranchNode, true)
+	}
+}
+
+// walkBranch walks an if, with or range. Variables declared in its pipeline
+// or its lists go out of scope at its end.
+func (l *linter) walkBranch(n *parse.BranchNode, isRange bool) {
+	l.use(n.Pipe)
+
+	l.push()
+	l.declare(n.Pipe, isRange)
+	l.walkList(n.List)
+	l.pop()
+
+	if n.ElseList != nil {
+		l.push()
+		l.walkList(n.ElseList)
+		l.pop()
+	}
+}
@@ -0,0 +1,45 @@
+package templatebox_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestLintVariables(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("vars", templatebox.TemplateSet{
+		Templates: []string{
+			`{{ $unused := .A }}{{ $used := .B }}{{ $used }}` + "\n" +
+				`{{ range $i, $v := .Items }}{{ $v }}{{ end }}` + "\n" +
+				`{{ range $item := .Items }}{{ range $item := $item.Sub }}{{ $item }}{{ end }}{{ end }}` + "\n" +
+				`{{ $n := 0 }}{{ range .Items }}{{ $n = 1 }}{{ end }}`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	issues, err := box.Lint()
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.Template+" "+issue.String())
+	}
+
+	expected := []string{
+		"vars vars:1:3: variable $unused is declared but never used (unused-variable)",
+		"vars vars:3:36: range variable $item shadows a variable of the same name (shadowed-variable)",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("Lint returned\n%s\nexpected\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+}
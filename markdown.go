@@ -0,0 +1,60 @@
+package templatebox
+
+import (
+	"fmt"
+	"html/template"
+)
+
+// MarkdownConverter converts markdown source to HTML.
+type MarkdownConverter interface {
+	Convert(markdown string) (string, error)
+}
+
+// MarkdownConverterFunc is an adapter to allow the use of an ordinary
+// function as a MarkdownConverter.
+type MarkdownConverterFunc func(markdown string) (string, error)
+
+// Convert calls f(markdown).
+func (f MarkdownConverterFunc) Convert(markdown string) (string, error) {
+	return f(markdown)
+}
+
+// SetMarkdownConverter sets the MarkdownConverter used by the markdown
+// template function.
+func (b *Box) SetMarkdownConverter(c MarkdownConverter) {
+	b.markdown = c
+}
+
+// markdownHTML implements the markdown template function. The markdown is
+// converted to HTML and the result is always passed through the Sanitizer
+// before being marked as safe, so both a MarkdownConverter and a Sanitizer
+// must be set on the Box. Shortcodes are rendered before the conversion
+// and inserted after sanitizing, headings are given ids for linking, and
+// fenced code blocks are highlighted if a Highlighter is set.
+func (b *Box) markdownHTML(s string) (template.HTML, error) {
+	out, _, err := b.convertMarkdown(s)
+	return out, err
+}
+
+// convertMarkdown implements markdownHTML and Markdown, returning the
+// headings of the document as well as the HTML.
+func (b *Box) convertMarkdown(s string) (template.HTML, []TOCEntry, error) {
+	if b.markdown == nil {
+		return "", nil, fmt.Errorf("markdown called but no MarkdownConverter is set")
+	}
+	if b.sanitizer == nil {
+		return "", nil, fmt.Errorf("markdown called but no Sanitizer is set")
+	}
+
+	s, shortcodes := b.extractShortcodes(s)
+
+	out, err := b.markdown.Convert(s)
+	if err != nil {
+		return "", nil, fmt.Errorf("markdown conversion failed: %w", err)
+	}
+	out, toc := addHeadingIDs(b.sanitizer.Sanitize(out))
+	if out, err = b.highlightCodeBlocks(out); err != nil {
+		return "", nil, err
+	}
+	return template.HTML(insertShortcodes(out, shortcodes)), toc, nil
+}
@@ -0,0 +1,104 @@
+package templatebox
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+)
+
+// Meta describes the document metadata rendered by the metaTags template
+// function: the title, description and canonical link plus the matching
+// Open Graph and Twitter card tags.
+type Meta struct {
+	Title       string
+	Description string
+	Canonical   string
+	Image       string
+	Type        string // Open Graph type; defaults to "website"
+	SiteName    string
+	TwitterCard string // defaults to "summary_large_image" if Image is set, otherwise "summary"
+	TwitterSite string
+}
+
+// merge returns m with any empty field filled in from defaults.
+func (m Meta) merge(defaults Meta) Meta {
+	fill := func(v *string, d string) {
+		if *v == "" {
+			*v = d
+		}
+	}
+	fill(&m.Title, defaults.Title)
+	fill(&m.Description, defaults.Description)
+	fill(&m.Canonical, defaults.Canonical)
+	fill(&m.Image, defaults.Image)
+	fill(&m.Type, defaults.Type)
+	fill(&m.SiteName, defaults.SiteName)
+	fill(&m.TwitterCard, defaults.TwitterCard)
+	fill(&m.TwitterSite, defaults.TwitterSite)
+	return m
+}
+
+// metaTagsFunc returns the metaTags template function for a template whose
+// FileSet or TemplateSet declared the given defaults.
+func metaTagsFunc(defaults *Meta) func(any) (template.HTML, error) {
+	return func(v any) (template.HTML, error) {
+		var m Meta
+		switch mv := v.(type) {
+		case nil:
+		case Meta:
+			m = mv
+		case *Meta:
+			if mv != nil {
+				m = *mv
+			}
+		default:
+			return "", fmt.Errorf("metaTags expects a Meta, got %T", v)
+		}
+		if defaults != nil {
+			m = m.merge(*defaults)
+		}
+		return renderMetaTags(m), nil
+	}
+}
+
+// renderMetaTags returns the HTML for m. Tags for empty fields are omitted.
+func renderMetaTags(m Meta) template.HTML {
+	if m.Type == "" {
+		m.Type = "website"
+	}
+	if m.TwitterCard == "" {
+		m.TwitterCard = "summary"
+		if m.Image != "" {
+			m.TwitterCard = "summary_large_image"
+		}
+	}
+
+	var sb strings.Builder
+	tag := func(attr, key, value string) {
+		if value == "" {
+			return
+		}
+		fmt.Fprintf(&sb, "<meta %s=\"%s\" content=\"%s\">\n", attr, key, html.EscapeString(value))
+	}
+
+	if m.Title != "" {
+		fmt.Fprintf(&sb, "<title>%s</title>\n", html.EscapeString(m.Title))
+	}
+	tag("name", "description", m.Description)
+	if m.Canonical != "" {
+		fmt.Fprintf(&sb, "<link rel=\"canonical\" href=\"%s\">\n", html.EscapeString(m.Canonical))
+	}
+	tag("property", "og:title", m.Title)
+	tag("property", "og:description", m.Description)
+	tag("property", "og:url", m.Canonical)
+	tag("property", "og:image", m.Image)
+	tag("property", "og:type", m.Type)
+	tag("property", "og:site_name", m.SiteName)
+	tag("name", "twitter:card", m.TwitterCard)
+	tag("name", "twitter:site", m.TwitterSite)
+	tag("name", "twitter:title", m.Title)
+	tag("name", "twitter:description", m.Description)
+	tag("name", "twitter:image", m.Image)
+	return template.HTML(sb.String())
+}
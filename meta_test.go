@@ -0,0 +1,55 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestMetaTagsWithDefaults(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("page", templatebox.TemplateSet{
+		Templates: []string{`<head>{{ metaTags .Meta }}</head>`},
+		Meta: &templatebox.Meta{
+			Title:       "Default title",
+			SiteName:    "Example",
+			Description: "Default description",
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	data := map[string]any{
+		"Meta": templatebox.Meta{
+			Title:     "Tom & Jerry",
+			Canonical: "https://example.com/tom",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "page", data); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	expected := `<head><title>Tom &amp; Jerry</title>
+<meta name="description" content="Default description">
+<link rel="canonical" href="https://example.com/tom">
+<meta property="og:title" content="Tom &amp; Jerry">
+<meta property="og:description" content="Default description">
+<meta property="og:url" content="https://example.com/tom">
+<meta property="og:type" content="website">
+<meta property="og:site_name" content="Example">
+<meta name="twitter:card" content="summary">
+<meta name="twitter:title" content="Tom &amp; Jerry">
+<meta name="twitter:description" content="Default description">
+</head>`
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+}
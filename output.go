@@ -0,0 +1,116 @@
+package templatebox
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+)
+
+// Output describes the kind of content a registered template produces. It
+// determines the type returned by RenderTyped.
+type Output int
+
+const (
+	// OutputPage is a complete HTML document. This is the default.
+	OutputPage Output = iota
+
+	// OutputFragment is a fragment of HTML intended to be embedded in
+	// another template.
+	OutputFragment
+
+	// OutputAttributes is one or more attribute name and value pairs, such
+	// as `class="active" aria-current="page"`, intended to be embedded
+	// inside an element tag of another template.
+	OutputAttributes
+)
+
+// String returns the name of the Output kind.
+func (o Output) String() string {
+	switch o {
+	case OutputPage:
+		return "page"
+	case OutputFragment:
+		return "fragment"
+	case OutputAttributes:
+		return "attributes"
+	}
+	return fmt.Sprintf("Output(%d)", int(o))
+}
+
+// RenderString renders the named template with the given data and returns
+// the output as a string.
+func (b *Box) RenderString(name string, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := b.RenderHTML(&buf, name, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RenderHTMLMulti renders the named template once and writes the output to
+// each of the writers, such as an http.ResponseWriter and a file keeping a
+// copy of what was sent. Nothing is written if the render fails. A failing
+// writer does not stop the output being written to the others; the
+// returned error lists every writer that failed.
+func (b *Box) RenderHTMLMulti(ws []io.Writer, name string, data any) error {
+	var buf bytes.Buffer
+	if err := b.RenderHTML(&buf, name, data); err != nil {
+		return err
+	}
+
+	var errs []error
+	for i, w := range ws {
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			errs = append(errs, fmt.Errorf("write %s output to writer %d: %w", name, i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RenderTyped renders the named template with the given data and returns the
+// output wrapped in the type matching the Output declared when the template
+// was registered: a string for OutputPage, template.HTML for OutputFragment
+// and template.HTMLAttr for OutputAttributes. The typed values can be passed
+// as data to other templates without being escaped a second time.
+func (b *Box) RenderTyped(name string, data any) (any, error) {
+	s, err := b.RenderString(name, data)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.RLock()
+	out := b.opts[name].output
+	b.mu.RUnlock()
+
+	switch out {
+	case OutputFragment:
+		return template.HTML(s), nil
+	case OutputAttributes:
+		return template.HTMLAttr(s), nil
+	}
+	return s, nil
+}
+
+// RenderHTMLInto renders the named template with the given data and returns
+// the output as template.HTML, ready to be passed as data into the render of
+// another template. It is intended for composing separately registered
+// templates, such as rendering a list of cards and passing the result into a
+// page layout. The named template is trusted to produce well-formed HTML
+// regardless of its declared Output.
+func (b *Box) RenderHTMLInto(name string, data any) (template.HTML, error) {
+	s, err := b.RenderString(name, data)
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(s), nil
+}
+
+// OutputOf returns the Output declared for the named template.
+func (b *Box) OutputOf(name string) (Output, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	opts, ok := b.opts[name]
+	return opts.output, ok
+}
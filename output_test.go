@@ -0,0 +1,146 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"io"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestRenderTypedFragment(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("badge", templatebox.TemplateSet{
+		Templates: []string{`<span class="badge">{{ . }}</span>`},
+		Output:    templatebox.OutputFragment,
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("active", templatebox.TemplateSet{
+		Templates: []string{`class="active"`},
+		Output:    templatebox.OutputAttributes,
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("page", templatebox.TemplateSet{
+		Templates: []string{`<p {{ .Attrs }}>{{ .Badge }}</p>`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	badge, err := box.RenderTyped("badge", "a&b")
+	if err != nil {
+		t.Fatalf("RenderTyped failed: %v", err)
+	}
+	if _, ok := badge.(template.HTML); !ok {
+		t.Fatalf("RenderTyped returned %T, expected template.HTML", badge)
+	}
+
+	attrs, err := box.RenderTyped("active", nil)
+	if err != nil {
+		t.Fatalf("RenderTyped failed: %v", err)
+	}
+	if _, ok := attrs.(template.HTMLAttr); !ok {
+		t.Fatalf("RenderTyped returned %T, expected template.HTMLAttr", attrs)
+	}
+
+	var buf bytes.Buffer
+	err = box.RenderHTML(&buf, "page", map[string]any{"Badge": badge, "Attrs": attrs})
+	if err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	expected := `<p class="active"><span class="badge">a&amp;b</span></p>`
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+
+	page, err := box.RenderTyped("page", map[string]any{"Badge": badge, "Attrs": attrs})
+	if err != nil {
+		t.Fatalf("RenderTyped failed: %v", err)
+	}
+	if _, ok := page.(string); !ok {
+		t.Fatalf("RenderTyped returned %T, expected string", page)
+	}
+}
+
+func TestRenderHTMLInto(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("card", templatebox.TemplateSet{
+		Templates: []string{`<div class="card">{{ .Title }}</div>`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	err = box.AddTemplate("a", templatebox.FileSet{
+		Filenames: []string{"layout.html", "d.html"},
+		FuncMap: templatebox.FuncMap{
+			"uppr": func(v any) any { return v },
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	card, err := box.RenderHTMLInto("card", map[string]string{"Title": "<Hello>"})
+	if err != nil {
+		t.Fatalf("RenderHTMLInto failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "a", map[string]any{"Name": card}); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	expected := `<h1><div class="card">&lt;Hello&gt;</div></h1>`
+	if !bytes.Contains(buf.Bytes(), []byte(expected)) {
+		t.Fatalf("RenderHTML returned %s, expected it to contain %s", buf.String(), expected)
+	}
+}
+
+// failingWriter is an io.Writer that always fails.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestRenderHTMLMulti(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("page", templatebox.TemplateSet{
+		Templates: []string{`<p>{{ . }}</p>`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var a, b bytes.Buffer
+	err = box.RenderHTMLMulti([]io.Writer{&a, failingWriter{}, &b}, "page", "hello")
+	if err == nil {
+		t.Fatalf("RenderHTMLMulti succeeded, expected the failing writer's error")
+	}
+
+	expected := "<p>hello</p>"
+	if a.String() != expected || b.String() != expected {
+		t.Fatalf("RenderHTMLMulti wrote %s and %s, expected %s", a.String(), b.String(), expected)
+	}
+}
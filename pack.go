@@ -0,0 +1,72 @@
+package templatebox
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// TemplatePack is a set of templates that a Go library can export for
+// applications to mount into their Box, such as the pages of an
+// authentication package:
+//
+//	//go:embed templates
+//	var files embed.FS
+//
+//	var Pack = templatebox.TemplatePack{
+//		FS: files,
+//		Templates: map[string]templatebox.FileSet{
+//			"login": {Filenames: []string{"templates/layout.html", "templates/login.html"}},
+//		},
+//	}
+type TemplatePack struct {
+	// FS holds the template files of the pack.
+	FS fs.FS
+
+	// Templates maps template names, relative to the mount prefix, to
+	// their FileSets. Filenames are relative to FS.
+	Templates map[string]FileSet
+
+	// FuncMap holds the default functions of the pack. It is added to
+	// every template of the pack before the FuncMap of its FileSet.
+	FuncMap FuncMap
+}
+
+// Mount adds the templates of the pack to the Box, naming each template
+// with the prefix followed by its name in the pack, so with the prefix
+// "auth/" the pack template "login" is rendered as "auth/login". The pack
+// FS is registered with AddSource under the prefix without its trailing
+// slash, so applications can also use the pack files in their own FileSets,
+// for example "auth:templates/layout.html".
+func (b *Box) Mount(prefix string, p TemplatePack) error {
+	source := strings.TrimSuffix(prefix, "/")
+	if err := b.AddSource(source, p.FS); err != nil {
+		return fmt.Errorf("mount %s failed: %w", prefix, err)
+	}
+
+	for _, name := range sortedKeys(p.Templates) {
+		s := p.Templates[name]
+
+		filenames := make([]string, len(s.Filenames))
+		for i, f := range s.Filenames {
+			filenames[i] = source + ":" + f
+		}
+		s.Filenames = filenames
+
+		if p.FuncMap != nil {
+			funcs := make(FuncMap, len(p.FuncMap)+len(s.FuncMap))
+			for k, v := range p.FuncMap {
+				funcs[k] = v
+			}
+			for k, v := range s.FuncMap {
+				funcs[k] = v
+			}
+			s.FuncMap = funcs
+		}
+
+		if err := b.AddTemplate(prefix+name, s); err != nil {
+			return fmt.Errorf("mount %s failed: template %s: %w", prefix, name, err)
+		}
+	}
+	return nil
+}
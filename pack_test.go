@@ -0,0 +1,41 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestMount(t *testing.T) {
+	pack := templatebox.TemplatePack{
+		FS: fstest.MapFS{
+			"templates/layout.html": {Data: []byte(`<form>{{ template "content" . }}</form>`)},
+			"templates/login.html":  {Data: []byte(`{{ define "content" }}{{ label "Email" }}{{ end }}`)},
+		},
+		Templates: map[string]templatebox.FileSet{
+			"login": {Filenames: []string{"templates/layout.html", "templates/login.html"}},
+		},
+		FuncMap: templatebox.FuncMap{
+			"label": func(s string) string { return s + ":" },
+		},
+	}
+
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	if err := box.Mount("auth/", pack); err != nil {
+		t.Fatalf("Mount failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "auth/login", nil); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+	expected := "<form>Email:</form>"
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+}
@@ -0,0 +1,102 @@
+package templatebox
+
+import (
+	"sort"
+	"text/template/parse"
+)
+
+// parseTrees parses the sources into a set of parse trees keyed by template
+// name, without executing or escaping them. Function names are not checked,
+// so the trees can be analysed without the FuncMaps the templates were
+// added with, and comments are kept.
+//
+// As with template.Parse, a later definition of a template replaces an
+// earlier one unless the later definition is empty.
+func parseTrees(srcs []Source) (map[string]*parse.Tree, error) {
+	trees := make(map[string]*parse.Tree)
+	for _, src := range srcs {
+		set := make(map[string]*parse.Tree)
+		t := parse.New(src.Name)
+		t.Mode = parse.SkipFuncCheck | parse.ParseComments
+		if _, err := t.Parse(string(src.Text), "", "", set); err != nil {
+			return nil, err
+		}
+		for name, tree := range set {
+			if old, ok := trees[name]; ok && parse.IsEmptyTree(tree.Root) && old.Root != nil {
+				continue
+			}
+			trees[name] = tree
+		}
+	}
+	return trees, nil
+}
+
+// sortedTrees returns the trees ordered by template name.
+func sortedTrees(trees map[string]*parse.Tree) []*parse.Tree {
+	names := make([]string, 0, len(trees))
+	for name := range trees {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sorted := make([]*parse.Tree, len(names))
+	for i, name := range names {
+		sorted[i] = trees[name]
+	}
+	return sorted
+}
+
+// walkNodes calls fn for n and every node below it, depth first.
+func walkNodes(n parse.Node, fn func(parse.Node)) {
+	if n == nil {
+		return
+	}
+	fn(n)
+
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			walkNodes(c, fn)
+		}
+	case *parse.ActionNode:
+		walkNodes(n.Pipe, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, v := range n.Decl {
+			walkNodes(v, fn)
+		}
+		for _, c := range n.Cmds {
+			walkNodes(c, fn)
+		}
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			walkNodes(a, fn)
+		}
+	case *parse.ChainNode:
+		walkNodes(n.Node, fn)
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.TemplateNode:
+		walkNodes(n.Pipe, fn)
+	}
+}
+
+// walkBranch walks the pipeline and both lists of an if, range or with.
+func walkBranch(n *parse.BranchNode, fn func(parse.Node)) {
+	walkNodes(n.Pipe, fn)
+	if n.List != nil {
+		walkNodes(n.List, fn)
+	}
+	if n.ElseList != nil {
+		walkNodes(n.ElseList, fn)
+	}
+}
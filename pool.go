@@ -0,0 +1,103 @@
+package templatebox
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// Data is a map of render data taken from the pool of a Box with Box.Data.
+// Handlers building a map of data for every request can use it to reuse the
+// maps rather than allocate new ones:
+//
+//	data := box.Data()
+//	data["Title"] = "Orders"
+//	data["Orders"] = orders
+//	err := box.RenderHTML(w, "orders", data)
+//
+// Once the render it is passed to returns, the Data is cleared and returned
+// to the pool, so it must not be used again, nor kept by a template
+// function. Work the Box does with the data after the render returns, such
+// as revalidating a template with CacheOptions in the background, holds the
+// Data back from the pool until it is done.
+type Data map[string]any
+
+// dataPool holds the cleared maps of Data released by renders, together
+// with the Data handed out by Box.Data and not yet released, counting the
+// renders using each.
+type dataPool struct {
+	pool sync.Pool
+
+	mu   sync.Mutex
+	refs map[unsafe.Pointer]int
+}
+
+// Data returns an empty Data from the pool of the Box.
+func (b *Box) Data() Data {
+	d, _ := b.data.pool.Get().(Data)
+	if d == nil {
+		d = make(Data)
+	}
+	b.data.mu.Lock()
+	if b.data.refs == nil {
+		b.data.refs = make(map[unsafe.Pointer]int)
+	}
+	b.data.refs[dataKey(d)] = 0
+	b.data.mu.Unlock()
+	return d
+}
+
+// ReleaseData returns a Data to the pool without rendering it, such as when
+// a handler fails before the render. It must not be used again.
+func (b *Box) ReleaseData(d Data) {
+	b.data.mu.Lock()
+	_, ok := b.data.refs[dataKey(d)]
+	if ok {
+		delete(b.data.refs, dataKey(d))
+	}
+	b.data.mu.Unlock()
+	if ok {
+		clear(d)
+		b.data.pool.Put(d)
+	}
+}
+
+// dataKey returns the identity of the map d.
+func dataKey(d Data) unsafe.Pointer {
+	return reflect.ValueOf(d).UnsafePointer()
+}
+
+// retainData records that data, if a Data from the pool, is in use by a
+// render, so that a render nested inside it, such as with RenderHTMLInto,
+// does not release it. It reports whether data is a Data from the pool.
+func (b *Box) retainData(data any) bool {
+	d, ok := data.(Data)
+	if !ok || d == nil {
+		return false
+	}
+	b.data.mu.Lock()
+	defer b.data.mu.Unlock()
+	n, ok := b.data.refs[dataKey(d)]
+	if ok {
+		b.data.refs[dataKey(d)] = n + 1
+	}
+	return ok
+}
+
+// releaseData ends a use of data retained with retainData, returning it to
+// the pool when no render is using it.
+func (b *Box) releaseData(data any) {
+	d := data.(Data)
+	b.data.mu.Lock()
+	n := b.data.refs[dataKey(d)] - 1
+	if n > 0 {
+		b.data.refs[dataKey(d)] = n
+		b.data.mu.Unlock()
+		return
+	}
+	delete(b.data.refs, dataKey(d))
+	b.data.mu.Unlock()
+
+	clear(d)
+	b.data.pool.Put(d)
+}
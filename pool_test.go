@@ -0,0 +1,88 @@
+package templatebox_test
+
+import (
+	"html/template"
+	"io"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestData(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetGlobalFuncMap(templatebox.FuncMap{
+		"inner": func(name string, data any) (template.HTML, error) {
+			return box.RenderHTMLInto(name, data)
+		},
+	})
+	err = box.AddTemplateRaw("outer", templatebox.TemplateSet{
+		Templates: []string{`{{ inner "greeting" . }} and {{ .Name }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	err = box.AddTemplateRaw("greeting", templatebox.TemplateSet{
+		Templates: []string{`Hello {{ .Name }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	// the nested render does not release the data of the outer render
+	data := box.Data()
+	data["Name"] = "Ada"
+	got, err := box.RenderString("outer", data)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	if expected := "Hello Ada and Ada"; got != expected {
+		t.Fatalf("RenderString returned %s, expected %s", got, expected)
+	}
+	if len(data) != 0 {
+		t.Fatalf("Data has %d entries after the render, expected it to be cleared", len(data))
+	}
+
+	// a Data not taken from the pool belongs to the caller
+	own := templatebox.Data{"Name": "Grace"}
+	if _, err := box.RenderString("greeting", own); err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	if own["Name"] != "Grace" {
+		t.Fatalf("Data not from the pool was cleared")
+	}
+
+	released := box.Data()
+	released["Name"] = "Alan"
+	box.ReleaseData(released)
+	if len(released) != 0 {
+		t.Fatalf("ReleaseData left %d entries, expected the Data to be cleared", len(released))
+	}
+}
+
+func BenchmarkRenderMapData(b *testing.B) {
+	box := newBenchmarkBox(b)
+
+	b.ReportAllocs()
+	for range b.N {
+		data := map[string]any{"Title": "Orders", "User": "ada", "Count": 3}
+		if err := box.RenderHTML(io.Discard, "a", data); err != nil {
+			b.Fatalf("RenderHTML failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkRenderPooledData(b *testing.B) {
+	box := newBenchmarkBox(b)
+
+	b.ReportAllocs()
+	for range b.N {
+		data := box.Data()
+		data["Title"], data["User"], data["Count"] = "Orders", "ada", 3
+		if err := box.RenderHTML(io.Discard, "a", data); err != nil {
+			b.Fatalf("RenderHTML failed: %v", err)
+		}
+	}
+}
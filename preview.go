@@ -0,0 +1,204 @@
+package templatebox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// maxPlaceholderDepth bounds the recursion of placeholder generation for
+// self-referencing data types.
+const maxPlaceholderDepth = 5
+
+// SetExampleData registers example data for the named template. Example
+// data is used by Preview in place of generated placeholder data, and takes
+// precedence over an example data file.
+func (b *Box) SetExampleData(name string, data any) {
+	b.muPreview.Lock()
+	b.examples[name] = data
+	b.muPreview.Unlock()
+}
+
+// SetDataType registers the type of data the named template expects, given
+// as a value of that type such as PageData{} or (*PageData)(nil). Preview
+// uses it to generate placeholder data when no example data is registered.
+func (b *Box) SetDataType(name string, v any) {
+	b.muPreview.Lock()
+	b.dataTypes[name] = reflect.TypeOf(v)
+	b.muPreview.Unlock()
+}
+
+// Preview renders the named template without any backend state, so
+// designers can view any page. The data used is the example data
+// registered with SetExampleData if any, otherwise the example data file
+// of the template, otherwise the data recorded from the last render in
+// debug mode (see Config.RecordData), otherwise placeholder data generated
+// from the type registered with SetDataType, otherwise nil.
+//
+// The example data file of a template added with AddTemplate sits next to
+// one of its files, named after it with a .data.json or .data.yaml
+// extension, such as hello.data.json for hello.html; YAML needs the
+// adapter/yaml package imported, as with LoadData. The files of the
+// FileSet are searched from the last, usually the page, to the first,
+// usually the layout. Data files are read on every call, so edits show
+// without restarting.
+//
+// As with Warm, previews are not counted by LastRendered and Unrendered,
+// and are not passed to Tee or recorded.
+func (b *Box) Preview(name string) (string, error) {
+	data, err := b.previewData(name)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := b.renderQuiet(context.Background(), &buf, name, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// previewData returns the data Preview renders the named template with.
+func (b *Box) previewData(name string) (any, error) {
+	data, _, err := b.sampleData(name)
+	return data, err
+}
+
+// sampleData returns the example, recorded or placeholder data for the
+// named template, and false if it has none of them.
+func (b *Box) sampleData(name string) (any, bool, error) {
+	b.muPreview.RLock()
+	example, hasExample := b.examples[name]
+	recorded, hasRecorded := b.recorded[name]
+	typ, hasType := b.dataTypes[name]
+	b.muPreview.RUnlock()
+
+	if hasExample {
+		return example, true, nil
+	}
+	if data, ok, err := b.exampleFile(name); ok || err != nil {
+		return data, ok, err
+	}
+	if hasRecorded {
+		return recorded, true, nil
+	}
+	if hasType && typ != nil {
+		data, err := Placeholder(typ)
+		return data, true, err
+	}
+	return nil, false, nil
+}
+
+// exampleDataExts are the extensions of example data files, in order of
+// precedence.
+var exampleDataExts = []string{".data.json", ".data.yaml", ".data.yml"}
+
+// isExampleData reports whether the file p is an example data file.
+func isExampleData(p string) bool {
+	for _, ext := range exampleDataExts {
+		if strings.HasSuffix(p, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// exampleFile returns the decoded example data file of the named template,
+// and false if it has none.
+func (b *Box) exampleFile(name string) (any, bool, error) {
+	b.muFileSets.RLock()
+	s, ok := b.fileSets[name]
+	b.muFileSets.RUnlock()
+	if !ok {
+		return nil, false, nil
+	}
+
+	for i := len(s.Filenames) - 1; i >= 0; i-- {
+		base := strings.TrimSuffix(s.Filenames[i], filepath.Ext(s.Filenames[i]))
+		for _, ext := range exampleDataExts {
+			filename := base + ext
+			text, err := b.readFile(filename)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, false, fmt.Errorf("example data %s: %w", filename, err)
+			}
+			data, err := decodeData(filepath.Ext(filename), text)
+			if err != nil {
+				return nil, false, fmt.Errorf("example data %s: %w", filename, err)
+			}
+			return data, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// Placeholder returns a placeholder value of type t. Strings are set to a
+// description of where they appear, numbers to one, booleans to true and
+// slices and maps contain two and one placeholder elements respectively.
+// Struct fields are filled recursively up to a fixed depth.
+func Placeholder(t reflect.Type) (any, error) {
+	if t == nil {
+		return nil, fmt.Errorf("placeholder type cannot be nil")
+	}
+	return placeholder(t, "value", 0).Interface(), nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// placeholder returns a placeholder value of type t for the field label.
+func placeholder(t reflect.Type, label string, depth int) reflect.Value {
+	v := reflect.New(t).Elem()
+	if depth > maxPlaceholderDepth {
+		return v
+	}
+
+	if t == timeType {
+		v.Set(reflect.ValueOf(time.Date(2024, time.January, 2, 15, 4, 5, 0, time.UTC)))
+		return v
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString("Sample " + label)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1)
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Pointer:
+		v.Set(placeholder(t.Elem(), label, depth+1).Addr())
+	case reflect.Slice:
+		s := reflect.MakeSlice(t, 2, 2)
+		for i := range 2 {
+			s.Index(i).Set(placeholder(t.Elem(), fmt.Sprintf("%s %d", label, i+1), depth+1))
+		}
+		v.Set(s)
+	case reflect.Array:
+		for i := range v.Len() {
+			v.Index(i).Set(placeholder(t.Elem(), fmt.Sprintf("%s %d", label, i+1), depth+1))
+		}
+	case reflect.Map:
+		m := reflect.MakeMapWithSize(t, 1)
+		m.SetMapIndex(placeholder(t.Key(), "key", depth+1), placeholder(t.Elem(), label, depth+1))
+		v.Set(m)
+	case reflect.Struct:
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			v.Field(i).Set(placeholder(f.Type, f.Name, depth+1))
+		}
+	}
+	return v
+}
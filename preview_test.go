@@ -0,0 +1,186 @@
+package templatebox_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+type previewItem struct {
+	Name  string
+	Price float64
+}
+
+type previewPage struct {
+	Title  string
+	Items  []previewItem
+	Author *struct{ Name string }
+	Admin  bool
+}
+
+func TestPreviewGeneratedData(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("page", templatebox.TemplateSet{
+		Templates: []string{`<h1>{{ .Title }}</h1>{{ range .Items }}<li>{{ .Name }} {{ .Price }}</li>{{ end }}` +
+			`{{ .Author.Name }}{{ if .Admin }} admin{{ end }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	box.SetDataType("page", previewPage{})
+
+	got, err := box.Preview("page")
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+
+	expected := `<h1>Sample Title</h1><li>Sample Name 1</li><li>Sample Name 1</li>Sample Name admin`
+	if got != expected {
+		t.Fatalf("Preview returned %s, expected %s", got, expected)
+	}
+}
+
+func TestPreviewExampleData(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("hello", templatebox.TemplateSet{
+		Templates: []string{`Hello {{ .Name }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	box.SetDataType("hello", map[string]string{})
+	box.SetExampleData("hello", map[string]string{"Name": "Ada"})
+
+	got, err := box.Preview("hello")
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+	if got != "Hello Ada" {
+		t.Fatalf("Preview returned %s, expected %s", got, "Hello Ada")
+	}
+}
+
+func TestPreviewWithoutHooks(t *testing.T) {
+	var teed []string
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		Tee: func(name string, output []byte) {
+			teed = append(teed, name)
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	recordDir := t.TempDir()
+	box.SetRecorder(recordDir)
+
+	err = box.AddTemplateRaw("hello", templatebox.TemplateSet{
+		Templates: []string{`Hello {{ .Name }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	box.SetExampleData("hello", map[string]string{"Name": "Ada"})
+
+	if _, err := box.Preview("hello"); err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+
+	// previewing is not a use of the template
+	if _, ok := box.LastRendered("hello"); ok {
+		t.Fatalf("LastRendered reported a render of hello after Preview")
+	}
+	if len(teed) != 0 {
+		t.Fatalf("Tee was called for %q, expected no calls", teed)
+	}
+	entries, err := os.ReadDir(recordDir)
+	if err != nil {
+		t.Fatalf("os.ReadDir failed: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("Preview recorded %d entries, expected none", len(entries))
+	}
+}
+
+func TestPreviewExampleDataFile(t *testing.T) {
+	path := t.TempDir()
+	files := map[string]string{
+		"layout.html":           `<main>{{ template "hello.html" . }}</main>`,
+		"layout.data.json":      `{"Name": "Layout"}`,
+		"hello.html":            `Hello {{ .Name }}, {{ len .Friends }} friends`,
+		"hello.data.json":       `{"Name": "Ada", "Friends": ["Grace", "Alan"]}`,
+		"pages/about.html":      `About {{ .Name }}`,
+		"pages/about.data.json": `{"Name": "Grace"}`,
+	}
+	for name, text := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(path, name)), 0755); err != nil {
+			t.Fatalf("os.MkdirAll failed: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(path, name), []byte(text), 0644); err != nil {
+			t.Fatalf("os.WriteFile failed: %v", err)
+		}
+	}
+
+	box, err := templatebox.NewBoxFromOSDir(path, nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplate("hello", templatebox.FileSet{Filenames: []string{"layout.html", "hello.html"}})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+	err = box.AddTemplate("about", templatebox.FileSet{Filenames: []string{"pages/about.html"}})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	got, err := box.Preview("hello")
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+	if expected := "<main>Hello Ada, 2 friends</main>"; got != expected {
+		t.Fatalf("Preview returned %s, expected %s", got, expected)
+	}
+
+	got, err = box.Preview("about")
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+	if expected := "About Grace"; got != expected {
+		t.Fatalf("Preview returned %s, expected %s", got, expected)
+	}
+
+	// Warm uses the same data
+	if err := box.Warm(context.Background()); err != nil {
+		t.Fatalf("Warm failed: %v", err)
+	}
+
+	// example data files are not reported as orphaned templates
+	orphans, err := box.Orphans()
+	if err != nil {
+		t.Fatalf("Orphans failed: %v", err)
+	}
+	if len(orphans) != 0 {
+		t.Fatalf("Orphans returned %v, expected none", orphans)
+	}
+
+	// registered example data takes precedence over the file
+	box.SetExampleData("about", map[string]string{"Name": "Alan"})
+	got, err = box.Preview("about")
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+	if expected := "About Alan"; got != expected {
+		t.Fatalf("Preview returned %s, expected %s", got, expected)
+	}
+}
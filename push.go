@@ -0,0 +1,278 @@
+package templatebox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PushOptions configures a Pusher.
+type PushOptions struct {
+	// Funcs are bound to the templates rendered by the Pusher, replacing
+	// the functions of the same name, for example a "user" function
+	// returning the user of the connection. The functions must also be
+	// defined when the templates are added, such as with a placeholder in
+	// the global FuncMap, for the templates to parse. Renders with Funcs
+	// bypass the output cache, as their output depends on the Pusher.
+	Funcs FuncMap
+
+	// BatchDelay, if set, collects the fragments pushed within the delay
+	// of the first into a single message, so a burst of updates costs one
+	// message rather than one each.
+	BatchDelay time.Duration
+
+	// MaxBatch sends a batch as soon as it holds this many fragments. Zero
+	// means no limit.
+	MaxBatch int
+}
+
+// Pusher renders named fragments into messages passed to a send function,
+// typically writing to a websocket connection, to power live pages
+// rendered on the server:
+//
+//	p := box.NewPusher(func(msg []byte) error {
+//		return conn.WriteMessage(websocket.TextMessage, msg)
+//	}, templatebox.PushOptions{BatchDelay: 50 * time.Millisecond})
+//	defer p.Close()
+//	err := p.Push("dashboard#stats", stats)
+//
+// Calls to send are serialised. A Pusher is safe for concurrent use.
+type Pusher struct {
+	box  *Box
+	send func(msg []byte) error
+	opts PushOptions
+
+	// templates bound to PushOptions.Funcs
+	muTemplates sync.Mutex
+	templates   map[string]pushSet
+
+	mu      sync.Mutex
+	pending [][]byte
+	timer   *time.Timer
+	err     error // error of a batch sent by the timer
+}
+
+// NewPusher returns a Pusher passing rendered fragments to send.
+func (b *Box) NewPusher(send func(msg []byte) error, opts PushOptions) *Pusher {
+	return &Pusher{
+		box:       b,
+		send:      send,
+		opts:      opts,
+		templates: make(map[string]pushSet),
+	}
+}
+
+// Push renders the named template, which may be a fragment of the form
+// "page#fragment", and sends the output as a message, or adds it to the
+// current batch with PushOptions.BatchDelay. Nothing is sent if the render
+// fails. If sending a batch in the background failed, Push returns that
+// error instead.
+func (p *Pusher) Push(name string, data any) error {
+	return p.PushContext(context.Background(), name, data)
+}
+
+// PushContext is like Push but counts the render against the budget of ctx
+// set with WithBudget, and gives up waiting for a render slot when ctx is
+// done.
+func (p *Pusher) PushContext(ctx context.Context, name string, data any) error {
+	if err := spendBudget(ctx, name); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := p.render(ctx, &buf, name, data); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.err; err != nil {
+		p.err = nil
+		return err
+	}
+	if p.opts.BatchDelay <= 0 {
+		return p.send(buf.Bytes())
+	}
+
+	p.pending = append(p.pending, buf.Bytes())
+	if p.opts.MaxBatch > 0 && len(p.pending) >= p.opts.MaxBatch {
+		return p.flushLocked()
+	}
+	if p.timer == nil {
+		p.timer = time.AfterFunc(p.opts.BatchDelay, func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if err := p.flushLocked(); err != nil && p.err == nil {
+				p.err = err
+			}
+		})
+	}
+	return nil
+}
+
+// Flush sends the current batch immediately.
+func (p *Pusher) Flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.err; err != nil {
+		p.err = nil
+		return err
+	}
+	return p.flushLocked()
+}
+
+// Close sends the current batch. The send function is not called after
+// Close returns unless Push is called again.
+func (p *Pusher) Close() error {
+	return p.Flush()
+}
+
+// flushLocked sends the pending fragments as one message, separated by
+// newlines. The caller must hold p.mu.
+func (p *Pusher) flushLocked() error {
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	if len(p.pending) == 0 {
+		return nil
+	}
+	msg := bytes.Join(p.pending, []byte("\n"))
+	p.pending = nil
+	return p.send(msg)
+}
+
+// render renders the named template into w through the render pipeline of
+// the Box, with the templates bound to PushOptions.Funcs if set.
+func (p *Pusher) render(ctx context.Context, w *bytes.Buffer, name string, data any) error {
+	b := p.box
+	if len(p.opts.Funcs) == 0 {
+		return b.render(ctx, w, name, data)
+	}
+	if b.retainData(data) {
+		defer b.releaseData(data)
+	}
+
+	release, err := b.acquireRender(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	// look the template up for its render options, rebuilding it first in
+	// debug mode, then render its copy bound to the funcs of the Pusher
+	_, opts, err := b.lookup(name)
+	if err != nil {
+		return err
+	}
+	t, err := p.template(name)
+	if err != nil {
+		return err
+	}
+	opts.cache = nil
+	return b.execute(w, name, t, opts, data)
+}
+
+// template returns the named template bound to PushOptions.Funcs. An
+// html/template set is parsed once for every Pusher and cloned for each;
+// other engines bind functions when parsing, so the set is parsed for each
+// Pusher. Bound templates are kept until the template set changes.
+func (p *Pusher) template(name string) (Template, error) {
+	page, fragment, isFragment := strings.Cut(name, "#")
+	set, err := p.box.pushSet(page)
+	if err != nil {
+		return nil, err
+	}
+
+	p.muTemplates.Lock()
+	defer p.muTemplates.Unlock()
+	if bound, ok := p.templates[name]; ok && bound.sum == set.sum {
+		return bound.t, nil
+	}
+
+	var t Template
+	if ht, ok := set.t.(*template.Template); ok {
+		clone, err := ht.Clone()
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", name, err)
+		}
+		t = clone.Funcs(template.FuncMap(p.opts.Funcs))
+	} else if t, err = p.box.parseWithFuncs(page, p.opts.Funcs); err != nil {
+		return nil, err
+	}
+	if isFragment {
+		ht, ok := t.(*template.Template)
+		if !ok {
+			return nil, fmt.Errorf("template %s: fragments are only supported by html/template", name)
+		}
+		if t = ht.Lookup(fragment); t == nil {
+			return nil, fmt.Errorf("template %s not found: %s defines no template %s", name, page, fragment)
+		}
+	}
+	p.templates[name] = pushSet{sum: set.sum, t: t}
+	return t, nil
+}
+
+// pushSet is a template set parsed for Pushers, with the hash of the
+// sources it was parsed from.
+type pushSet struct {
+	sum string
+	t   Template
+}
+
+// pushSet returns the named template set parsed again for Pushers and
+// never executed, so that it can be cloned and bound to the funcs of each
+// Pusher. It is parsed again only when the template set changes.
+func (b *Box) pushSet(name string) (pushSet, error) {
+	b.mu.RLock()
+	sum := b.hashes[name].Sum
+	b.mu.RUnlock()
+
+	b.muPush.Lock()
+	defer b.muPush.Unlock()
+	if set, ok := b.pushSets[name]; ok && set.sum == sum {
+		return set, nil
+	}
+	t, err := b.parseWithFuncs(name, nil)
+	if err != nil {
+		return pushSet{}, err
+	}
+	if b.pushSets == nil {
+		b.pushSets = make(map[string]pushSet)
+	}
+	b.pushSets[name] = pushSet{sum: sum, t: t}
+	return b.pushSets[name], nil
+}
+
+// parseWithFuncs parses the registered FileSet or TemplateSet of the named
+// template again with funcs added to its FuncMap.
+func (b *Box) parseWithFuncs(name string, funcs FuncMap) (Template, error) {
+	b.muFileSets.RLock()
+	fs, isFile := b.fileSets[name]
+	rs, isRaw := b.rawSets[name]
+	b.muFileSets.RUnlock()
+
+	switch {
+	case isFile:
+		fs.FuncMap = withFuncs(fs.FuncMap, funcs)
+		p, err := b.parseFileSet(fs)
+		return p.t, err
+	case isRaw:
+		rs.FuncMap = withFuncs(rs.FuncMap, funcs)
+		p, err := b.parseRaw(name, rs)
+		return p.t, err
+	}
+	return nil, fmt.Errorf("template %s not found", name)
+}
+
+// withFuncs returns a copy of m with funcs added.
+func withFuncs(m, funcs FuncMap) FuncMap {
+	out := make(FuncMap, len(m)+len(funcs))
+	maps.Copy(out, m)
+	maps.Copy(out, funcs)
+	return out
+}
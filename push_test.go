@@ -0,0 +1,166 @@
+package templatebox_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+// messages collects the messages sent by a Pusher.
+type messages struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (m *messages) send(msg []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.msgs = append(m.msgs, string(msg))
+	return nil
+}
+
+func (m *messages) list() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.msgs...)
+}
+
+func newPushBox(t *testing.T) *templatebox.Box {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetGlobalFuncMap(templatebox.FuncMap{"user": func() string { return "" }})
+	err = box.AddTemplateRaw("dashboard", templatebox.TemplateSet{
+		Templates: []string{
+			`<main>{{ template "stat" . }}</main>`,
+			`{{ define "stat" }}<b id="stat" hx-swap-oob="true">{{ user }}: {{ . }}</b>{{ end }}`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	return box
+}
+
+func TestPusherFuncs(t *testing.T) {
+	box := newPushBox(t)
+
+	var ada, grace messages
+	pa := box.NewPusher(ada.send, templatebox.PushOptions{
+		Funcs: templatebox.FuncMap{"user": func() string { return "ada" }},
+	})
+	pg := box.NewPusher(grace.send, templatebox.PushOptions{
+		Funcs: templatebox.FuncMap{"user": func() string { return "grace" }},
+	})
+
+	for _, p := range []*templatebox.Pusher{pa, pg} {
+		if err := p.Push("dashboard#stat", 42); err != nil {
+			t.Fatalf("Push failed: %v", err)
+		}
+	}
+
+	expected := []string{`<b id="stat" hx-swap-oob="true">ada: 42</b>`}
+	if got := ada.list(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Pusher sent %q, expected %q", got, expected)
+	}
+	expected = []string{`<b id="stat" hx-swap-oob="true">grace: 42</b>`}
+	if got := grace.list(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Pusher sent %q, expected %q", got, expected)
+	}
+
+	// the box itself still renders with the global FuncMap
+	got, err := box.RenderString("dashboard#stat", 1)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	if expected := `<b id="stat" hx-swap-oob="true">: 1</b>`; got != expected {
+		t.Fatalf("RenderString returned %s, expected %s", got, expected)
+	}
+}
+
+func TestPusherFuncsRenderPipeline(t *testing.T) {
+	var teed []string
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		Tee: func(name string, output []byte) {
+			teed = append(teed, name+": "+string(output))
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetGlobalFuncMap(templatebox.FuncMap{"user": func() string { return "" }})
+	err = box.AddTemplateRaw("greeting", templatebox.TemplateSet{
+		Templates: []string{`Hello {{ user }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var m messages
+	p := box.NewPusher(m.send, templatebox.PushOptions{
+		Funcs: templatebox.FuncMap{"user": func() string { return "ada" }},
+	})
+	ctx := templatebox.WithBudget(context.Background(), 1)
+	if err := p.PushContext(ctx, "greeting", nil); err != nil {
+		t.Fatalf("PushContext failed: %v", err)
+	}
+
+	// the render goes through the Tee and usage tracking of the Box
+	if expected := []string{"greeting: Hello ada"}; !reflect.DeepEqual(teed, expected) {
+		t.Fatalf("Tee got %q, expected %q", teed, expected)
+	}
+	if _, ok := box.LastRendered("greeting"); !ok {
+		t.Fatalf("LastRendered reported no render of greeting after Push")
+	}
+
+	// and counts against the budget of the context
+	err = p.PushContext(ctx, "greeting", nil)
+	if !errors.Is(err, templatebox.ErrBudgetExceeded) {
+		t.Fatalf("PushContext returned %v, expected %v", err, templatebox.ErrBudgetExceeded)
+	}
+}
+
+func TestPusherBatching(t *testing.T) {
+	box := newPushBox(t)
+
+	var m messages
+	p := box.NewPusher(m.send, templatebox.PushOptions{BatchDelay: time.Hour, MaxBatch: 3})
+	for i := range 4 {
+		if err := p.Push("dashboard#stat", i); err != nil {
+			t.Fatalf("Push failed: %v", err)
+		}
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	expected := []string{
+		`<b id="stat" hx-swap-oob="true">: 0</b>` + "\n" +
+			`<b id="stat" hx-swap-oob="true">: 1</b>` + "\n" +
+			`<b id="stat" hx-swap-oob="true">: 2</b>`,
+		`<b id="stat" hx-swap-oob="true">: 3</b>`,
+	}
+	if got := m.list(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Pusher sent %q, expected %q", got, expected)
+	}
+
+	// a batch is sent after the delay without a flush
+	var delayed messages
+	p = box.NewPusher(delayed.send, templatebox.PushOptions{BatchDelay: 10 * time.Millisecond})
+	if err := p.Push("dashboard#stat", 5); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(delayed.list()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := delayed.list(); len(got) != 1 {
+		t.Fatalf("Pusher sent %q after the delay, expected one message", got)
+	}
+}
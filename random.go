@@ -0,0 +1,51 @@
+package templatebox
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// randomBytes returns n bytes read from the Config.Random source.
+func (b *Box) randomBytes(n int) ([]byte, error) {
+	r := b.cfg.Random
+	if r == nil {
+		r = rand.Reader
+	}
+
+	p := make([]byte, n)
+	b.muRandom.Lock()
+	_, err := io.ReadFull(r, p)
+	b.muRandom.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("read random bytes: %w", err)
+	}
+	return p, nil
+}
+
+// uuid implements the uuid template function, returning a random version 4
+// UUID such as "1b4e28ba-2fa1-41d2-883f-0016d3cca427", for form element
+// ids and idempotency keys.
+func (b *Box) uuid() (string, error) {
+	p, err := b.randomBytes(16)
+	if err != nil {
+		return "", err
+	}
+	p[6] = p[6]&0x0f | 0x40 // version 4
+	p[8] = p[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", p[0:4], p[4:6], p[6:8], p[8:10], p[10:]), nil
+}
+
+// randomHex implements the randomHex template function, returning n random
+// bytes as 2n hexadecimal digits, for example {{ randomHex 8 }}.
+func (b *Box) randomHex(n int) (string, error) {
+	if n < 0 {
+		return "", fmt.Errorf("randomHex expects a non-negative length, got %d", n)
+	}
+	p, err := b.randomBytes(n)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(p), nil
+}
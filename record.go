@@ -0,0 +1,91 @@
+package templatebox
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"sort"
+)
+
+// SetRedactor sets a function applied to render data before the Box logs,
+// keeps or shows a copy of it: render failures logged in debug mode, data
+// recorded with Config.RecordData and the data served by the AdminHandler.
+// Use it to remove passwords, tokens and personal information so they never
+// leak into logs, even when debug tooling is enabled in staging. The
+// function must not modify data in place; return a redacted copy instead.
+func (b *Box) SetRedactor(fn func(data any) any) {
+	b.redactor = fn
+}
+
+// redact returns data passed through the redactor, if one is set.
+func (b *Box) redact(data any) any {
+	if b.redactor == nil {
+		return data
+	}
+	return b.redactor(data)
+}
+
+// logRenderError logs a failed render of the named template in debug mode,
+// along with the redacted data if a redactor is set. Without one only the
+// type of the data and the keys of a map are logged, as the values may be
+// secrets.
+func (b *Box) logRenderError(name string, data any, err error) {
+	if !b.debug() {
+		return
+	}
+	attrs := []any{"template", name, "error", err}
+	if b.redactor != nil {
+		attrs = append(attrs, "data", b.redact(data))
+	} else {
+		attrs = append(attrs, "data_type", fmt.Sprintf("%T", data))
+		if keys := dataKeys(data); keys != nil {
+			attrs = append(attrs, "data_keys", keys)
+		}
+	}
+	b.logger().Error("templatebox: render failed", attrs...)
+}
+
+// dataKeys returns the sorted keys of data if it is a map, and nil
+// otherwise.
+func dataKeys(data any) []string {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Map {
+		return nil
+	}
+	keys := make([]string, 0, v.Len())
+	for _, k := range v.MapKeys() {
+		keys = append(keys, fmt.Sprint(k.Interface()))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// recordData keeps a redacted copy of the data passed to the named
+// template when recording is enabled. Only the most recent value per
+// template is kept.
+func (b *Box) recordData(name string, data any) {
+	if !b.debug() || !b.cfg.RecordData {
+		return
+	}
+
+	// a pooled Data is cleared after the render, so keep a copy
+	if d, ok := data.(Data); ok {
+		data = maps.Clone(d)
+	}
+	data = b.redact(data)
+	b.muPreview.Lock()
+	b.recorded[name] = data
+	b.muPreview.Unlock()
+}
+
+// RecordedData returns the redacted data most recently passed to the named
+// template. Data is only recorded when both Config.Debug and
+// Config.RecordData are set. The data is recorded before the template is
+// executed, so after a failed render it holds the data that caused the
+// failure and the page can be rendered again with it.
+func (b *Box) RecordedData(name string) (any, bool) {
+	b.muPreview.RLock()
+	defer b.muPreview.RUnlock()
+	data, ok := b.recorded[name]
+	return data, ok
+}
@@ -0,0 +1,141 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestRecordedData(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		Debug:      true,
+		RecordData: true,
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	box.SetRedactor(func(data any) any {
+		m, ok := data.(map[string]string)
+		if !ok {
+			return data
+		}
+		c := make(map[string]string, len(m))
+		for k, v := range m {
+			c[k] = v
+		}
+		c["Password"] = "[redacted]"
+		return c
+	})
+
+	err = box.AddTemplateRaw("login", templatebox.TemplateSet{
+		Templates: []string{`{{ .User }}{{ .Missing.Field }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	data := map[string]string{"User": "ada", "Password": "secret"}
+	box.RenderHTML(io.Discard, "login", data)
+
+	got, ok := box.RecordedData("login")
+	if !ok {
+		t.Fatalf("RecordedData returned false, expected true")
+	}
+	expected := map[string]string{"User": "ada", "Password": "[redacted]"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("RecordedData returned %v, expected %v", got, expected)
+	}
+	if data["Password"] != "secret" {
+		t.Fatalf("redactor modified the render data")
+	}
+}
+
+func TestRecordedDataDisabled(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		Debug: true,
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("hello", templatebox.TemplateSet{
+		Templates: []string{`Hello {{ . }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	if err := box.RenderHTML(io.Discard, "hello", "ada"); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+	if _, ok := box.RecordedData("hello"); ok {
+		t.Fatalf("RecordedData returned true, expected false without Config.RecordData")
+	}
+}
+
+func TestRedactorAppliedToLogs(t *testing.T) {
+	var logs bytes.Buffer
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		Debug:  true,
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetRedactor(func(data any) any { return "[redacted]" })
+
+	err = box.AddTemplateRaw("broken", templatebox.TemplateSet{
+		Templates: []string{`{{ index .Items 5 }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	data := map[string]any{"Email": "ada@example.com", "Items": []int{}}
+	if err := box.RenderHTML(io.Discard, "broken", data); err == nil {
+		t.Fatalf("RenderHTML succeeded, expected an error")
+	}
+
+	if strings.Contains(logs.String(), "ada@example.com") {
+		t.Fatalf("log contains unredacted data: %s", logs.String())
+	}
+	if !strings.Contains(logs.String(), "data=[redacted]") {
+		t.Fatalf("log does not contain redacted data: %s", logs.String())
+	}
+}
+
+func TestRenderErrorLogWithoutRedactor(t *testing.T) {
+	var logs bytes.Buffer
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		Debug:  true,
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("broken", templatebox.TemplateSet{
+		Templates: []string{`{{ index .Items 5 }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	data := map[string]any{"Email": "ada@example.com", "Items": []int{}}
+	if err := box.RenderHTML(io.Discard, "broken", data); err == nil {
+		t.Fatalf("RenderHTML succeeded, expected an error")
+	}
+
+	if strings.Contains(logs.String(), "ada@example.com") {
+		t.Fatalf("log contains the data values: %s", logs.String())
+	}
+	expected := `data_type="map[string]interface {}" data_keys="[Email Items]"`
+	if !strings.Contains(logs.String(), expected) {
+		t.Fatalf("log does not contain %s: %s", expected, logs.String())
+	}
+}
@@ -0,0 +1,70 @@
+package templatebox
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// SetRecorder makes the Box write the output of every successful render to
+// dir, for integration test suites that replay and diff rendered pages
+// across releases. Each render is written to dir/<name>/<hash>.html, where
+// hash identifies the data, along with the redacted data as
+// dir/<name>/<hash>.json when it can be encoded as JSON. The name is path
+// escaped; templates whose names are not usable as a directory name, such
+// as "..", are not recorded. Rendering the same template with the same
+// data overwrites the previous recording. Failures to write are logged and
+// do not fail the render. An empty dir disables recording.
+func (b *Box) SetRecorder(dir string) {
+	b.recordDir = dir
+}
+
+// recordRender writes the output of a render of the named template with
+// data to the recorder directory.
+func (b *Box) recordRender(name string, data any, output []byte) {
+	if err := b.writeRecording(name, data, output); err != nil {
+		b.logger().Error("templatebox: record render failed", "template", name, "error", err)
+	}
+}
+
+// writeRecording implements recordRender.
+func (b *Box) writeRecording(name string, data any, output []byte) error {
+	hash, encoded, jsonErr := hashData(b.redact(data))
+	hash = hash[:16]
+
+	escaped := url.PathEscape(name)
+	if !fs.ValidPath(escaped) || escaped == "." {
+		return fmt.Errorf("record template %q: %w: %s", name, ErrInvalidPath, escaped)
+	}
+	dir := filepath.Join(b.recordDir, escaped)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, hash+".html"), output, 0644); err != nil {
+		return err
+	}
+	if jsonErr == nil {
+		return os.WriteFile(filepath.Join(dir, hash+".json"), encoded, 0644)
+	}
+	return nil
+}
+
+// hashData returns the hex encoded SHA-256 of the JSON encoding of data,
+// along with the encoding. Data that cannot be encoded as JSON is hashed
+// by its Go syntax representation instead, and the JSON error returned.
+func hashData(data any) (string, []byte, error) {
+	encoded, err := json.Marshal(data)
+
+	sum := sha256.New()
+	if err == nil {
+		sum.Write(encoded)
+	} else {
+		fmt.Fprintf(sum, "%#v", data)
+	}
+	return hex.EncodeToString(sum.Sum(nil)), encoded, err
+}
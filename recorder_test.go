@@ -0,0 +1,101 @@
+package templatebox_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestSetRecorder(t *testing.T) {
+	dir := t.TempDir()
+
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("emails/welcome", templatebox.TemplateSet{
+		Templates: []string{`Welcome {{ .Name }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	box.SetRecorder(dir)
+
+	data := map[string]string{"Name": "Andy"}
+	for range 2 {
+		if _, err := box.RenderString("emails/welcome", data); err != nil {
+			t.Fatalf("RenderString failed: %v", err)
+		}
+	}
+
+	outputs, err := filepath.Glob(filepath.Join(dir, "emails%2Fwelcome", "*.html"))
+	if err != nil {
+		t.Fatalf("filepath.Glob failed: %v", err)
+	}
+	if len(outputs) != 1 {
+		t.Fatalf("recorded %d outputs, expected 1", len(outputs))
+	}
+
+	output, err := os.ReadFile(outputs[0])
+	if err != nil {
+		t.Fatalf("os.ReadFile failed: %v", err)
+	}
+	expected := "Welcome Andy"
+	if string(output) != expected {
+		t.Fatalf("recorded %s, expected %s", output, expected)
+	}
+
+	recorded, err := os.ReadFile(outputs[0][:len(outputs[0])-len(".html")] + ".json")
+	if err != nil {
+		t.Fatalf("os.ReadFile failed: %v", err)
+	}
+	expected = `{"Name":"Andy"}`
+	if string(recorded) != expected {
+		t.Fatalf("recorded data %s, expected %s", recorded, expected)
+	}
+}
+
+func TestSetRecorderInvalidNames(t *testing.T) {
+	dir := t.TempDir()
+	recordDir := filepath.Join(dir, "recordings")
+
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	for _, name := range []string{"..", "."} {
+		err = box.AddTemplateRaw(name, templatebox.TemplateSet{
+			Templates: []string{`Hello`},
+		})
+		if err != nil {
+			t.Fatalf("AddTemplateRaw failed: %v", err)
+		}
+	}
+	box.SetRecorder(recordDir)
+
+	for _, name := range []string{"..", "."} {
+		if _, err := box.RenderString(name, nil); err != nil {
+			t.Fatalf("RenderString failed: %v", err)
+		}
+	}
+
+	// nothing is written to or above the recorder directory
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("os.ReadDir failed: %v", err)
+	}
+	for _, e := range entries {
+		if e.Name() != "recordings" {
+			t.Fatalf("recorder wrote %s outside its directory", e.Name())
+		}
+	}
+	if entries, err := os.ReadDir(recordDir); err == nil {
+		for _, e := range entries {
+			if !e.IsDir() {
+				t.Fatalf("recorder wrote %s into its directory", e.Name())
+			}
+		}
+	}
+}
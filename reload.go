@@ -0,0 +1,156 @@
+package templatebox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+)
+
+// Reload re-reads and re-parses every template added with AddTemplate. All
+// templates are parsed before any is replaced, so if one fails to parse the
+// Box keeps serving the previously loaded templates and the returned error
+// lists every failure. Templates added with AddTemplateRaw have no source to
+// reload from and are left unchanged. With Config.CanaryReload, changed
+// templates must also render their sample data before any is replaced.
+// With Config.KeepVersions, the template set before and after the reload
+// is recorded as a version for Diff.
+func (b *Box) Reload() error {
+	return b.ReloadContext(context.Background())
+}
+
+// ReloadContext is like Reload but records the actor of ctx, set with
+// WithActor, in the audit log.
+func (b *Box) ReloadContext(ctx context.Context) error {
+	sets := b.registeredFileSets()
+
+	names := make([]string, 0, len(sets))
+	for name := range sets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parsed := make(map[string]parsedTemplate, len(sets))
+	var errs []error
+	for _, name := range names {
+		p, err := b.parseFileSet(sets[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("reload template %s: %w", name, err))
+			continue
+		}
+		parsed[name] = p
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if b.cfg.CanaryReload {
+		for _, name := range names {
+			if err := b.canary(name, parsed[name]); err != nil {
+				errs = append(errs, fmt.Errorf("reload template %s: %w", name, err))
+			}
+		}
+		if len(errs) > 0 {
+			return errors.Join(errs...)
+		}
+	}
+
+	if b.cfg.KeepVersions > 0 {
+		b.recordVersion()
+	}
+
+	var changed []string
+	b.mu.Lock()
+	for _, name := range names {
+		p := parsed[name]
+		if b.hashes[name].Sum != p.hash.Sum {
+			changed = append(changed, name)
+		}
+		b.html[name] = p.t
+		b.hashes[name] = p.hash
+		opts := b.opts[name]
+		opts.requires = parseRequires(p.srcs)
+		b.opts[name] = opts
+		if b.cfg.KeepVersions > 0 {
+			b.sources[name] = p.srcs
+		}
+	}
+	b.publishLocked()
+	b.mu.Unlock()
+
+	if b.cfg.KeepVersions > 0 {
+		b.recordVersion()
+	}
+	b.audit(ctx, AuditReload, changed...)
+	return nil
+}
+
+// canary renders p, the reloaded version of the named template, with the
+// sample data of the template, discarding the output. Templates whose
+// sources are unchanged or that have no sample data are not rendered.
+func (b *Box) canary(name string, p parsedTemplate) error {
+	b.mu.RLock()
+	old := b.hashes[name]
+	b.mu.RUnlock()
+	if old.Sum == p.hash.Sum {
+		return nil
+	}
+
+	data, ok, err := b.sampleData(name)
+	if err != nil {
+		return fmt.Errorf("canary data: %w", err)
+	}
+	if !ok {
+		return nil
+	}
+	if err := p.t.Execute(io.Discard, data); err != nil {
+		return fmt.Errorf("canary render failed: %w", err)
+	}
+	return nil
+}
+
+// HandleSignals calls Reload each time the process receives one of the
+// given signals, logging the result to the configured Logger. If no signals
+// are given SIGHUP is used on Unix systems; elsewhere the signals must be
+// given. It returns immediately; signals are handled in a background
+// goroutine until ctx is done or the Box is closed. This lets operators
+// push template-only updates, for example to a mounted volume, without
+// restarting the binary:
+//
+//	box.HandleSignals(ctx, syscall.SIGHUP)
+func (b *Box) HandleSignals(ctx context.Context, sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = defaultReloadSignals
+	}
+	if len(sigs) == 0 {
+		b.logger().Warn("templatebox: no signals to reload on; give HandleSignals the signals")
+		return
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+
+	b.background.Add(1)
+	go func() {
+		defer b.background.Done()
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.closing:
+				return
+			case sig := <-ch:
+				log := b.logger().With("signal", sig.String())
+				if err := b.ReloadContext(WithActor(ctx, sig.String())); err != nil {
+					log.Error("templatebox: reload failed; keeping previous templates", "error", err)
+					continue
+				}
+				log.Info("templatebox: templates reloaded")
+			}
+		}
+	}()
+}
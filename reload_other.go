@@ -0,0 +1,9 @@
+//go:build !unix
+
+package templatebox
+
+import "os"
+
+// defaultReloadSignals are the signals HandleSignals reloads on when none
+// are given. Platforms without SIGHUP have none.
+var defaultReloadSignals []os.Signal
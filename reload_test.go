@@ -0,0 +1,122 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+// writeTemplates writes each name and content pair to dir.
+func writeTemplates(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		filename := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("os.MkdirAll failed: %v", err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatalf("os.WriteFile failed: %v", err)
+		}
+	}
+}
+
+func TestReload(t *testing.T) {
+	path := t.TempDir()
+	writeTemplates(t, path, map[string]string{
+		"a.html": `version 1`,
+		"b.html": `b`,
+	})
+
+	box, err := templatebox.NewBoxFromOSDir(path, nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateMap(map[string]templatebox.FileSet{
+		"a": {Filenames: []string{"a.html"}},
+		"b": {Filenames: []string{"b.html"}},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateMap failed: %v", err)
+	}
+
+	// a parse error in b must not replace a
+	writeTemplates(t, path, map[string]string{
+		"a.html": `version 2`,
+		"b.html": `{{ if }}`,
+	})
+	if err := box.Reload(); err == nil {
+		t.Fatalf("Reload succeeded, expected a parse error")
+	}
+
+	s, err := box.RenderString("a", nil)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	if s != "version 1" {
+		t.Fatalf("RenderString returned %s, expected %s", s, "version 1")
+	}
+
+	writeTemplates(t, path, map[string]string{"b.html": `b`})
+	if err := box.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	s, err = box.RenderString("a", nil)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	if s != "version 2" {
+		t.Fatalf("RenderString returned %s, expected %s", s, "version 2")
+	}
+}
+
+func TestCanaryReload(t *testing.T) {
+	path := t.TempDir()
+	writeTemplates(t, path, map[string]string{"a.html": `{{ .Title }} v1`})
+
+	box, err := templatebox.NewBoxFromOSDir(path, &templatebox.Config{CanaryReload: true})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	if err := box.AddTemplate("a", templatebox.FileSet{Filenames: []string{"a.html"}}); err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+	box.SetExampleData("a", struct{ Title string }{Title: "Home"})
+
+	// parses but fails to render with the example data
+	writeTemplates(t, path, map[string]string{"a.html": `{{ .Heading }} v2`})
+	if err := box.Reload(); err == nil {
+		t.Fatalf("Reload succeeded, expected a canary render error")
+	}
+
+	s, err := box.Preview("a")
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+	expected := "Home v1"
+	if s != expected {
+		t.Fatalf("Preview returned %s, expected %s", s, expected)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,12 @@
+//go:build unix
+
+package templatebox
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultReloadSignals are the signals HandleSignals reloads on when none
+// are given.
+var defaultReloadSignals = []os.Signal{syscall.SIGHUP}
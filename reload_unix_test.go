@@ -0,0 +1,55 @@
+//go:build unix
+
+package templatebox_test
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestHandleSignals(t *testing.T) {
+	path := t.TempDir()
+	writeTemplates(t, path, map[string]string{"a.html": `version 1`})
+
+	var logs syncBuffer
+	box, err := templatebox.NewBoxFromOSDir(path, &templatebox.Config{
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	if err := box.AddTemplate("a", templatebox.FileSet{Filenames: []string{"a.html"}}); err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	box.HandleSignals(ctx, syscall.SIGHUP)
+
+	writeTemplates(t, path, map[string]string{"a.html": `version 2`})
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("syscall.Kill failed: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(logs.String(), "templates reloaded") {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for reload, logs: %s", logs.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	s, err := box.RenderString("a", nil)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	if s != "version 2" {
+		t.Fatalf("RenderString returned %s, expected %s", s, "version 2")
+	}
+}
@@ -0,0 +1,61 @@
+package templatebox
+
+import (
+	"bytes"
+	"io"
+)
+
+// RenderedPage is the immutable output of a template rendered ahead of
+// time by PrepareHTML. It can be written to any number of responses without
+// re-rendering and without copying the output into intermediate buffers.
+// A RenderedPage is safe for concurrent use.
+type RenderedPage struct {
+	name string
+	b    []byte
+}
+
+// PrepareHTML renders the named template with the given data and returns
+// the output as a RenderedPage. Use it for hot pages whose output is reused
+// across many responses.
+func (b *Box) PrepareHTML(name string, data any) (*RenderedPage, error) {
+	var buf bytes.Buffer
+	if err := b.RenderHTML(&buf, name, data); err != nil {
+		return nil, err
+	}
+	return &RenderedPage{name: name, b: buf.Bytes()}, nil
+}
+
+// Name returns the name of the template the page was rendered from.
+func (p *RenderedPage) Name() string {
+	return p.name
+}
+
+// Len returns the length of the rendered output in bytes.
+func (p *RenderedPage) Len() int {
+	return len(p.b)
+}
+
+// Bytes returns the rendered output. The returned slice must not be
+// modified.
+func (p *RenderedPage) Bytes() []byte {
+	return p.b
+}
+
+// String returns the rendered output as a string.
+func (p *RenderedPage) String() string {
+	return string(p.b)
+}
+
+// Reader returns a new io.ReadSeeker over the rendered output, suitable for
+// http.ServeContent.
+func (p *RenderedPage) Reader() *bytes.Reader {
+	return bytes.NewReader(p.b)
+}
+
+// WriteTo writes the rendered output to w in a single call. It implements
+// io.WriterTo, and unlike an io.Reader the page is not consumed, so the same
+// page can be written to every response that needs it.
+func (p *RenderedPage) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(p.b)
+	return int64(n), err
+}
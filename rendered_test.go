@@ -0,0 +1,93 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestPrepareHTML(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplate("a", templatebox.FileSet{
+		Filenames: []string{"layout.html", "a.html"},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	page, err := box.PrepareHTML("a", nil)
+	if err != nil {
+		t.Fatalf("PrepareHTML failed: %v", err)
+	}
+
+	var expected bytes.Buffer
+	if err := box.RenderHTML(&expected, "a", nil); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	// the page can be written more than once
+	for range 2 {
+		var buf bytes.Buffer
+		n, err := page.WriteTo(&buf)
+		if err != nil {
+			t.Fatalf("WriteTo failed: %v", err)
+		}
+		if n != int64(page.Len()) || buf.String() != expected.String() {
+			t.Fatalf("WriteTo wrote %d bytes %s, expected %s", n, buf.String(), expected.String())
+		}
+	}
+
+	if page.Name() != "a" {
+		t.Fatalf("Name returned %s, expected %s", page.Name(), "a")
+	}
+}
+
+func newBenchmarkBox(b *testing.B) *templatebox.Box {
+	b.Helper()
+
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		b.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplate("a", templatebox.FileSet{
+		Filenames: []string{"layout.html", "a.html"},
+	})
+	if err != nil {
+		b.Fatalf("AddTemplate failed: %v", err)
+	}
+	return box
+}
+
+func BenchmarkRenderHTML(b *testing.B) {
+	box := newBenchmarkBox(b)
+
+	b.ReportAllocs()
+	for range b.N {
+		if err := box.RenderHTML(io.Discard, "a", nil); err != nil {
+			b.Fatalf("RenderHTML failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkRenderedPageWriteTo(b *testing.B) {
+	box := newBenchmarkBox(b)
+
+	page, err := box.PrepareHTML("a", nil)
+	if err != nil {
+		b.Fatalf("PrepareHTML failed: %v", err)
+	}
+
+	b.ReportAllocs()
+	for range b.N {
+		if _, err := page.WriteTo(io.Discard); err != nil {
+			b.Fatalf("WriteTo failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package templatebox
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template/parse"
+)
+
+// TemplateReport describes the size and complexity of a registered
+// template.
+type TemplateReport struct {
+	Name string
+
+	// Files is the number of files or template strings parsed.
+	Files int
+
+	// SourceBytes is the total size of the parsed source.
+	SourceBytes int
+
+	// Blocks is the number of templates defined with define or block.
+	Blocks int
+
+	// Funcs lists the distinct functions referenced, including the
+	// predefined functions such as len and printf.
+	Funcs []string
+}
+
+// Report returns a TemplateReport for every registered template, sorted by
+// name. Template files are read from the Box filesystem again, so the
+// report reflects the files as they are now. Templates parsed by an Engine
+// other than html/template are skipped.
+func (b *Box) Report() ([]TemplateReport, error) {
+	var reports []TemplateReport
+	for _, name := range b.htmlNames() {
+		srcs, err := b.templateSources(name)
+		if err != nil {
+			return nil, fmt.Errorf("report template %s: %w", name, err)
+		}
+		trees, err := parseTrees(srcs)
+		if err != nil {
+			return nil, fmt.Errorf("report template %s: %w", name, err)
+		}
+
+		r := TemplateReport{Name: name, Files: len(srcs)}
+		sourceNames := make(map[string]bool)
+		for _, src := range srcs {
+			r.SourceBytes += len(src.Text)
+			sourceNames[src.Name] = true
+		}
+
+		funcs := make(map[string]bool)
+		for treeName, tree := range trees {
+			if !sourceNames[treeName] {
+				r.Blocks++
+			}
+			walkNodes(tree.Root, func(n parse.Node) {
+				if id, ok := n.(*parse.IdentifierNode); ok {
+					funcs[id.Ident] = true
+				}
+			})
+		}
+		for f := range funcs {
+			r.Funcs = append(r.Funcs, f)
+		}
+		sort.Strings(r.Funcs)
+
+		reports = append(reports, r)
+	}
+	return reports, nil
+}
+
+// WriteReportTable writes the reports to w as a human-readable table.
+func WriteReportTable(w io.Writer, reports []TemplateReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TEMPLATE\tFILES\tBYTES\tBLOCKS\tFUNCS")
+	for _, r := range reports {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\n",
+			r.Name, r.Files, r.SourceBytes, r.Blocks, strings.Join(r.Funcs, ","))
+	}
+	return tw.Flush()
+}
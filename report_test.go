@@ -0,0 +1,62 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestReport(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplate("d", templatebox.FileSet{
+		Filenames: []string{"layout.html", "d.html"},
+		FuncMap: templatebox.FuncMap{
+			"uppr": strings.ToUpper,
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("raw", templatebox.TemplateSet{
+		Templates: []string{
+			`{{ template "a" . }}{{ template "b" . }}`,
+			`{{ define "a" }}{{ len . }}{{ end }}{{ block "b" . }}{{ printf "%v" . }}{{ end }}`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	reports, err := box.Report()
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+
+	expected := []templatebox.TemplateReport{
+		{Name: "d", Files: 2, SourceBytes: 291, Blocks: 1, Funcs: []string{"uppr"}},
+		{Name: "raw", Files: 2, SourceBytes: 121, Blocks: 2, Funcs: []string{"len", "printf"}},
+	}
+	if !reflect.DeepEqual(reports, expected) {
+		t.Fatalf("Report returned %+v, expected %+v", reports, expected)
+	}
+
+	var buf bytes.Buffer
+	if err := templatebox.WriteReportTable(&buf, reports); err != nil {
+		t.Fatalf("WriteReportTable failed: %v", err)
+	}
+	table := `TEMPLATE  FILES  BYTES  BLOCKS  FUNCS
+d         2      291    1       uppr
+raw       2      121    2       len,printf
+`
+	if buf.String() != table {
+		t.Fatalf("WriteReportTable wrote\n%s\nexpected\n%s", buf.String(), table)
+	}
+}
@@ -0,0 +1,145 @@
+package templatebox
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// requiresComment matches a template comment declaring the data a template
+// requires, such as {{/* requires: Title string, Items []Item */}}.
+var requiresComment = regexp.MustCompile(`(?s)\{\{-?\s*/\*\s*requires:(.*?)\*/\s*-?\}\}`)
+
+// requiredField is a field declared by a requires comment. The type is empty
+// when only the name is given.
+type requiredField struct {
+	path []string
+	typ  string
+}
+
+// parseRequires returns the fields declared by the requires comments of the
+// sources. Each comment lists fields separated by commas, each a name or
+// dotted path such as User.Email, optionally followed by its Go type.
+func parseRequires(srcs []Source) []requiredField {
+	var fields []requiredField
+	for _, src := range srcs {
+		for _, m := range requiresComment.FindAllSubmatch(src.Text, -1) {
+			for _, decl := range strings.Split(string(m[1]), ",") {
+				name, typ, _ := strings.Cut(strings.TrimSpace(decl), " ")
+				if name == "" {
+					continue
+				}
+				fields = append(fields, requiredField{
+					path: strings.Split(name, "."),
+					typ:  strings.TrimSpace(typ),
+				})
+			}
+		}
+	}
+	return fields
+}
+
+// packageQualifier matches the package names qualifying type names, so that
+// a declared []Item matches []shop.Item.
+var packageQualifier = regexp.MustCompile(`\b\w+\.`)
+
+// checkRequired checks data against the fields required by the named
+// template, returning an error naming every field that is missing or nil, or
+// whose type differs from the declared type. Fields are looked up as the
+// template would: struct fields and methods without arguments, and the keys
+// of maps.
+func checkRequired(name string, fields []requiredField, data any) error {
+	var errs []error
+	for _, f := range fields {
+		field := strings.Join(f.path, ".")
+		v, ok := fieldValue(reflect.ValueOf(data), f.path)
+		if !ok {
+			errs = append(errs, fmt.Errorf("template %s: missing field %s", name, field))
+			continue
+		}
+		if f.typ == "" || f.typ == "any" || f.typ == "interface{}" {
+			continue
+		}
+		got := packageQualifier.ReplaceAllString(v.Type().String(), "")
+		if expected := packageQualifier.ReplaceAllString(f.typ, ""); got != expected {
+			errs = append(errs, fmt.Errorf("template %s: field %s is %s, expected %s", name, field, v.Type(), f.typ))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// fieldValue returns the value at path in v, reporting false if any element
+// of the path is missing or a nil pointer or interface. Nil slices and maps
+// are present, as the template renders them as empty.
+func fieldValue(v reflect.Value, path []string) (reflect.Value, bool) {
+	for _, name := range path {
+		v = indirectValue(v)
+		if !v.IsValid() {
+			return v, false
+		}
+		if m := v.MethodByName(name); m.IsValid() && m.Type().NumIn() == 0 && m.Type().NumOut() > 0 {
+			v = m.Call(nil)[0]
+			continue
+		}
+		if v.Kind() == reflect.Pointer {
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.Struct:
+			sf, ok := v.Type().FieldByName(name)
+			if !ok || !sf.IsExported() {
+				return v, false
+			}
+			v = v.FieldByIndex(sf.Index)
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return v, false
+			}
+			v = v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		default:
+			return v, false
+		}
+	}
+	v = indirectInterface(v)
+	if !v.IsValid() {
+		return v, false
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Func, reflect.Chan:
+		if v.IsNil() {
+			return v, false
+		}
+	}
+	return v, true
+}
+
+// indirectValue returns the value v points to or holds, keeping the last
+// pointer so that its methods can be found. It returns the zero Value for
+// nil.
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer && v.Elem().Kind() == reflect.Pointer) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	if v.IsValid() && v.Kind() == reflect.Pointer && v.IsNil() {
+		return reflect.Value{}
+	}
+	return v
+}
+
+// indirectInterface returns the value held by the interface v, or the zero
+// Value for a nil interface.
+func indirectInterface(v reflect.Value) reflect.Value {
+	if v.IsValid() && v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		return v.Elem()
+	}
+	return v
+}
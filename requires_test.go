@@ -0,0 +1,123 @@
+package templatebox_test
+
+import (
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+type invoiceItem struct {
+	Name string
+}
+
+type invoiceUser struct {
+	Email string
+}
+
+type invoice struct {
+	Title string
+	Items []invoiceItem
+	User  *invoiceUser
+}
+
+func (invoice) Total() float64 { return 9.5 }
+
+func TestStrictData(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		StrictData:    true,
+		StripComments: true,
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("invoice", templatebox.TemplateSet{
+		Templates: []string{
+			`{{/* requires: Title string, Items []invoiceItem, User.Email, Total float64 */}}` +
+				`<h1>{{ .Title }}</h1>{{ range .Items }}<p>{{ .Name }}</p>{{ end }}<p>{{ .User.Email }} {{ .Total }}</p>`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	data := invoice{
+		Title: "Invoice",
+		Items: []invoiceItem{{Name: "Tea"}},
+		User:  &invoiceUser{Email: "ada@example.com"},
+	}
+	got, err := box.RenderString("invoice", data)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	expected := `<h1>Invoice</h1><p>Tea</p><p>ada@example.com 9.5</p>`
+	if got != expected {
+		t.Fatalf("RenderString returned %s, expected %s", got, expected)
+	}
+
+	data.User = nil
+	_, err = box.RenderString("invoice", data)
+	if err == nil || err.Error() != "template invoice: missing field User.Email" {
+		t.Fatalf("RenderString returned %v, expected a missing field error", err)
+	}
+
+	_, err = box.RenderString("invoice", map[string]any{
+		"Title": 7,
+		"User":  map[string]any{"Email": "ada@example.com"},
+		"Total": 9.5,
+	})
+	expected = "template invoice: field Title is int, expected string\ntemplate invoice: missing field Items"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("RenderString returned %v, expected %s", err, expected)
+	}
+}
+
+func TestRequiresOnlyInStrictMode(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("hello", templatebox.TemplateSet{
+		Templates: []string{`{{/* requires: Name string */}}Hello {{ .Name }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	got, err := box.RenderString("hello", map[string]any{})
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	if expected := "Hello "; got != expected {
+		t.Fatalf("RenderString returned %s, expected %s", got, expected)
+	}
+}
+
+func TestRequiresAfterReload(t *testing.T) {
+	path := t.TempDir()
+	writeTemplates(t, path, map[string]string{
+		"page.html": `{{/* requires: Title string */}}<h1>{{ .Title }}</h1>`,
+	})
+	box, err := templatebox.NewBoxFromOSDir(path, &templatebox.Config{StrictData: true})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	if err := box.AddTemplate("page", templatebox.FileSet{Filenames: []string{"page.html"}}); err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+	if _, err := box.RenderString("page", map[string]any{}); err == nil {
+		t.Fatalf("RenderString succeeded, expected a missing field error")
+	}
+
+	// a reload dropping the requires comment drops the requirement
+	writeTemplates(t, path, map[string]string{"page.html": `<h1>Welcome</h1>`})
+	if err := box.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	s, err := box.RenderString("page", map[string]any{})
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	if expected := "<h1>Welcome</h1>"; s != expected {
+		t.Fatalf("RenderString returned %s, expected %s", s, expected)
+	}
+}
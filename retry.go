@@ -0,0 +1,34 @@
+package templatebox
+
+import (
+	"errors"
+	"io/fs"
+	"time"
+)
+
+// readRoot reads the path from a source registered with AddSource,
+// retrying failures other than a missing file up to Config.SourceRetries
+// times with exponential backoff.
+func (b *Box) readRoot(fsys fs.FS, p string) ([]byte, error) {
+	delay := b.cfg.SourceRetryDelay
+	if delay <= 0 {
+		delay = 100 * time.Millisecond
+	}
+
+	for attempt := 0; ; attempt++ {
+		text, err := fs.ReadFile(fsys, p)
+		if err == nil || attempt >= b.cfg.SourceRetries || permanent(err) {
+			return text, err
+		}
+		b.logger().Debug("templatebox: retrying source read", "path", p, "attempt", attempt+1, "error", err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// permanent reports whether a read error will not go away by retrying.
+func permanent(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) ||
+		errors.Is(err, fs.ErrPermission) ||
+		errors.Is(err, fs.ErrInvalid)
+}
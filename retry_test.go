@@ -0,0 +1,72 @@
+package templatebox_test
+
+import (
+	"errors"
+	"io/fs"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+// flakyFS is an fs.FS whose Open fails while failures is positive.
+type flakyFS struct {
+	fstest.MapFS
+
+	mu       sync.Mutex
+	failures int
+}
+
+func (f *flakyFS) Open(name string) (fs.File, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return nil, errors.New("connection reset")
+	}
+	return f.MapFS.Open(name)
+}
+
+func (f *flakyFS) fail(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = n
+}
+
+func TestSourceRetries(t *testing.T) {
+	remote := &flakyFS{MapFS: fstest.MapFS{
+		"page.html": {Data: []byte(`remote page`)},
+	}}
+
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		Debug:            true,
+		SourceRetries:    2,
+		SourceRetryDelay: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	if err := box.AddSource("remote", remote); err != nil {
+		t.Fatalf("AddSource failed: %v", err)
+	}
+
+	// two failures are absorbed by the retries
+	remote.fail(2)
+	err = box.AddTemplate("page", templatebox.FileSet{Filenames: []string{"remote:page.html"}})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	// a rebuild that cannot read the source renders the previous template
+	remote.fail(100)
+	s, err := box.RenderString("page", nil)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	expected := "remote page"
+	if s != expected {
+		t.Fatalf("RenderString returned %s, expected %s", s, expected)
+	}
+}
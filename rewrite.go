@@ -0,0 +1,105 @@
+package templatebox
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+// SourceRewriter rewrites the text of a template before it is parsed. The
+// name is the FileSet filename for files and the template name for
+// template strings added with AddTemplateRaw.
+type SourceRewriter func(name string, src []byte) ([]byte, error)
+
+// AddSourceRewriter adds a SourceRewriter applied to every template source
+// before it is parsed, after any rewriters added earlier. Rewriters let a
+// large migration shim deprecated function names or old delimiters without
+// touching every file at once:
+//
+//	box.AddSourceRewriter(func(name string, src []byte) ([]byte, error) {
+//		return bytes.ReplaceAll(src, []byte("{{ oldFormat "), []byte("{{ format ")), nil
+//	})
+//
+// Rewriters apply to templates added afterwards, and to debug rebuilds and
+// Reload of every template. Template hashes and the analysis functions see
+// the rewritten sources.
+func (b *Box) AddSourceRewriter(fn SourceRewriter) {
+	b.rewriters = append(b.rewriters, fn)
+}
+
+// rewrite applies the builtin rewriters enabled by the Config and the
+// source rewriters to src: comments are stripped first, then the added
+// rewriters run in order, and the banner is injected last into sources
+// parsed with html/template, the only engine defining its function.
+func (b *Box) rewrite(name string, src []byte, engine Engine) ([]byte, error) {
+	if b.cfg.StripComments {
+		src = stripComments(src)
+	}
+	for _, fn := range b.rewriters {
+		var err error
+		if src, err = fn(name, src); err != nil {
+			return nil, fmt.Errorf("rewrite %s failed: %w", name, err)
+		}
+	}
+	if b.cfg.Banner != "" && isHTMLEngine(engine) {
+		src = injectBanner(src)
+	}
+	return src, nil
+}
+
+var (
+	// templateComment matches {{/* */}} comments without trim markers,
+	// which can be removed without changing the output.
+	templateComment = regexp.MustCompile(`(?s)\{\{/\*.*?\*/\}\}|\{\{ /\*.*?\*/ \}\}`)
+
+	htmlComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+	doctype = regexp.MustCompile(`(?i)<!doctype[^>]*>`)
+)
+
+// stripComments removes template comments and HTML comments from src.
+// html/template already drops HTML comments from its output, but other
+// engines do not.
+// Template comments with trim markers, such as {{- /* x */ -}}, are kept
+// because removing them would change the whitespace of the output, as are
+// requires comments, which declare the data of the template.
+func stripComments(src []byte) []byte {
+	src = templateComment.ReplaceAllFunc(src, func(c []byte) []byte {
+		if requiresComment.Match(c) {
+			return c
+		}
+		return nil
+	})
+	return htmlComment.ReplaceAll(src, nil)
+}
+
+// bannerFuncName is the builtin function injected into page templates to
+// output Config.Banner. html/template drops HTML comments found in template
+// text, so the comment is returned by a function instead.
+const bannerFuncName = "_templatebox_banner"
+
+// bannerComment implements the banner function.
+func (b *Box) bannerComment() template.HTML {
+	// "--" cannot appear inside an HTML comment
+	text := strings.ReplaceAll(b.cfg.Banner, "--", "- -")
+	return template.HTML("<!-- " + html.EscapeString(text) + " -->")
+}
+
+// injectBanner inserts a call to the banner function after the doctype of
+// src. Sources without a doctype, such as partials and fragments, are
+// returned unchanged so that the banner appears once per page.
+func injectBanner(src []byte) []byte {
+	loc := doctype.FindIndex(src)
+	if loc == nil {
+		return src
+	}
+
+	var buf bytes.Buffer
+	buf.Write(src[:loc[1]])
+	buf.WriteString("\n{{ " + bannerFuncName + " }}")
+	buf.Write(src[loc[1]:])
+	return buf.Bytes()
+}
@@ -0,0 +1,105 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestAddSourceRewriter(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplates(t, dir, map[string]string{
+		"old.html": `[[ shout .Name ]]`,
+	})
+
+	box, err := templatebox.NewBoxFromOSDir(dir, nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetGlobalFuncMap(templatebox.FuncMap{"upper": strings.ToUpper})
+
+	// shim old delimiters and a renamed function
+	box.AddSourceRewriter(func(name string, src []byte) ([]byte, error) {
+		src = bytes.ReplaceAll(src, []byte("[["), []byte("{{"))
+		return bytes.ReplaceAll(src, []byte("]]"), []byte("}}")), nil
+	})
+	box.AddSourceRewriter(func(name string, src []byte) ([]byte, error) {
+		return bytes.ReplaceAll(src, []byte("{{ shout "), []byte("{{ upper ")), nil
+	})
+
+	if err := box.AddTemplate("old", templatebox.FileSet{Filenames: []string{"old.html"}}); err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+	err = box.AddTemplateRaw("raw", templatebox.TemplateSet{Templates: []string{`[[ shout "raw" ]]`}})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	for name, expected := range map[string]string{"old": "ANN", "raw": "RAW"} {
+		var buf bytes.Buffer
+		if err := box.RenderHTML(&buf, name, map[string]string{"Name": "ann"}); err != nil {
+			t.Fatalf("RenderHTML %s failed: %v", name, err)
+		}
+		if buf.String() != expected {
+			t.Fatalf("RenderHTML %s returned %s, expected %s", name, buf.String(), expected)
+		}
+	}
+}
+
+func TestStripCommentsAndBanner(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		StripComments: true,
+		Banner:        "generated by shop -- v1",
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("page", templatebox.TemplateSet{
+		Templates: []string{
+			"<!DOCTYPE html><p>{{/* note */}}a<!-- todo -->b{{- /* kept */ -}} c</p>",
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "page", nil); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	expected := "<!DOCTYPE html>\n<!-- generated by shop - - v1 --><p>abc</p>"
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %q, expected %q", buf.String(), expected)
+	}
+}
+
+func TestBannerNotInjectedForOtherEngines(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		Banner: "generated by shop v1",
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetGlobalFuncMap(templatebox.FuncMap{"upper": strings.ToUpper})
+
+	err = box.AddTemplateRaw("page", templatebox.TemplateSet{
+		Templates: []string{"<!DOCTYPE html><p>Hello ${name}</p>"},
+		Engine:    replaceEngine{},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "page", map[string]string{"name": "ann"}); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+	expected := "<!DOCTYPE html><p>Hello ANN</p>"
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %q, expected %q", buf.String(), expected)
+	}
+}
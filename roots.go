@@ -0,0 +1,54 @@
+package templatebox
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// AddSource registers fsys as an additional template root under the given
+// name, so that one Box can serve templates from several places, such as
+// the application's templates and those of a vendored library, without
+// merging them on disk. FileSet filenames of the form "name:path" are read
+// from fsys, for example "emails:welcome.html", and may be mixed with
+// filenames relative to the template directory in the same FileSet.
+//
+// Use os.DirFS for a directory on disk; its files are read afresh on every
+// debug rebuild and Reload. Registering a name again replaces the previous
+// fs.FS.
+func (b *Box) AddSource(name string, fsys fs.FS) error {
+	if name == "" || strings.Contains(name, ":") {
+		return fmt.Errorf("invalid source name %q", name)
+	}
+	if fsys == nil {
+		return fmt.Errorf("source %s: fs.FS cannot be nil", name)
+	}
+
+	b.muRoots.Lock()
+	defer b.muRoots.Unlock()
+
+	if b.roots == nil {
+		b.roots = make(map[string]fs.FS)
+	}
+	b.roots[name] = fsys
+	return nil
+}
+
+// root returns the registered fs.FS and the cleaned, slash separated path
+// within it for a filename of the form "name:path". It returns false if the
+// filename has no prefix naming a registered source.
+func (b *Box) root(filename string) (fs.FS, string, bool) {
+	i := strings.Index(filename, ":")
+	if i <= 0 {
+		return nil, "", false
+	}
+
+	b.muRoots.RLock()
+	fsys, ok := b.roots[filename[:i]]
+	b.muRoots.RUnlock()
+	if !ok {
+		return nil, "", false
+	}
+	return fsys, path.Clean(slashPath(filename[i+1:])), true
+}
@@ -0,0 +1,53 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestAddSource(t *testing.T) {
+	emails := t.TempDir()
+	writeTemplates(t, emails, map[string]string{
+		"welcome.html": `{{ define "content" }}Welcome {{ .Name }}{{ end }}`,
+	})
+	vendor := fstest.MapFS{
+		"layouts/mail.html": {Data: []byte(`<body>{{ template "content" . }}</body>`)},
+	}
+
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	if err := box.AddSource("emails", os.DirFS(emails)); err != nil {
+		t.Fatalf("AddSource failed: %v", err)
+	}
+	if err := box.AddSource("vendor", vendor); err != nil {
+		t.Fatalf("AddSource failed: %v", err)
+	}
+
+	err = box.AddTemplate("welcome", templatebox.FileSet{
+		Filenames: []string{"vendor:layouts/mail.html", "emails:welcome.html"},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "welcome", map[string]string{"Name": "Ann"}); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+	expected := "<body>Welcome Ann</body>"
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+
+	err = box.AddTemplate("bad", templatebox.FileSet{Filenames: []string{"emails:../secret.html"}})
+	if !errors.Is(err, templatebox.ErrInvalidPath) {
+		t.Fatalf("AddTemplate returned %v, expected ErrInvalidPath", err)
+	}
+}
@@ -0,0 +1,38 @@
+package templatebox
+
+import (
+	"fmt"
+	"html/template"
+)
+
+// Sanitizer cleans untrusted HTML, such as user-generated content, so that
+// the result is safe to render without further escaping. Implementations
+// decide which elements and attributes are allowed.
+type Sanitizer interface {
+	Sanitize(html string) string
+}
+
+// SanitizerFunc is an adapter to allow the use of an ordinary function as a
+// Sanitizer.
+type SanitizerFunc func(html string) string
+
+// Sanitize calls f(html).
+func (f SanitizerFunc) Sanitize(html string) string {
+	return f(html)
+}
+
+// SetSanitizer sets the Sanitizer used by the sanitize template function.
+// Until a Sanitizer is set any call to sanitize fails at render time, so
+// user-generated HTML is never passed through unsanitized by accident.
+func (b *Box) SetSanitizer(s Sanitizer) {
+	b.sanitizer = s
+}
+
+// sanitize implements the sanitize template function. It runs s through
+// the configured Sanitizer and marks the result as safe HTML.
+func (b *Box) sanitize(s string) (template.HTML, error) {
+	if b.sanitizer == nil {
+		return "", fmt.Errorf("sanitize called but no Sanitizer is set")
+	}
+	return template.HTML(b.sanitizer.Sanitize(s)), nil
+}
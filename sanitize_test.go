@@ -0,0 +1,89 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestSanitizeFunc(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	box.SetSanitizer(templatebox.SanitizerFunc(func(s string) string {
+		return strings.ReplaceAll(s, "<script>alert(1)</script>", "")
+	}))
+
+	err = box.AddTemplateRaw("bio", templatebox.TemplateSet{
+		Templates: []string{`<div>{{ sanitize .Bio }}</div>`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	data := map[string]string{"Bio": "<b>hi</b><script>alert(1)</script>"}
+	if err := box.RenderHTML(&buf, "bio", data); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	expected := "<div><b>hi</b></div>"
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+}
+
+func TestSanitizeFuncWithoutSanitizer(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("bio", templatebox.TemplateSet{
+		Templates: []string{`<div>{{ sanitize .Bio }}</div>`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	data := map[string]string{"Bio": "<b>hi</b>"}
+	if err := box.RenderHTML(&buf, "bio", data); err == nil {
+		t.Fatalf("RenderHTML succeeded, expected an error when no Sanitizer is set")
+	}
+}
+
+func TestMarkdownFunc(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	box.SetMarkdownConverter(templatebox.MarkdownConverterFunc(func(s string) (string, error) {
+		return "<p>" + strings.TrimPrefix(s, "*") + "</p><script>x()</script>", nil
+	}))
+	box.SetSanitizer(templatebox.SanitizerFunc(func(s string) string {
+		return strings.ReplaceAll(s, "<script>x()</script>", "")
+	}))
+
+	err = box.AddTemplateRaw("comment", templatebox.TemplateSet{
+		Templates: []string{`<article>{{ markdown .Body }}</article>`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "comment", map[string]string{"Body": "*hello"}); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	expected := "<article><p>hello</p></article>"
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+}
@@ -0,0 +1,367 @@
+package templatebox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SetSchema attaches a JSON Schema to the named template. In debug mode, or
+// always with Config.StrictData, the data of every render of the template is
+// marshalled to JSON and validated against the schema first, and the render
+// fails with an error listing each violation. This suits templates such as
+// payment documents rendered from data that arrives from other systems.
+//
+// The schema keywords supported are type, enum, const, properties,
+// required, additionalProperties, items, minItems, maxItems, minLength,
+// maxLength, pattern, minimum, maximum, exclusiveMinimum and
+// exclusiveMaximum, as well as annotations such as title and description.
+// SetSchema returns an error for a schema using any other keyword, such as
+// $ref or oneOf, rather than silently ignoring it. A nil schema removes the
+// schema of the template.
+func (b *Box) SetSchema(name string, schema []byte) error {
+	b.muSchemas.Lock()
+	defer b.muSchemas.Unlock()
+	if schema == nil {
+		delete(b.schemas, name)
+		return nil
+	}
+
+	var v any
+	if err := json.Unmarshal(schema, &v); err != nil {
+		return fmt.Errorf("schema for %s: %w", name, err)
+	}
+	s, err := compileSchema(v, "")
+	if err != nil {
+		return fmt.Errorf("schema for %s: %w", name, err)
+	}
+	b.schemas[name] = s
+	return nil
+}
+
+// checkSchema validates data against the schema of the named template, if
+// it has one.
+func (b *Box) checkSchema(name string, data any) error {
+	b.muSchemas.RLock()
+	s, ok := b.schemas[name]
+	b.muSchemas.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	j, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("template %s: marshal data for schema: %w", name, err)
+	}
+	var v any
+	if err := json.Unmarshal(j, &v); err != nil {
+		return fmt.Errorf("template %s: unmarshal data for schema: %w", name, err)
+	}
+
+	var errs []error
+	s.validate(v, "", func(ptr, msg string) {
+		if ptr == "" {
+			ptr = "/"
+		}
+		errs = append(errs, fmt.Errorf("template %s: data %s: %s", name, ptr, msg))
+	})
+	return errors.Join(errs...)
+}
+
+// jsonSchema is a compiled JSON Schema.
+type jsonSchema struct {
+	types      []string
+	enum       []any
+	constant   any
+	hasConst   bool
+	properties map[string]*jsonSchema
+	required   []string
+	additional *jsonSchema // nil allows any additional property
+	noMore     bool        // additionalProperties is false
+	items      *jsonSchema
+
+	minItems, maxItems, minLength, maxLength     *int
+	minimum, maximum, exclusiveMin, exclusiveMax *float64
+	pattern                                      *regexp.Regexp
+}
+
+// schemaAnnotations are the keywords that do not affect validation.
+var schemaAnnotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true,
+	"description": true, "default": true, "examples": true, "format": true,
+	"deprecated": true, "readOnly": true, "writeOnly": true,
+}
+
+// compileSchema compiles the decoded JSON Schema v found at the JSON pointer
+// ptr of the schema document.
+func compileSchema(v any, ptr string) (*jsonSchema, error) {
+	if b, ok := v.(bool); ok {
+		if b {
+			return &jsonSchema{}, nil
+		}
+		// false matches nothing: an empty enum
+		return &jsonSchema{enum: []any{}}, nil
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: schema must be an object or boolean", schemaPtr(ptr))
+	}
+
+	s := &jsonSchema{}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		val := m[k]
+		kptr := ptr + "/" + k
+		var err error
+		switch k {
+		case "type":
+			switch t := val.(type) {
+			case string:
+				s.types = []string{t}
+			case []any:
+				for _, e := range t {
+					name, ok := e.(string)
+					if !ok {
+						return nil, fmt.Errorf("%s: type must be a string or array of strings", kptr)
+					}
+					s.types = append(s.types, name)
+				}
+			default:
+				return nil, fmt.Errorf("%s: type must be a string or array of strings", kptr)
+			}
+		case "enum":
+			if s.enum, ok = val.([]any); !ok {
+				return nil, fmt.Errorf("%s: enum must be an array", kptr)
+			}
+		case "const":
+			s.constant, s.hasConst = val, true
+		case "properties":
+			props, ok := val.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: properties must be an object", kptr)
+			}
+			s.properties = make(map[string]*jsonSchema, len(props))
+			for name, p := range props {
+				if s.properties[name], err = compileSchema(p, kptr+"/"+name); err != nil {
+					return nil, err
+				}
+			}
+		case "required":
+			names, ok := val.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: required must be an array of strings", kptr)
+			}
+			for _, e := range names {
+				name, ok := e.(string)
+				if !ok {
+					return nil, fmt.Errorf("%s: required must be an array of strings", kptr)
+				}
+				s.required = append(s.required, name)
+			}
+		case "additionalProperties":
+			if b, ok := val.(bool); ok {
+				s.noMore = !b
+				continue
+			}
+			s.additional, err = compileSchema(val, kptr)
+		case "items":
+			s.items, err = compileSchema(val, kptr)
+		case "minItems":
+			s.minItems, err = schemaInt(val, kptr)
+		case "maxItems":
+			s.maxItems, err = schemaInt(val, kptr)
+		case "minLength":
+			s.minLength, err = schemaInt(val, kptr)
+		case "maxLength":
+			s.maxLength, err = schemaInt(val, kptr)
+		case "minimum":
+			s.minimum, err = schemaNumber(val, kptr)
+		case "maximum":
+			s.maximum, err = schemaNumber(val, kptr)
+		case "exclusiveMinimum":
+			s.exclusiveMin, err = schemaNumber(val, kptr)
+		case "exclusiveMaximum":
+			s.exclusiveMax, err = schemaNumber(val, kptr)
+		case "pattern":
+			p, ok := val.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: pattern must be a string", kptr)
+			}
+			if s.pattern, err = regexp.Compile(p); err != nil {
+				err = fmt.Errorf("%s: %w", kptr, err)
+			}
+		default:
+			if !schemaAnnotations[k] {
+				return nil, fmt.Errorf("%s: unsupported keyword %s", kptr, k)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// schemaPtr returns the JSON pointer ptr for use in messages.
+func schemaPtr(ptr string) string {
+	if ptr == "" {
+		return "/"
+	}
+	return ptr
+}
+
+// schemaNumber returns the number value of a schema keyword.
+func schemaNumber(v any, ptr string) (*float64, error) {
+	f, ok := v.(float64)
+	if !ok {
+		return nil, fmt.Errorf("%s: must be a number", ptr)
+	}
+	return &f, nil
+}
+
+// schemaInt returns the non-negative integer value of a schema keyword.
+func schemaInt(v any, ptr string) (*int, error) {
+	f, ok := v.(float64)
+	if !ok || f < 0 || f != math.Trunc(f) {
+		return nil, fmt.Errorf("%s: must be a non-negative integer", ptr)
+	}
+	n := int(f)
+	return &n, nil
+}
+
+// jsonType returns the JSON Schema type of the decoded JSON value v.
+// Numbers without a fractional part are integers.
+func jsonType(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	}
+	return "object"
+}
+
+// validate reports each violation of the schema by the decoded JSON value v
+// found at the JSON pointer ptr.
+func (s *jsonSchema) validate(v any, ptr string, report func(ptr, msg string)) {
+	if len(s.types) > 0 {
+		t := jsonType(v)
+		ok := false
+		for _, want := range s.types {
+			if want == t || want == "number" && t == "integer" {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			report(ptr, fmt.Sprintf("expected %s, got %s", strings.Join(s.types, " or "), t))
+			return
+		}
+	}
+	if s.enum != nil && !containsValue(s.enum, v) {
+		report(ptr, fmt.Sprintf("value %s is not one of the allowed values", jsonText(v)))
+	}
+	if s.hasConst && !reflect.DeepEqual(s.constant, v) {
+		report(ptr, fmt.Sprintf("expected %s, got %s", jsonText(s.constant), jsonText(v)))
+	}
+
+	switch v := v.(type) {
+	case float64:
+		if s.minimum != nil && v < *s.minimum {
+			report(ptr, fmt.Sprintf("%v is less than the minimum %v", v, *s.minimum))
+		}
+		if s.maximum != nil && v > *s.maximum {
+			report(ptr, fmt.Sprintf("%v is greater than the maximum %v", v, *s.maximum))
+		}
+		if s.exclusiveMin != nil && v <= *s.exclusiveMin {
+			report(ptr, fmt.Sprintf("%v must be greater than %v", v, *s.exclusiveMin))
+		}
+		if s.exclusiveMax != nil && v >= *s.exclusiveMax {
+			report(ptr, fmt.Sprintf("%v must be less than %v", v, *s.exclusiveMax))
+		}
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.minLength != nil && n < *s.minLength {
+			report(ptr, fmt.Sprintf("length %d is less than the minimum %d", n, *s.minLength))
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			report(ptr, fmt.Sprintf("length %d is greater than the maximum %d", n, *s.maxLength))
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			report(ptr, fmt.Sprintf("%q does not match the pattern %s", v, s.pattern))
+		}
+	case []any:
+		if s.minItems != nil && len(v) < *s.minItems {
+			report(ptr, fmt.Sprintf("%d items is less than the minimum %d", len(v), *s.minItems))
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			report(ptr, fmt.Sprintf("%d items is more than the maximum %d", len(v), *s.maxItems))
+		}
+		if s.items != nil {
+			for i, e := range v {
+				s.items.validate(e, ptr+"/"+strconv.Itoa(i), report)
+			}
+		}
+	case map[string]any:
+		for _, name := range s.required {
+			if _, ok := v[name]; !ok {
+				report(ptr, "missing required property "+name)
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			p := ptr + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+			switch sub, ok := s.properties[name]; {
+			case ok:
+				sub.validate(v[name], p, report)
+			case s.noMore:
+				report(ptr, "additional property "+name+" is not allowed")
+			case s.additional != nil:
+				s.additional.validate(v[name], p, report)
+			}
+		}
+	}
+}
+
+// containsValue reports whether the decoded JSON value v is in values.
+func containsValue(values []any, v any) bool {
+	for _, e := range values {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonText returns the decoded JSON value v as JSON text for messages.
+func jsonText(v any) string {
+	j, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(j)
+}
@@ -0,0 +1,87 @@
+package templatebox_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+const invoiceSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"title": "Invoice",
+	"type": "object",
+	"required": ["number", "lines"],
+	"additionalProperties": false,
+	"properties": {
+		"number": {"type": "string", "pattern": "^INV-[0-9]+$"},
+		"currency": {"enum": ["GBP", "EUR"]},
+		"lines": {
+			"type": "array",
+			"minItems": 1,
+			"items": {
+				"type": "object",
+				"required": ["amount"],
+				"properties": {
+					"description": {"type": "string", "maxLength": 10},
+					"amount": {"type": "number", "exclusiveMinimum": 0}
+				}
+			}
+		}
+	}
+}`
+
+func TestSetSchema(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{StrictData: true})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("invoice", templatebox.TemplateSet{
+		Templates: []string{`{{ .number }}:{{ range .lines }} {{ .amount }}{{ end }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	if err := box.SetSchema("invoice", []byte(invoiceSchema)); err != nil {
+		t.Fatalf("SetSchema failed: %v", err)
+	}
+
+	got, err := box.RenderString("invoice", map[string]any{
+		"number":   "INV-7",
+		"currency": "GBP",
+		"lines":    []map[string]any{{"description": "Tea", "amount": 2.5}},
+	})
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	if expected := "INV-7: 2.5"; got != expected {
+		t.Fatalf("RenderString returned %s, expected %s", got, expected)
+	}
+
+	_, err = box.RenderString("invoice", map[string]any{
+		"number":   "7",
+		"currency": "USD",
+		"lines":    []map[string]any{{"description": "Afternoon tea", "amount": 0}},
+		"notes":    "",
+	})
+	expected := strings.Join([]string{
+		`template invoice: data /currency: value "USD" is not one of the allowed values`,
+		`template invoice: data /lines/0/amount: 0 must be greater than 0`,
+		`template invoice: data /lines/0/description: length 13 is greater than the maximum 10`,
+		`template invoice: data /: additional property notes is not allowed`,
+		`template invoice: data /number: "7" does not match the pattern ^INV-[0-9]+$`,
+	}, "\n")
+	if err == nil || err.Error() != expected {
+		t.Fatalf("RenderString returned %v, expected %s", err, expected)
+	}
+
+	_, err = box.RenderString("invoice", map[string]any{"number": "INV-1", "lines": "none"})
+	if expected := "template invoice: data /lines: expected array, got string"; err == nil || err.Error() != expected {
+		t.Fatalf("RenderString returned %v, expected %s", err, expected)
+	}
+
+	err = box.SetSchema("invoice", []byte(`{"oneOf": [{"type": "string"}]}`))
+	if err == nil || !strings.Contains(err.Error(), "unsupported keyword oneOf") {
+		t.Fatalf("SetSchema returned %v, expected an unsupported keyword error", err)
+	}
+}
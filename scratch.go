@@ -0,0 +1,193 @@
+package templatebox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SetScratchDir makes the template strings of raw templates editable in
+// debug mode. The strings of every template added with AddTemplateRaw,
+// before and after the call, are written to dir/<name>/<index>.html, with
+// the name path escaped, and the template is rebuilt from the files on
+// every render when they change, so an inline template can be tweaked in
+// an editor and the change seen on the next page load. Edits are not
+// written back to the Go source; ScratchEdits reports them for copying
+// back. Rebuilds are recorded in the audit log as AuditRebuild. Adding a
+// template again overwrites its files. Templates whose names are not
+// usable as a directory name, such as "..", are not written and are
+// reported in the error. SetScratchDir has no effect outside debug mode,
+// and an empty dir turns it off.
+func (b *Box) SetScratchDir(dir string) error {
+	if dir != "" && !b.debug() {
+		b.logger().Warn("templatebox: the scratch directory has no effect outside debug mode", "dir", dir)
+		return nil
+	}
+
+	b.muFileSets.Lock()
+	b.scratchDir = dir
+	b.scratch = make(map[string][]string)
+	sets := make(map[string][]string)
+	if dir != "" {
+		for name, s := range b.rawSets {
+			if _, isFunc := b.rawFuncs[name]; !isFunc {
+				sets[name] = s.Templates
+			}
+		}
+	}
+	b.muFileSets.Unlock()
+
+	var errs []error
+	for _, name := range sortedKeys(sets) {
+		if err := b.writeScratch(name, sets[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ScratchEdit is a raw template whose files in the scratch directory have
+// been edited, reported by ScratchEdits.
+type ScratchEdit struct {
+	// Name is the name of the template.
+	Name string
+
+	// Templates are the edited template strings, in the order of the
+	// TemplateSet.
+	Templates []string
+
+	// Diff is the unified diff of the template strings the template was
+	// added with and the edited strings.
+	Diff string
+}
+
+// ScratchEdits returns the raw templates edited in the scratch directory
+// since they were added, ordered by name, so the edits can be copied back
+// to the Go source at the end of a session.
+func (b *Box) ScratchEdits() ([]ScratchEdit, error) {
+	b.muFileSets.RLock()
+	added := make(map[string][]string, len(b.scratch))
+	for name, templates := range b.scratch {
+		added[name] = templates
+	}
+	b.muFileSets.RUnlock()
+
+	var edits []ScratchEdit
+	for _, name := range sortedKeys(added) {
+		templates, err := b.readScratch(name, len(added[name]))
+		if err != nil {
+			return nil, err
+		}
+		var diff strings.Builder
+		for i, text := range templates {
+			file := url.PathEscape(name) + "/" + strconv.Itoa(i) + ".html"
+			diff.WriteString(unifiedDiff("a/"+file, "b/"+file, added[name][i], text))
+		}
+		if diff.Len() > 0 {
+			edits = append(edits, ScratchEdit{Name: name, Templates: templates, Diff: diff.String()})
+		}
+	}
+	return edits, nil
+}
+
+// scratchTemplate writes the template strings of the raw template to the
+// scratch directory, if set, logging failures as they do not fail
+// AddTemplateRaw.
+func (b *Box) scratchTemplate(name string, templates []string) {
+	b.muFileSets.RLock()
+	active := b.scratchDir != ""
+	b.muFileSets.RUnlock()
+	if !active {
+		return
+	}
+	if err := b.writeScratch(name, templates); err != nil {
+		b.logger().Error("templatebox: write scratch template failed", "template", name, "error", err)
+	}
+}
+
+// writeScratch replaces the files of the raw template in the scratch
+// directory with its template strings.
+func (b *Box) writeScratch(name string, templates []string) error {
+	b.muFileSets.Lock()
+	defer b.muFileSets.Unlock()
+
+	dir, err := scratchTemplateDir(b.scratchDir, name)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("scratch template %s: %w", name, err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("scratch template %s: %w", name, err)
+	}
+	for i, text := range templates {
+		if err := os.WriteFile(filepath.Join(dir, strconv.Itoa(i)+".html"), []byte(text), 0644); err != nil {
+			return fmt.Errorf("scratch template %s: %w", name, err)
+		}
+	}
+	b.scratch[name] = templates
+	return nil
+}
+
+// scratchTemplateDir returns the directory of the raw template in the
+// scratch directory. Names that do not escape to a single path element
+// below it, such as "", "." and "..", are rejected, as the directory is
+// removed when the template is written.
+func scratchTemplateDir(scratchDir, name string) (string, error) {
+	escaped := url.PathEscape(name)
+	if !fs.ValidPath(escaped) || escaped == "." {
+		return "", fmt.Errorf("scratch template %q: %w: %s", name, ErrInvalidPath, escaped)
+	}
+	return filepath.Join(scratchDir, escaped), nil
+}
+
+// readScratch reads the n template strings of the raw template from the
+// scratch directory.
+func (b *Box) readScratch(name string, n int) ([]string, error) {
+	b.muFileSets.RLock()
+	dir, err := scratchTemplateDir(b.scratchDir, name)
+	b.muFileSets.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make([]string, n)
+	for i := range templates {
+		text, err := os.ReadFile(filepath.Join(dir, strconv.Itoa(i)+".html"))
+		if err != nil {
+			return nil, fmt.Errorf("scratch template %s: %w", name, err)
+		}
+		templates[i] = string(text)
+	}
+	return templates, nil
+}
+
+// reloadScratch rebuilds the raw template from the scratch directory if its
+// files have changed.
+func (b *Box) reloadScratch(name string) error {
+	b.muFileSets.RLock()
+	s := b.rawSets[name]
+	b.muFileSets.RUnlock()
+
+	templates, err := b.readScratch(name, len(s.Templates))
+	if err != nil {
+		return err
+	}
+	if slices.Equal(templates, s.Templates) {
+		return nil
+	}
+	s.Templates = templates
+	if err := b.addRaw(name, s); err != nil {
+		return err
+	}
+	b.audit(context.Background(), AuditRebuild, name)
+	return nil
+}
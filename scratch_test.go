@@ -0,0 +1,119 @@
+package templatebox_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestScratchDir(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{Debug: true})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("pages/about", templatebox.TemplateSet{Templates: []string{
+		`<main>{{ template "content" . }}</main>`,
+		`{{ define "content" }}About{{ end }}`,
+	}})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := box.SetScratchDir(dir); err != nil {
+		t.Fatalf("SetScratchDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("footer", templatebox.TemplateSet{Templates: []string{`<footer></footer>`}})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	for _, file := range []string{"pages%2Fabout/0.html", "pages%2Fabout/1.html", "footer/0.html"} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Fatalf("Stat failed: %v", err)
+		}
+	}
+
+	edits, err := box.ScratchEdits()
+	if err != nil {
+		t.Fatalf("ScratchEdits failed: %v", err)
+	}
+	if len(edits) != 0 {
+		t.Fatalf("ScratchEdits returned %+v, expected no edits", edits)
+	}
+
+	// an edited file is used by the next render
+	edited := `{{ define "content" }}About us{{ end }}`
+	if err := os.WriteFile(filepath.Join(dir, "pages%2Fabout", "1.html"), []byte(edited), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	s, err := box.RenderString("pages/about", nil)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	expected := "<main>About us</main>"
+	if s != expected {
+		t.Fatalf("RenderString returned %s, expected %s", s, expected)
+	}
+
+	edits, err = box.ScratchEdits()
+	if err != nil {
+		t.Fatalf("ScratchEdits failed: %v", err)
+	}
+	if len(edits) != 1 || edits[0].Name != "pages/about" || edits[0].Templates[1] != edited {
+		t.Fatalf("ScratchEdits returned %+v, expected the edit of pages/about", edits)
+	}
+	if !strings.Contains(edits[0].Diff, `+{{ define "content" }}About us{{ end }}`) {
+		t.Fatalf("ScratchEdits returned diff %s, expected the edited line", edits[0].Diff)
+	}
+}
+
+func TestScratchDirOutsideDebug(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	dir := t.TempDir()
+	if err := box.SetScratchDir(dir); err != nil {
+		t.Fatalf("SetScratchDir failed: %v", err)
+	}
+	if err := box.AddTemplateRaw("footer", templatebox.TemplateSet{Templates: []string{`<footer></footer>`}}); err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("scratch directory has %d entries, expected none", len(entries))
+	}
+}
+
+func TestScratchDirInvalidNames(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{Debug: true})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	for _, name := range []string{"", ".", ".."} {
+		if err := box.AddTemplateRaw(name, templatebox.TemplateSet{Templates: []string{`x`}}); err != nil {
+			t.Fatalf("AddTemplateRaw failed: %v", err)
+		}
+	}
+
+	parent := t.TempDir()
+	keep := filepath.Join(parent, "keep.txt")
+	if err := os.WriteFile(keep, []byte("keep"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	dir := filepath.Join(parent, "scratch")
+	err = box.SetScratchDir(dir)
+	if err == nil || !errors.Is(err, templatebox.ErrInvalidPath) {
+		t.Fatalf("SetScratchDir returned %v, expected ErrInvalidPath", err)
+	}
+	if _, err := os.Stat(keep); err != nil {
+		t.Fatalf("file beside the scratch directory was removed: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package templatebox
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// shardCount is the number of shards of the maps written on every render,
+// so that renders of different templates rarely wait on the same lock.
+const shardCount = 32
+
+// shardIndex returns the shard of key, using the FNV-1a hash.
+func shardIndex(key string) int {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return int(h % shardCount)
+}
+
+// shardedMap is a map from string keys split into shards by key hash, each
+// with its own lock. The zero value is an empty map ready to use.
+type shardedMap[V any] struct {
+	shards [shardCount]mapShard[V]
+}
+
+type mapShard[V any] struct {
+	mu sync.RWMutex
+	m  map[string]V
+}
+
+// load returns the value stored under key.
+func (s *shardedMap[V]) load(key string) (V, bool) {
+	sh := &s.shards[shardIndex(key)]
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	v, ok := sh.m[key]
+	return v, ok
+}
+
+// store sets the value stored under key.
+func (s *shardedMap[V]) store(key string, v V) {
+	sh := &s.shards[shardIndex(key)]
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if sh.m == nil {
+		sh.m = make(map[string]V)
+	}
+	sh.m[key] = v
+}
+
+// ShardedLRUCache is an in-memory Cache split into shards by key hash, each
+// an LRUCache with its own lock, for applications rendering cached
+// templates at tens of thousands of renders per second. Entries are evicted
+// from the least recently used of their shard, so the eviction order is
+// approximately least recently used overall. It is the default Cache.
+type ShardedLRUCache struct {
+	shards [shardCount]*LRUCache
+}
+
+// NewShardedLRUCache returns a ShardedLRUCache holding about maxEntries
+// entries in total, spread evenly over its shards.
+func NewShardedLRUCache(maxEntries int) *ShardedLRUCache {
+	c := &ShardedLRUCache{}
+	per := (maxEntries + shardCount - 1) / shardCount
+	for i := range c.shards {
+		c.shards[i] = NewLRUCache(per)
+	}
+	return c
+}
+
+// Get returns the unexpired value stored under key.
+func (c *ShardedLRUCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return c.shards[shardIndex(key)].Get(ctx, key)
+}
+
+// Set stores value under key for ttl, evicting the least recently used
+// entry of the shard of key if it is full.
+func (c *ShardedLRUCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.shards[shardIndex(key)].Set(ctx, key, value, ttl)
+}
+
+// Delete removes key from the cache.
+func (c *ShardedLRUCache) Delete(ctx context.Context, key string) error {
+	return c.shards[shardIndex(key)].Delete(ctx, key)
+}
+
+// Len returns the number of entries in the cache, including expired
+// entries not yet removed.
+func (c *ShardedLRUCache) Len() int {
+	n := 0
+	for _, s := range c.shards {
+		n += s.Len()
+	}
+	return n
+}
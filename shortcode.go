@@ -0,0 +1,151 @@
+package templatebox
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Shortcode is the data a shortcode template is rendered with.
+type Shortcode struct {
+	Name string
+
+	// Args are the positional arguments of the shortcode, and Params its
+	// key="value" arguments.
+	Args   []string
+	Params map[string]string
+}
+
+// shortcodePattern matches a shortcode such as {{< youtube "id" >}}.
+var shortcodePattern = regexp.MustCompile(`\{\{<\s*(.*?)\s*>\}\}`)
+
+// AddShortcode registers a shortcode template that markdown expands in
+// place of {{< name args... >}} in its source, so content editors can embed
+// components such as videos without writing raw HTML:
+//
+//	box.AddShortcode("youtube", templatebox.TemplateSet{
+//	    Templates: []string{`<iframe src="https://www.youtube.com/embed/{{ index .Args 0 }}"></iframe>`},
+//	})
+//
+// Arguments are separated by spaces and may be quoted; key="value"
+// arguments are passed in Params. The template is rendered with a
+// Shortcode and its output is inserted after the markdown has been
+// converted and sanitized, so it is trusted like any other template. A
+// shortcode that is not registered, has invalid arguments or fails to
+// render is left in the markdown as text.
+func (b *Box) AddShortcode(name string, s TemplateSet) error {
+	if len(s.Templates) == 0 {
+		return fmt.Errorf("no templates provided")
+	}
+	if err := b.checkFuncMaps(s.FuncMap); err != nil {
+		return fmt.Errorf("add shortcode %s failed: %w", name, err)
+	}
+	p, err := b.parseRaw(name, s)
+	if err != nil {
+		return fmt.Errorf("add shortcode %s failed: %w", name, err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.shortcodes == nil {
+		b.shortcodes = make(map[string]Template)
+	}
+	b.shortcodes[name] = p.t
+	return nil
+}
+
+// extractShortcodes renders the shortcodes in src, returning src with each
+// replaced by a placeholder and the rendered output of each placeholder.
+// The source may be untrusted, such as a comment, so a shortcode that is
+// not registered, cannot be parsed or fails to render is left in place as
+// literal text rather than failing the render; render failures are logged.
+func (b *Box) extractShortcodes(src string) (string, map[string]string) {
+	if !strings.Contains(src, "{{<") {
+		return src, nil
+	}
+
+	rendered := make(map[string]string)
+	out := shortcodePattern.ReplaceAllStringFunc(src, func(m string) string {
+		sc, err := parseShortcode(shortcodePattern.FindStringSubmatch(m)[1])
+		if err != nil {
+			return m
+		}
+
+		b.mu.RLock()
+		t, ok := b.shortcodes[sc.Name]
+		b.mu.RUnlock()
+		if !ok {
+			return m
+		}
+
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, sc); err != nil {
+			b.logger().Warn("templatebox: render shortcode failed; leaving it as text",
+				"shortcode", sc.Name, "error", err)
+			return m
+		}
+		ph := fmt.Sprintf("TEMPLATEBOXSHORTCODE%dEND", len(rendered))
+		rendered[ph] = buf.String()
+		return ph
+	})
+	return out, rendered
+}
+
+// insertShortcodes replaces the placeholders in html with the rendered
+// shortcodes. A placeholder on a line of its own is wrapped in a paragraph
+// by markdown, which is removed along with it.
+func insertShortcodes(html string, rendered map[string]string) string {
+	for ph, out := range rendered {
+		html = strings.ReplaceAll(html, "<p>"+ph+"</p>", out)
+		html = strings.ReplaceAll(html, ph, out)
+	}
+	return html
+}
+
+// parseShortcode parses the inside of a shortcode: its name followed by
+// space separated arguments, each optionally quoted, and key=value params.
+func parseShortcode(s string) (Shortcode, error) {
+	var fields []string
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		end := strings.IndexAny(s, " \t\n")
+		if end < 0 {
+			end = len(s)
+		}
+		if q := strings.IndexByte(s, '"'); q >= 0 && q < end {
+			// the field contains a quoted string, which may contain spaces
+			rest, err := strconv.QuotedPrefix(s[q:])
+			if err != nil {
+				return Shortcode{}, fmt.Errorf("invalid shortcode arguments %q: %w", s, err)
+			}
+			end = q + len(rest)
+		}
+		fields = append(fields, s[:end])
+		s = s[end:]
+	}
+	if len(fields) == 0 {
+		return Shortcode{}, fmt.Errorf("shortcode without a name")
+	}
+
+	sc := Shortcode{Name: fields[0], Params: make(map[string]string)}
+	for _, f := range fields[1:] {
+		key, val, isParam := strings.Cut(f, "=")
+		if !isParam || strings.HasPrefix(f, `"`) {
+			key, val = "", f
+		}
+		if strings.HasPrefix(val, `"`) {
+			unquoted, err := strconv.Unquote(val)
+			if err != nil {
+				return Shortcode{}, fmt.Errorf("invalid shortcode argument %s: %w", f, err)
+			}
+			val = unquoted
+		}
+		if key == "" {
+			sc.Args = append(sc.Args, val)
+		} else {
+			sc.Params[key] = val
+		}
+	}
+	return sc, nil
+}
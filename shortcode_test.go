@@ -0,0 +1,91 @@
+package templatebox_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestShortcodes(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	// wrap each paragraph in <p> and strip iframes, as a real converter
+	// and sanitizer would
+	box.SetMarkdownConverter(templatebox.MarkdownConverterFunc(func(s string) (string, error) {
+		var out string
+		for _, p := range strings.Split(s, "\n\n") {
+			out += "<p>" + p + "</p>"
+		}
+		return out, nil
+	}))
+	box.SetSanitizer(templatebox.SanitizerFunc(func(s string) string {
+		return strings.ReplaceAll(s, "<iframe", "&lt;iframe")
+	}))
+
+	err = box.AddShortcode("youtube", templatebox.TemplateSet{
+		Templates: []string{`<iframe src="https://www.youtube.com/embed/{{ index .Args 0 }}" title="{{ .Params.title }}"></iframe>`},
+	})
+	if err != nil {
+		t.Fatalf("AddShortcode failed: %v", err)
+	}
+	err = box.AddTemplateRaw("post", templatebox.TemplateSet{
+		Templates: []string{`<article>{{ markdown . }}</article>`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	s, err := box.RenderString("post", "Watch this:\n\n{{< youtube \"abc 1\" title=\"A & B\" >}}\n\n<iframe src=\"evil\">")
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	expected := `<article><p>Watch this:</p><iframe src="https://www.youtube.com/embed/abc%201" title="A &amp; B"></iframe><p>&lt;iframe src="evil"></p></article>`
+	if s != expected {
+		t.Fatalf("RenderString returned %s, expected %s", s, expected)
+	}
+
+}
+
+func TestShortcodesInvalid(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetMarkdownConverter(templatebox.MarkdownConverterFunc(func(s string) (string, error) {
+		return "<p>" + s + "</p>", nil
+	}))
+	box.SetSanitizer(templatebox.SanitizerFunc(func(s string) string { return s }))
+
+	err = box.AddShortcode("youtube", templatebox.TemplateSet{
+		Templates: []string{`<iframe src="https://www.youtube.com/embed/{{ index .Args 0 }}"></iframe>`},
+	})
+	if err != nil {
+		t.Fatalf("AddShortcode failed: %v", err)
+	}
+	err = box.AddTemplateRaw("comment", templatebox.TemplateSet{
+		Templates: []string{`{{ markdown . }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	// unknown, badly quoted and failing shortcodes are left as text
+	tests := map[string]string{
+		`{{< vimeo 1 >}}`:                         `<p>{{< vimeo 1 >}}</p>`,
+		`{{< youtube "abc >}}`:                    `<p>{{< youtube "abc >}}</p>`,
+		`{{< youtube >}} and {{< youtube "x" >}}`: `<p>{{< youtube >}} and <iframe src="https://www.youtube.com/embed/x"></iframe></p>`,
+	}
+	for src, expected := range tests {
+		s, err := box.RenderString("comment", src)
+		if err != nil {
+			t.Fatalf("RenderString(%q) failed: %v", src, err)
+		}
+		if s != expected {
+			t.Fatalf("RenderString(%q) returned %s, expected %s", src, s, expected)
+		}
+	}
+}
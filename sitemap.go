@@ -0,0 +1,72 @@
+package templatebox
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// sitemapURLSet is the root element of a sitemap.xml file.
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// writeSitemap writes sitemap.xml to the output directory, listing the
+// pages with an HTML output file in the order given.
+func writeSitemap(opts SSGOptions) error {
+	base := strings.TrimSuffix(opts.BaseURL, "/")
+
+	var set sitemapURLSet
+	for _, p := range opts.Pages {
+		file, err := pageFile(p.Path)
+		if err != nil || !strings.HasSuffix(file, ".html") {
+			continue
+		}
+		u := sitemapURL{Loc: base + p.Path}
+		if !p.LastMod.IsZero() {
+			u.LastMod = p.LastMod.UTC().Format("2006-01-02")
+		}
+		set.URLs = append(set.URLs, u)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(set); err != nil {
+		return fmt.Errorf("encode sitemap: %w", err)
+	}
+	buf.WriteByte('\n')
+
+	if err := writeOutputFile(opts.Dir, "sitemap.xml", buf.Bytes()); err != nil {
+		return fmt.Errorf("write sitemap: %w", err)
+	}
+	return nil
+}
+
+// writeRobots writes robots.txt to the output directory.
+func writeRobots(opts SSGOptions) error {
+	var buf bytes.Buffer
+	buf.WriteString("User-agent: *\n")
+	if len(opts.RobotsDisallow) == 0 {
+		buf.WriteString("Disallow:\n")
+	}
+	for _, p := range opts.RobotsDisallow {
+		fmt.Fprintf(&buf, "Disallow: %s\n", p)
+	}
+	if opts.Sitemap {
+		fmt.Fprintf(&buf, "\nSitemap: %s/sitemap.xml\n", strings.TrimSuffix(opts.BaseURL, "/"))
+	}
+
+	if err := writeOutputFile(opts.Dir, "robots.txt", buf.Bytes()); err != nil {
+		return fmt.Errorf("write robots.txt: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package templatebox
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"text/template/parse"
+)
+
+// SlotInfo describes a slot of a layout: a template the layout executes by
+// name, such as {{ template "content" . }} or {{ block "sidebar" . }}.
+type SlotInfo struct {
+	// Name is the name of the slot.
+	Name string
+
+	// Default is true if the layout has default content for the slot, given
+	// with a block or define that is not empty. An empty block such as
+	// {{ block "content" . }}{{ end }} is a placeholder that must be filled.
+	Default bool
+
+	// FilledBy is what fills the slot: the file of a FileSet that defines
+	// it, the template string of a TemplateSet by index, such as
+	// "blog at index 1", or the template given to With for a Composition.
+	// It is empty if the slot is not filled.
+	FilledBy string
+}
+
+// Missing reports whether the slot is neither filled nor has default
+// content, so it renders as a blank region or fails to render.
+func (s SlotInfo) Missing() bool {
+	return !s.Default && s.FilledBy == ""
+}
+
+// Slots returns the slots of the layout of the named template, ordered by
+// name, and the page that fills each of them. The layout is the first
+// source of the template set and the pages are the rest. A page overrides
+// the default content of a slot by defining it; an empty definition keeps
+// the default, as with html/template.
+func (b *Box) Slots(name string) ([]SlotInfo, error) {
+	set, err := b.registeredSet(name)
+	if err != nil {
+		return nil, err
+	}
+	return setSlots(name, set.srcs, set.engine)
+}
+
+// setSlots returns the slots of the layout of a template set parsed from
+// srcs, filled by the other sources.
+func setSlots(name string, srcs []Source, engine Engine) ([]SlotInfo, error) {
+	if !isHTMLEngine(engine) {
+		return nil, fmt.Errorf("template %s: slots are only supported by html/template", name)
+	}
+	fills := make(map[string]string)
+	for i, src := range srcs[1:] {
+		trees, err := parseTrees([]Source{src})
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", name, err)
+		}
+		by := src.Filename
+		if by == "" {
+			by = fmt.Sprintf("%s at index %d", name, i+1)
+		}
+		for def, tree := range trees {
+			if !parse.IsEmptyTree(tree.Root) {
+				fills[def] = by
+			}
+		}
+	}
+	slots, err := layoutSlots(srcs[:1], fills)
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %w", name, err)
+	}
+	return slots, nil
+}
+
+// layoutSlots returns the slots of the layout parsed from srcs, ordered by
+// name, with fills mapping the name of each filled slot to what fills it.
+func layoutSlots(srcs []Source, fills map[string]string) ([]SlotInfo, error) {
+	trees, err := parseTrees(srcs)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var slots []SlotInfo
+	for _, tree := range sortedTrees(trees) {
+		walkNodes(tree.Root, func(n parse.Node) {
+			tn, ok := n.(*parse.TemplateNode)
+			if !ok || seen[tn.Name] {
+				return
+			}
+			seen[tn.Name] = true
+			def, ok := trees[tn.Name]
+			slots = append(slots, SlotInfo{
+				Name:     tn.Name,
+				Default:  ok && !parse.IsEmptyTree(def.Root),
+				FilledBy: fills[tn.Name],
+			})
+		})
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].Name < slots[j].Name })
+	return slots, nil
+}
+
+// checkSlots returns an error naming every missing slot of the layout of
+// a template set with Config.StrictSlots. A template set of one source is
+// not checked, as it is a page without a layout or a layout for Compose.
+func (b *Box) checkSlots(name string, srcs []Source, engine Engine) error {
+	if !b.cfg.StrictSlots || len(srcs) < 2 || !isHTMLEngine(engine) {
+		return nil
+	}
+	slots, err := setSlots(name, srcs, engine)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, s := range slots {
+		if s.Missing() {
+			errs = append(errs, fmt.Errorf("template %s: slot %s of %s is not filled and has no default", name, s.Name, srcs[0].Name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Slots returns the slots of the layout of the composition, ordered by
+// name, and the template given to With for each slot it fills.
+func (c *Composition) Slots() ([]SlotInfo, error) {
+	layout, err := c.box.registeredSet(c.layout)
+	if err != nil {
+		return nil, err
+	}
+	return c.slotInfos(layout.srcs)
+}
+
+// Validate returns an error if a slot of the layout is neither filled nor
+// has default content, or a slot given to With is not a slot of the layout
+// or is filled twice. The same checks are made when the composition is
+// first rendered; call Validate at startup to find the mistakes sooner.
+func (c *Composition) Validate() error {
+	layout, err := c.box.registeredSet(c.layout)
+	if err != nil {
+		return err
+	}
+	return c.validate(layout.srcs)
+}
+
+// slotInfos returns the slots of the layout parsed from srcs, filled by the
+// slots of the composition.
+func (c *Composition) slotInfos(srcs []Source) ([]SlotInfo, error) {
+	fills := make(map[string]string, len(c.slots))
+	for _, s := range c.slots {
+		fills[s.name] = s.template
+	}
+	slots, err := layoutSlots(srcs, fills)
+	if err != nil {
+		return nil, fmt.Errorf("compose %s: %w", c.layout, err)
+	}
+	return slots, nil
+}
+
+// validate implements Validate for the layout parsed from srcs.
+func (c *Composition) validate(srcs []Source) error {
+	slots, err := c.slotInfos(srcs)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	known := make(map[string]bool, len(slots))
+	for _, s := range slots {
+		known[s.Name] = true
+		if s.Missing() {
+			errs = append(errs, fmt.Errorf("compose %s: slot %s is not filled and has no default", c.layout, s.Name))
+		}
+	}
+	filled := make(map[string]bool, len(c.slots))
+	for _, s := range c.slots {
+		switch {
+		case !known[s.name]:
+			errs = append(errs, fmt.Errorf("compose %s: layout has no slot %s", c.layout, s.name))
+		case filled[s.name]:
+			errs = append(errs, fmt.Errorf("compose %s: slot %s is filled twice", c.layout, s.name))
+		}
+		filled[s.name] = true
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,73 @@
+package templatebox
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"text/template/parse"
+)
+
+// sourceFuncName is the builtin function called by the markers inserted by
+// annotateSources. It is not intended to be called from templates.
+const sourceFuncName = "_templatebox_source"
+
+// sourceComment implements the function called by source markers. It
+// returns an HTML comment naming the template and the file it was defined
+// in.
+func sourceComment(edge, name, file string) template.HTML {
+	clean := func(s string) string {
+		return strings.ReplaceAll(s, "--", "- -")
+	}
+	if file == "" || file == name {
+		return template.HTML(fmt.Sprintf("<!-- %s %q -->", edge, clean(name)))
+	}
+	return template.HTML(fmt.Sprintf("<!-- %s %q (%s) -->", edge, clean(name), clean(file)))
+}
+
+// annotate adds source comments to t if Config.SourceComments is enabled
+// in debug mode.
+func (b *Box) annotate(t *template.Template) error {
+	if !b.debug() || !b.cfg.SourceComments {
+		return nil
+	}
+	return annotateSources(t)
+}
+
+// annotateSources wraps the body of every template associated with t in
+// begin and end markers that render as HTML comments, so the rendered
+// output shows which template and file produced each region. It must be
+// called before t is executed for the first time.
+func annotateSources(t *template.Template) error {
+	for _, tmpl := range t.Templates() {
+		tree := tmpl.Tree
+		if tree == nil || tree.Root == nil || parse.IsEmptyTree(tree.Root) {
+			continue
+		}
+
+		begin, err := sourceMarker("begin", tmpl.Name(), tree.ParseName)
+		if err != nil {
+			return err
+		}
+		end, err := sourceMarker("end", tmpl.Name(), tree.ParseName)
+		if err != nil {
+			return err
+		}
+
+		nodes := make([]parse.Node, 0, len(tree.Root.Nodes)+2)
+		nodes = append(nodes, begin)
+		nodes = append(nodes, tree.Root.Nodes...)
+		nodes = append(nodes, end)
+		tree.Root.Nodes = nodes
+	}
+	return nil
+}
+
+// sourceMarker returns an action node calling the source function.
+func sourceMarker(edge, name, file string) (parse.Node, error) {
+	text := fmt.Sprintf("{{%s %q %q %q}}", sourceFuncName, edge, name, file)
+	trees, err := parse.Parse("marker", text, "", "", map[string]any{sourceFuncName: sourceComment})
+	if err != nil {
+		return nil, fmt.Errorf("source marker for %s failed: %w", name, err)
+	}
+	return trees["marker"].Root.Nodes[0], nil
+}
@@ -0,0 +1,70 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestSourceComments(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		Debug:          true,
+		SourceComments: true,
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplate("a", templatebox.FileSet{
+		Filenames: []string{"layout.html", "a.html"},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "a", nil); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	expected := `<!-- begin "layout.html" --><!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <title>Document</title>
+</head>
+<body>
+  <!-- begin "content" (a.html) --><h1>Page A</h1><!-- end "content" (a.html) -->
+</body>
+</html>
+<!-- end "layout.html" -->`
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+}
+
+func TestSourceCommentsRequireDebug(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		SourceComments: true,
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("hello", templatebox.TemplateSet{
+		Templates: []string{`Hello`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	s, err := box.RenderString("hello", nil)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	if s != "Hello" {
+		t.Fatalf("RenderString returned %s, expected %s", s, "Hello")
+	}
+}
@@ -0,0 +1,193 @@
+package templatebox
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Source is the text of a single template file or raw template string,
+// as passed to an Engine.
+type Source struct {
+	// Name is the name the text is parsed under: the base filename for
+	// files and the template name for raw templates.
+	Name string
+
+	// Filename is the filename from the FileSet, empty for raw templates.
+	Filename string
+
+	Text []byte
+}
+
+// ErrInvalidPath is returned, wrapped, when a FileSet filename is absolute
+// or escapes the template directory using "..".
+var ErrInvalidPath = errors.New("templatebox: invalid template path")
+
+// checkPath returns an error wrapping ErrInvalidPath if the filename is
+// absolute or refers outside the template directory, unless
+// Config.AllowOutsideDir is set. Filenames from configuration would
+// otherwise allow reading any file on the system.
+func (b *Box) checkPath(filename string) error {
+	if _, p, ok := b.root(filename); ok {
+		if !fs.ValidPath(p) {
+			return fmt.Errorf("%w: %s is outside its source", ErrInvalidPath, filename)
+		}
+		return nil
+	}
+	if b.cfg.AllowOutsideDir {
+		return nil
+	}
+
+	slashed := filepath.ToSlash(filename)
+	if b.fs != nil {
+		slashed = slashPath(filename)
+	}
+	switch clean := path.Clean(slashed); {
+	case filename == "":
+		return fmt.Errorf("%w: empty filename", ErrInvalidPath)
+	case filepath.IsAbs(filename) || path.IsAbs(slashed) || filepath.VolumeName(filename) != "":
+		return fmt.Errorf("%w: %s is absolute", ErrInvalidPath, filename)
+	case clean == ".." || strings.HasPrefix(clean, "../"):
+		return fmt.Errorf("%w: %s is outside the template directory", ErrInvalidPath, filename)
+	}
+	return nil
+}
+
+// readFile reads the filename, relative to the template directory, from
+// the Box filesystem.
+func (b *Box) readFile(filename string) ([]byte, error) {
+	if err := b.checkPath(filename); err != nil {
+		return nil, err
+	}
+	if b.files != nil {
+		return b.readArchiveFile(filename)
+	}
+	if fsys, p, ok := b.root(filename); ok {
+		return b.readRoot(fsys, p)
+	}
+
+	// in debug mode files in the overlay directory take precedence over
+	// the embedded copies
+	if b.overlayActive() {
+		text, err := os.ReadFile(filepath.Join(b.cfg.DevOverlayDir, filename))
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return text, err
+		}
+	}
+
+	// if b.fs is not nil we are using the embed.FS, which always uses
+	// slash separated paths, otherwise we are using the OS filesystem
+	if b.fs != nil {
+		return fs.ReadFile(b.fs, b.fsPath(filename))
+	}
+
+	// all templates filenames within the FileSet must be relative to the
+	// templateDir, unless Config.AllowOutsideDir permits absolute paths
+	if filepath.IsAbs(filename) {
+		return os.ReadFile(filename)
+	}
+	if b.templateDir != "" {
+		filename = filepath.Join(b.templateDir, filename)
+	}
+	return os.ReadFile(filename)
+}
+
+// slashPath returns p slash separated, treating both / and \ as
+// separators so that paths written for Windows work with an embed.FS on
+// every platform.
+func slashPath(p string) string {
+	return strings.ReplaceAll(filepath.ToSlash(p), `\`, "/")
+}
+
+// baseName returns the last element of the filename, the name a file is
+// parsed under.
+func (b *Box) baseName(filename string) string {
+	if _, p, ok := b.root(filename); ok {
+		return path.Base(p)
+	}
+	return path.Base(slashPath(filename))
+}
+
+// fsPath returns the path of the filename, relative to the template
+// directory, within the embed.FS.
+func (b *Box) fsPath(filename string) string {
+	return path.Join(slashPath(b.templateDir), slashPath(filename))
+}
+
+// overlayActive reports whether an embed.FS backed Box reads files from
+// Config.DevOverlayDir first.
+func (b *Box) overlayActive() bool {
+	return b.fs != nil && b.debug() && b.cfg.DevOverlayDir != ""
+}
+
+// fileSetSources reads the files of the FileSet.
+func (b *Box) fileSetSources(s FileSet) ([]Source, error) {
+	srcs := make([]Source, len(s.Filenames))
+	for i, filename := range s.Filenames {
+		text, err := b.readFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		if text, err = b.rewrite(filename, text, s.Engine); err != nil {
+			return nil, err
+		}
+		srcs[i] = Source{
+			Name:     b.baseName(filename),
+			Filename: filename,
+			Text:     text,
+		}
+	}
+	return srcs, nil
+}
+
+// rawSources returns the template strings of the TemplateSet added under
+// the given name, after the source rewriters.
+func (b *Box) rawSources(name string, s TemplateSet) ([]Source, error) {
+	srcs := make([]Source, len(s.Templates))
+	for i, text := range s.Templates {
+		rewritten, err := b.rewrite(name, []byte(text), s.Engine)
+		if err != nil {
+			return nil, err
+		}
+		srcs[i] = Source{Name: name, Text: rewritten}
+	}
+	return srcs, nil
+}
+
+// templateSources returns the sources of the named template, reading files
+// from the Box filesystem for templates added with AddTemplate.
+func (b *Box) templateSources(name string) ([]Source, error) {
+	b.muFileSets.RLock()
+	fset, isFile := b.fileSets[name]
+	rset, isRaw := b.rawSets[name]
+	b.muFileSets.RUnlock()
+
+	switch {
+	case isFile:
+		return b.fileSetSources(fset)
+	case isRaw:
+		return b.rawSources(name, rset)
+	}
+	return nil, fmt.Errorf("template %s not found", name)
+}
+
+// parseSources parses each source into t, in the same way as
+// template.ParseFiles: a source with the same name as t is parsed into t
+// itself and any other source into a new associated template of its name.
+func parseSources(t *template.Template, srcs []Source) (*template.Template, error) {
+	for _, src := range srcs {
+		tmpl := t
+		if src.Name != t.Name() {
+			tmpl = t.New(src.Name)
+		}
+		if _, err := tmpl.Parse(string(src.Text)); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,101 @@
+package templatebox
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// SSEWriter writes server-sent events whose data is a rendered template,
+// for streaming live-updating fragments to the browser. It is safe for
+// concurrent use.
+type SSEWriter struct {
+	box *Box
+	w   io.Writer
+
+	mu      sync.Mutex
+	started bool
+}
+
+// SSE returns an SSEWriter writing events to w, typically the
+// http.ResponseWriter of a request held open for the stream:
+//
+//	sse := box.SSE(w)
+//	for n := range notifications {
+//		if err := sse.Send("notification", n); err != nil {
+//			return
+//		}
+//	}
+//
+// If w is an http.ResponseWriter the event stream headers are set before
+// the first event is written, and every event is flushed.
+func (b *Box) SSE(w io.Writer) *SSEWriter {
+	return &SSEWriter{box: b, w: w}
+}
+
+// Send renders the template named after the event and writes it as an
+// event of that type. It is the same as SendEvent(event, event, data).
+func (s *SSEWriter) Send(event string, data any) error {
+	return s.SendEvent(event, event, data)
+}
+
+// SendEvent renders the named template and writes it as the data of an
+// event of the given type, with a data line for each line of the output.
+// An empty event type writes a message event. Nothing is written if the
+// render fails.
+func (s *SSEWriter) SendEvent(event, name string, data any) error {
+	if strings.ContainsAny(event, "\r\n") {
+		return fmt.Errorf("sse: event type %q contains a line break", event)
+	}
+
+	var out bytes.Buffer
+	if err := s.box.RenderHTML(&out, name, data); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", event)
+	}
+	text := strings.ReplaceAll(out.String(), "\r\n", "\n")
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r", "\n"), "\n") {
+		fmt.Fprintf(&buf, "data: %s\n", line)
+	}
+	buf.WriteByte('\n')
+	return s.write(buf.Bytes())
+}
+
+// Comment writes a comment line, which clients ignore, to keep an idle
+// connection open through proxies that close inactive connections.
+func (s *SSEWriter) Comment(text string) error {
+	return s.write([]byte(": " + strings.NewReplacer("\r", " ", "\n", " ").Replace(text) + "\n\n"))
+}
+
+// write writes a framed event, setting the stream headers first and
+// flushing afterwards when writing to an http.ResponseWriter.
+func (s *SSEWriter) write(p []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rw, isHTTP := s.w.(http.ResponseWriter)
+	if isHTTP && !s.started {
+		h := rw.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-cache")
+		h.Set("X-Accel-Buffering", "no")
+	}
+	s.started = true
+
+	if _, err := s.w.Write(p); err != nil {
+		return err
+	}
+	if isHTTP {
+		if err := http.NewResponseController(rw).Flush(); err != nil {
+			return fmt.Errorf("sse: flush: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package templatebox_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestSSE(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("notification", templatebox.TemplateSet{
+		Templates: []string{"<li>\n  {{ .Text }}\n</li>"},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	sse := box.SSE(rec)
+	if err := sse.Send("notification", map[string]string{"Text": "<Saved>"}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if err := sse.Comment("keep-alive"); err != nil {
+		t.Fatalf("Comment failed: %v", err)
+	}
+	if err := sse.SendEvent("", "notification", map[string]string{"Text": "Hi"}); err != nil {
+		t.Fatalf("SendEvent failed: %v", err)
+	}
+	if err := sse.Send("missing", nil); err == nil {
+		t.Fatalf("Send succeeded, expected an error for a missing template")
+	}
+
+	expected := "event: notification\ndata: <li>\ndata:   &lt;Saved&gt;\ndata: </li>\n\n" +
+		": keep-alive\n\n" +
+		"data: <li>\ndata:   Hi\ndata: </li>\n\n"
+	if rec.Body.String() != expected {
+		t.Fatalf("SSE wrote %q, expected %q", rec.Body.String(), expected)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type was %s, expected text/event-stream", ct)
+	}
+	if !rec.Flushed {
+		t.Fatalf("SSE did not flush the response")
+	}
+}
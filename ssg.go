@@ -0,0 +1,282 @@
+package templatebox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Page is a page of a static site built by RenderAll.
+type Page struct {
+	// Path is the URL path the page is served at, such as "/",
+	// "/blog/hello/" or "/feed.xml". Paths ending in a slash are written
+	// to an index.html file in the matching directory.
+	Path string
+
+	Template string
+	Data     any
+
+	// LastMod is the time the content of the page last changed, typically
+	// taken from the front matter of its content, used for the lastmod of
+	// its sitemap entry. It is omitted from the sitemap if zero.
+	LastMod time.Time
+
+	// Draft marks a page, typically one whose content has draft: true in
+	// its front matter, that is only built with SSGOptions.IncludeDrafts.
+	Draft bool
+}
+
+// SSGOptions configures a static site build by RenderAll.
+type SSGOptions struct {
+	// Dir is the output directory. Files for pages are created in it,
+	// along with any missing directories. Static assets copied into it
+	// beforehand satisfy the link check.
+	Dir string
+
+	Pages []Page
+
+	// CheckLinks collects the href and src URLs of the rendered pages and
+	// reports internal links that resolve to neither a generated page nor
+	// a file in Dir.
+	CheckLinks bool
+
+	// CheckExternalLinks also sends a HEAD request to every external
+	// http and https link, reporting those that fail or respond with an
+	// error status. It requires CheckLinks.
+	CheckExternalLinks bool
+
+	// Client is used for CheckExternalLinks. If nil http.DefaultClient is
+	// used.
+	Client *http.Client
+
+	// BaseURL is the absolute URL the site is served from, such as
+	// "https://example.com". It is required by Sitemap and Robots.
+	BaseURL string
+
+	// Sitemap writes a sitemap.xml listing every page with an HTML output
+	// file.
+	Sitemap bool
+
+	// Robots writes a robots.txt disallowing the RobotsDisallow paths for
+	// all user agents and pointing to the sitemap if Sitemap is set.
+	Robots         bool
+	RobotsDisallow []string
+
+	// DataDir is a directory of JSON, YAML and CSV data files, loaded with
+	// LoadData. When it is set each page is rendered with a PageData
+	// holding the data files as Site.Data and the Data of the Page as
+	// Data, so every template can use {{ .Site.Data.products }} alongside
+	// its own {{ .Data.Title }}.
+	DataDir string
+
+	// IncludeDrafts builds pages marked as Draft, for example for a
+	// staging build. Otherwise drafts are left out of the build, the
+	// sitemap and the link check, so links to them are reported broken.
+	IncludeDrafts bool
+
+	// Incremental skips rendering pages whose template sources and data
+	// are unchanged since the previous incremental build into Dir, using
+	// a manifest of fingerprints kept in Dir as .templatebox-build.json.
+	// The data of each page must be deterministic when encoded as JSON.
+	// Changes to FuncMaps and other settings of the Box are not detected,
+	// so do a full build after changing them.
+	Incremental bool
+}
+
+// Site describes the static site being built by RenderAll.
+type Site struct {
+	BaseURL string
+
+	// Data holds the files of SSGOptions.DataDir as loaded by LoadData.
+	Data map[string]any
+}
+
+// PageData is the data pages are rendered with by RenderAll when
+// SSGOptions.DataDir is set.
+type PageData struct {
+	Site *Site
+
+	// Path is the Path of the Page being rendered.
+	Path string
+
+	// Data is the Data of the Page being rendered.
+	Data any
+}
+
+// BuildReport is the result of a RenderAll build.
+type BuildReport struct {
+	// Files are the output files written, relative to Dir, in the order
+	// of the pages.
+	Files []string
+
+	// Drafts are the paths of the draft pages left out of the build.
+	Drafts []string
+
+	// Skipped are the output files of an incremental build left in place
+	// because the page is unchanged.
+	Skipped []string
+
+	// BrokenLinks are the links found by the link check that do not
+	// resolve.
+	BrokenLinks []BrokenLink
+}
+
+// BrokenLink is a link of a rendered page that does not resolve.
+type BrokenLink struct {
+	// Page is the Path of the page containing the link.
+	Page string
+
+	URL    string
+	Reason string
+}
+
+// RenderAll renders every page of a static site to its file in opts.Dir.
+// Pages are rendered concurrently as with RenderMany. If a page fails to
+// render or write, RenderAll returns an error listing every failure after
+// writing the pages that succeeded. Broken links are reported in the
+// BuildReport and do not make RenderAll fail.
+func (b *Box) RenderAll(ctx context.Context, opts SSGOptions) (*BuildReport, error) {
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("output directory cannot be empty")
+	}
+	if (opts.Sitemap || opts.Robots) && opts.BaseURL == "" {
+		return nil, fmt.Errorf("BaseURL is required for the sitemap and robots.txt")
+	}
+
+	report := &BuildReport{}
+	if !opts.IncludeDrafts {
+		var published []Page
+		for _, p := range opts.Pages {
+			if p.Draft {
+				report.Drafts = append(report.Drafts, p.Path)
+				continue
+			}
+			published = append(published, p)
+		}
+		opts.Pages = published
+	}
+
+	files := make([]string, len(opts.Pages))
+	for i, p := range opts.Pages {
+		file, err := pageFile(p.Path)
+		if err != nil {
+			return nil, err
+		}
+		files[i] = file
+	}
+
+	var site *Site
+	if opts.DataDir != "" {
+		data, err := LoadData(os.DirFS(opts.DataDir))
+		if err != nil {
+			return nil, err
+		}
+		site = &Site{BaseURL: opts.BaseURL, Data: data}
+	}
+
+	var m buildManifest
+	if opts.Incremental {
+		m = readBuildManifest(opts.Dir)
+	}
+
+	outputs := make(map[string][]byte)
+	var errs []error
+
+	// pages whose template and data are unchanged since the last
+	// incremental build are not rendered again
+	var jobs []RenderJob
+	var render []int
+	keys := make(buildManifest)
+	for i, p := range opts.Pages {
+		data := p.Data
+		if site != nil {
+			data = PageData{Site: site, Path: p.Path, Data: p.Data}
+		}
+		if opts.Incremental {
+			keys[files[i]] = b.pageKey(p.Template, data)
+			if out, ok := m.unchanged(opts.Dir, files[i], keys[files[i]]); ok {
+				report.Skipped = append(report.Skipped, files[i])
+				outputs[p.Path] = out
+				continue
+			}
+		}
+		jobs = append(jobs, RenderJob{Name: p.Template, Data: data})
+		render = append(render, i)
+	}
+
+	for j, r := range b.RenderMany(ctx, jobs) {
+		i := render[j]
+		p := opts.Pages[i]
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("render page %s: %w", p.Path, r.Err))
+			delete(keys, files[i])
+			continue
+		}
+		if err := writeOutputFile(opts.Dir, files[i], r.Output); err != nil {
+			errs = append(errs, fmt.Errorf("write page %s: %w", p.Path, err))
+			delete(keys, files[i])
+			continue
+		}
+		report.Files = append(report.Files, files[i])
+		outputs[p.Path] = r.Output
+	}
+	if opts.Incremental {
+		if err := writeBuildManifest(opts.Dir, keys); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return report, errors.Join(errs...)
+	}
+
+	if opts.Sitemap {
+		if err := writeSitemap(opts); err != nil {
+			return report, err
+		}
+		report.Files = append(report.Files, "sitemap.xml")
+	}
+	if opts.Robots {
+		if err := writeRobots(opts); err != nil {
+			return report, err
+		}
+		report.Files = append(report.Files, "robots.txt")
+	}
+
+	if opts.CheckLinks {
+		report.BrokenLinks = checkLinks(ctx, opts, outputs)
+	}
+	return report, nil
+}
+
+// pageFile returns the slash separated output file, relative to the output
+// directory, of the page served at the URL path p.
+func pageFile(p string) (string, error) {
+	if !strings.HasPrefix(p, "/") {
+		return "", fmt.Errorf("page path %q must start with /", p)
+	}
+	file := strings.TrimPrefix(path.Clean(p), "/")
+	if strings.HasSuffix(p, "/") {
+		file = path.Join(file, "index.html")
+	}
+	if !fs.ValidPath(file) {
+		return "", fmt.Errorf("invalid page path %q", p)
+	}
+	return file, nil
+}
+
+// writeOutputFile writes data to the slash separated file within dir,
+// creating its directory.
+func writeOutputFile(dir, file string, data []byte) error {
+	filename := filepath.Join(dir, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
@@ -0,0 +1,224 @@
+package templatebox_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestRenderAll(t *testing.T) {
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ok" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer external.Close()
+
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("page", templatebox.TemplateSet{
+		Templates: []string{`<h1>{{ .Title }}</h1>{{ range .Links }}<a href="{{ . }}">link</a>{{ end }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	writeTemplates(t, dir, map[string]string{"css/app.css": "body {}"})
+
+	type page struct {
+		Title string
+		Links []string
+	}
+	report, err := box.RenderAll(context.Background(), templatebox.SSGOptions{
+		Dir: dir,
+		Pages: []templatebox.Page{
+			{Path: "/", Template: "page", Data: page{
+				Title: "Home",
+				Links: []string{"/blog/hello/", "blog/missing/", "/css/app.css", "#top", "mailto:a@example.com"},
+			}},
+			{Path: "/blog/hello/", Template: "page", Data: page{
+				Title: "Hello",
+				Links: []string{"../../", "/blog/hello", external.URL + "/ok", external.URL + "/gone"},
+			}},
+		},
+		CheckLinks:         true,
+		CheckExternalLinks: true,
+	})
+	if err != nil {
+		t.Fatalf("RenderAll failed: %v", err)
+	}
+
+	expectedFiles := []string{"index.html", "blog/hello/index.html"}
+	if !reflect.DeepEqual(report.Files, expectedFiles) {
+		t.Fatalf("RenderAll wrote %v, expected %v", report.Files, expectedFiles)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "blog", "hello", "index.html"))
+	if err != nil {
+		t.Fatalf("os.ReadFile failed: %v", err)
+	}
+	expected := `<h1>Hello</h1>`
+	if string(b[:len(expected)]) != expected {
+		t.Fatalf("RenderAll wrote %s, expected it to start with %s", b, expected)
+	}
+
+	expectedLinks := []templatebox.BrokenLink{
+		{Page: "/", URL: "blog/missing/", Reason: "/blog/missing/ not found"},
+		{Page: "/blog/hello/", URL: external.URL + "/gone", Reason: "404 Not Found"},
+	}
+	if !reflect.DeepEqual(report.BrokenLinks, expectedLinks) {
+		t.Fatalf("RenderAll reported %+v, expected %+v", report.BrokenLinks, expectedLinks)
+	}
+}
+
+func TestRenderAllSitemap(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("page", templatebox.TemplateSet{
+		Templates: []string{`<h1>{{ . }}</h1>`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	_, err = box.RenderAll(context.Background(), templatebox.SSGOptions{
+		Dir: dir,
+		Pages: []templatebox.Page{
+			{Path: "/", Template: "page", Data: "Home"},
+			{Path: "/about/", Template: "page", Data: "About", LastMod: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+			{Path: "/feed.xml", Template: "page", Data: "Feed"},
+		},
+		BaseURL:        "https://example.com/",
+		Sitemap:        true,
+		Robots:         true,
+		RobotsDisallow: []string{"/drafts/"},
+	})
+	if err != nil {
+		t.Fatalf("RenderAll failed: %v", err)
+	}
+
+	sitemap, err := os.ReadFile(filepath.Join(dir, "sitemap.xml"))
+	if err != nil {
+		t.Fatalf("os.ReadFile failed: %v", err)
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://example.com/</loc>
+  </url>
+  <url>
+    <loc>https://example.com/about/</loc>
+    <lastmod>2024-05-01</lastmod>
+  </url>
+</urlset>
+`
+	if string(sitemap) != expected {
+		t.Fatalf("sitemap.xml is %s, expected %s", sitemap, expected)
+	}
+
+	robots, err := os.ReadFile(filepath.Join(dir, "robots.txt"))
+	if err != nil {
+		t.Fatalf("os.ReadFile failed: %v", err)
+	}
+	expected = "User-agent: *\nDisallow: /drafts/\n\nSitemap: https://example.com/sitemap.xml\n"
+	if string(robots) != expected {
+		t.Fatalf("robots.txt is %s, expected %s", robots, expected)
+	}
+}
+
+func TestRenderAllIncremental(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	addPage := func(src string) {
+		err := box.AddTemplateRaw("page", templatebox.TemplateSet{Templates: []string{src}})
+		if err != nil {
+			t.Fatalf("AddTemplateRaw failed: %v", err)
+		}
+	}
+	addPage(`<h1>{{ . }}</h1>`)
+
+	dir := t.TempDir()
+	build := func(about string) *templatebox.BuildReport {
+		report, err := box.RenderAll(context.Background(), templatebox.SSGOptions{
+			Dir: dir,
+			Pages: []templatebox.Page{
+				{Path: "/", Template: "page", Data: "Home"},
+				{Path: "/about/", Template: "page", Data: about},
+			},
+			Incremental: true,
+		})
+		if err != nil {
+			t.Fatalf("RenderAll failed: %v", err)
+		}
+		return report
+	}
+
+	report := build("About")
+	if len(report.Files) != 2 || len(report.Skipped) != 0 {
+		t.Fatalf("first build wrote %v and skipped %v, expected every page written", report.Files, report.Skipped)
+	}
+
+	// only the page whose data changed is rendered
+	report = build("About us")
+	expected := []string{"about/index.html"}
+	if !reflect.DeepEqual(report.Files, expected) {
+		t.Fatalf("RenderAll wrote %v, expected %v", report.Files, expected)
+	}
+
+	// a template change renders every page using it
+	addPage(`<h2>{{ . }}</h2>`)
+	report = build("About us")
+	if len(report.Files) != 2 {
+		t.Fatalf("RenderAll wrote %v after a template change, expected every page", report.Files)
+	}
+}
+
+func TestRenderAllDrafts(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("page", templatebox.TemplateSet{
+		Templates: []string{`<h1>{{ . }}</h1>`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	pages := []templatebox.Page{
+		{Path: "/", Template: "page", Data: "Home"},
+		{Path: "/next/", Template: "page", Data: "Coming soon", Draft: true},
+	}
+	for _, includeDrafts := range []bool{false, true} {
+		report, err := box.RenderAll(context.Background(), templatebox.SSGOptions{
+			Dir:           t.TempDir(),
+			Pages:         pages,
+			IncludeDrafts: includeDrafts,
+		})
+		if err != nil {
+			t.Fatalf("RenderAll failed: %v", err)
+		}
+
+		expected := []string{"index.html"}
+		if includeDrafts {
+			expected = append(expected, "next/index.html")
+		}
+		if !reflect.DeepEqual(report.Files, expected) {
+			t.Fatalf("RenderAll with IncludeDrafts %t wrote %v, expected %v", includeDrafts, report.Files, expected)
+		}
+	}
+}
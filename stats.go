@@ -0,0 +1,40 @@
+package templatebox
+
+import (
+	"io"
+	"time"
+)
+
+// RenderStats describes a single render.
+type RenderStats struct {
+	// Bytes is the number of bytes written to the io.Writer, including
+	// any output written before a failure.
+	Bytes int64
+
+	// Duration is the time taken to look up and render the template and
+	// write the output.
+	Duration time.Duration
+}
+
+// RenderHTMLStats is like RenderHTML but also reports the number of bytes
+// written and the duration of the render, so access logs and metrics can
+// include the size of template generated responses without wrapping the
+// writer at every call site.
+func (b *Box) RenderHTMLStats(w io.Writer, name string, data any) (RenderStats, error) {
+	cw := &countingWriter{w: w}
+	start := time.Now()
+	err := b.RenderHTML(cw, name, data)
+	return RenderStats{Bytes: cw.n, Duration: time.Since(start)}, err
+}
+
+// countingWriter counts the bytes written to the underlying io.Writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
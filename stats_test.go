@@ -0,0 +1,35 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestRenderHTMLStats(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplate("a", templatebox.FileSet{
+		Filenames: []string{"layout.html", "a.html"},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	stats, err := box.RenderHTMLStats(&buf, "a", nil)
+	if err != nil {
+		t.Fatalf("RenderHTMLStats failed: %v", err)
+	}
+
+	if stats.Bytes != int64(buf.Len()) {
+		t.Fatalf("RenderHTMLStats reported %d bytes, expected %d", stats.Bytes, buf.Len())
+	}
+	if stats.Duration <= 0 {
+		t.Fatalf("RenderHTMLStats reported duration %v, expected a positive duration", stats.Duration)
+	}
+}
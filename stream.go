@@ -0,0 +1,104 @@
+package templatebox
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Stream renders a page progressively: the output up to the first value
+// that is still being fetched, typically the head of the layout, is sent
+// to the client straight away so the browser can start fetching CSS and
+// scripts, and the rest follows as the values resolve.
+//
+//	st := box.NewStream(w)
+//	data := ShopPage{
+//		Title:    "Shop",
+//		Products: st.Await(func() (any, error) { return db.Products(ctx) }),
+//	}
+//	err := st.Render(r.Context(), "shop", data)
+//
+// The template reads a value returned by Await with the await function,
+// for example {{ range await .Products }}, which flushes the output
+// written so far before waiting for the value.
+type Stream struct {
+	box *Box
+	w   io.Writer
+	bw  *bufio.Writer
+}
+
+// NewStream returns a Stream writing to w. If w is an http.ResponseWriter
+// it is flushed to the client along with the Stream's buffer.
+func (b *Box) NewStream(w io.Writer) *Stream {
+	return &Stream{box: b, w: w, bw: bufio.NewWriter(w)}
+}
+
+// Deferred is a value being fetched concurrently with the render of a
+// Stream, created by Stream.Await.
+type Deferred struct {
+	stream *Stream
+	done   chan struct{}
+	val    any
+	err    error
+}
+
+// Await starts fn in a new goroutine and returns a Deferred holding its
+// result, for use as render data of the Stream. Start every slow fetch
+// with Await before calling Render so they run concurrently.
+func (s *Stream) Await(fn func() (any, error)) *Deferred {
+	d := &Deferred{stream: s, done: make(chan struct{})}
+	go func() {
+		defer close(d.done)
+		d.val, d.err = fn()
+	}()
+	return d
+}
+
+// Render renders the named template to the Stream's writer, flushing
+// whenever the template waits for a Deferred value and when it completes.
+// If rendering fails after output has been flushed the response is
+// incomplete; the error is returned for logging, as it is too late to send
+// an error page.
+func (s *Stream) Render(ctx context.Context, name string, data any) error {
+	err := s.box.RenderHTMLContext(ctx, s.bw, name, data)
+	if ferr := s.flush(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// flush writes the buffered output to the client.
+func (s *Stream) flush() error {
+	if err := s.bw.Flush(); err != nil {
+		return err
+	}
+	if rw, ok := s.w.(http.ResponseWriter); ok {
+		if err := http.NewResponseController(rw).Flush(); err != nil {
+			return fmt.Errorf("stream: flush: %w", err)
+		}
+	}
+	return nil
+}
+
+// await implements the await template function. Given a Deferred it
+// flushes the output of its Stream if the value is not yet available, then
+// waits for it. Any other value is returned unchanged, so a template can
+// be rendered with plain data by RenderHTML, such as in tests.
+func await(v any) (any, error) {
+	d, ok := v.(*Deferred)
+	if !ok {
+		return v, nil
+	}
+
+	select {
+	case <-d.done:
+	default:
+		if err := d.stream.flush(); err != nil {
+			return nil, err
+		}
+		<-d.done
+	}
+	return d.val, d.err
+}
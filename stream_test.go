@@ -0,0 +1,78 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+// headWriter closes sent once the output written to it contains </head>.
+type headWriter struct {
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	once sync.Once
+	sent chan struct{}
+}
+
+func (w *headWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n, err := w.buf.Write(p)
+	if strings.Contains(w.buf.String(), "</head>") {
+		w.once.Do(func() { close(w.sent) })
+	}
+	return n, err
+}
+
+func TestStream(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("shop", templatebox.TemplateSet{
+		Templates: []string{
+			`<html><head><title>{{ .Title }}</title></head><body>{{ range await .Products }}<p>{{ . }}</p>{{ end }}</body></html>`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	w := &headWriter{sent: make(chan struct{})}
+	st := box.NewStream(w)
+	products := st.Await(func() (any, error) {
+		// the products only resolve once the head has reached the client
+		select {
+		case <-w.sent:
+			return []string{"Tea", "Cake"}, nil
+		case <-time.After(5 * time.Second):
+			return nil, errors.New("head was not flushed")
+		}
+	})
+
+	data := map[string]any{"Title": "Shop", "Products": products}
+	if err := st.Render(context.Background(), "shop", data); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	expected := `<html><head><title>Shop</title></head><body><p>Tea</p><p>Cake</p></body></html>`
+	if w.buf.String() != expected {
+		t.Fatalf("Render wrote %s, expected %s", w.buf.String(), expected)
+	}
+
+	// the same template renders with plain data
+	data["Products"] = []string{"Jam"}
+	got, err := box.RenderString("shop", data)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	if expected := `<html><head><title>Shop</title></head><body><p>Jam</p></body></html>`; got != expected {
+		t.Fatalf("RenderString returned %s, expected %s", got, expected)
+	}
+}
@@ -0,0 +1,164 @@
+package templatebox
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"html/template"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SuspenseOptions configures a Suspense.
+type SuspenseOptions struct {
+	// Fallback is the content of the placeholders shown until a deferred
+	// fragment is swapped in, such as a spinner or a skeleton.
+	Fallback template.HTML
+
+	// Expiry is how long a rendered fragment is kept for its placeholder
+	// to request it, after which it is dropped. The default is one minute.
+	Expiry time.Duration
+}
+
+// Suspense renders slow fragments of a page after the page itself. Defer
+// starts fetching the data of a fragment and rendering it in the
+// background, and returns a placeholder to render in its place. The
+// placeholder uses htmx to request the fragment from the Suspense, which
+// is an http.Handler mounted at the URL given to NewSuspense, and swap it
+// in once rendered:
+//
+//	suspense := box.NewSuspense("/deferred/", templatebox.SuspenseOptions{
+//		Fallback: `<p class="loading">Loading…</p>`,
+//	})
+//	mux.Handle("/deferred/", suspense)
+//
+//	reviews, err := suspense.Defer("product#reviews", func() (any, error) {
+//		return db.Reviews(id)
+//	})
+//
+// A Suspense is safe for concurrent use.
+type Suspense struct {
+	box  *Box
+	url  string
+	opts SuspenseOptions
+
+	mu      sync.Mutex
+	pending map[string]*suspended
+}
+
+// suspended is a fragment being rendered by a Suspense.
+type suspended struct {
+	done chan struct{}
+	out  []byte
+	err  error
+}
+
+// NewSuspense returns a Suspense whose placeholders request the deferred
+// fragments from url, the path the Suspense is mounted at.
+func (b *Box) NewSuspense(url string, opts SuspenseOptions) *Suspense {
+	if opts.Expiry <= 0 {
+		opts.Expiry = time.Minute
+	}
+	return &Suspense{
+		box:     b,
+		url:     strings.TrimSuffix(url, "/") + "/",
+		opts:    opts,
+		pending: make(map[string]*suspended),
+	}
+}
+
+// Defer calls fetch in a new goroutine and renders the named template, which
+// may be a fragment of the form "page#fragment", with its result. It returns
+// the placeholder markup to render in the page, which loads the fragment
+// when the page is shown and replaces itself with it. If fetch or the
+// render fail the error is logged when it happens and the placeholder is
+// left in place. Close waits for the goroutine, and Defer fails with
+// ErrClosed after Close.
+func (s *Suspense) Defer(name string, fetch func() (any, error)) (template.HTML, error) {
+	p, err := s.box.randomBytes(16)
+	if err != nil {
+		return "", err
+	}
+	id := "deferred-" + hex.EncodeToString(p)
+
+	b := s.box
+	b.muAsync.RLock()
+	defer b.muAsync.RUnlock()
+	if b.closed {
+		return "", fmt.Errorf("%w: deferring %s", ErrClosed, name)
+	}
+
+	f := &suspended{done: make(chan struct{})}
+	s.mu.Lock()
+	s.pending[id] = f
+	s.mu.Unlock()
+	time.AfterFunc(s.opts.Expiry, func() { s.remove(id) })
+
+	b.background.Add(1)
+	go func() {
+		defer b.background.Done()
+		defer close(f.done)
+		if f.err = s.render(name, fetch, &f.out); f.err != nil {
+			b.logger().Error("templatebox: deferred render failed", "template", name, "error", f.err)
+		}
+	}()
+
+	return template.HTML(fmt.Sprintf(`<div id="%s" hx-get="%s" hx-trigger="load" hx-swap="outerHTML">%s</div>`,
+		id, html.EscapeString(s.url+id), s.opts.Fallback)), nil
+}
+
+// render calls fetch and renders the named template with its result into
+// out.
+func (s *Suspense) render(name string, fetch func() (any, error), out *[]byte) error {
+	data, err := fetch()
+	if err != nil {
+		return fmt.Errorf("deferred %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := s.box.RenderHTMLContext(context.Background(), &buf, name, data); err != nil {
+		return err
+	}
+	*out = buf.Bytes()
+	return nil
+}
+
+// ServeHTTP writes the deferred fragment named by the last element of the
+// request path once it is rendered. Each fragment is served once; unknown
+// and expired fragments are not found.
+func (s *Suspense) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := path.Base(r.URL.Path)
+	s.mu.Lock()
+	f, ok := s.pending[id]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	select {
+	case <-f.done:
+	case <-r.Context().Done():
+		return
+	}
+	s.remove(id)
+
+	if f.err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(f.out)
+}
+
+// remove drops the fragment with the given id.
+func (s *Suspense) remove(id string) {
+	s.mu.Lock()
+	delete(s.pending, id)
+	s.mu.Unlock()
+}
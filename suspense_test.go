@@ -0,0 +1,130 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestSuspense(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		Random: rand.New(rand.NewSource(1)),
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("product", templatebox.TemplateSet{
+		Templates: []string{
+			`<h1>{{ .Name }}</h1>{{ .Reviews }}`,
+			`{{ define "reviews" }}<ul>{{ range . }}<li>{{ . }}</li>{{ end }}</ul>{{ end }}`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	suspense := box.NewSuspense("/deferred", templatebox.SuspenseOptions{Fallback: "Loading…"})
+	release := make(chan struct{})
+	reviews, err := suspense.Defer("product#reviews", func() (any, error) {
+		<-release
+		return []string{"Great", "<b>Meh</b>"}, nil
+	})
+	if err != nil {
+		t.Fatalf("Defer failed: %v", err)
+	}
+
+	// the page renders without waiting for the reviews
+	page, err := box.RenderString("product", map[string]any{"Name": "Tea", "Reviews": reviews})
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	id := "deferred-52fdfc072182654f163f5f0f9a621d72"
+	expected := `<h1>Tea</h1><div id="` + id + `" hx-get="/deferred/` + id + `" hx-trigger="load" hx-swap="outerHTML">Loading…</div>`
+	if page != expected {
+		t.Fatalf("RenderString returned %s, expected %s", page, expected)
+	}
+
+	close(release)
+	rec := httptest.NewRecorder()
+	suspense.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/deferred/"+id, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ServeHTTP returned status %d, expected %d", rec.Code, http.StatusOK)
+	}
+	if expected := "<ul><li>Great</li><li>&lt;b&gt;Meh&lt;/b&gt;</li></ul>"; rec.Body.String() != expected {
+		t.Fatalf("ServeHTTP wrote %s, expected %s", rec.Body.String(), expected)
+	}
+
+	// each fragment is served once
+	rec = httptest.NewRecorder()
+	suspense.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/deferred/"+id, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("ServeHTTP returned status %d, expected %d", rec.Code, http.StatusNotFound)
+	}
+
+	failed, err := suspense.Defer("product#reviews", func() (any, error) {
+		return nil, errors.New("database down")
+	})
+	if err != nil {
+		t.Fatalf("Defer failed: %v", err)
+	}
+	id = strings.SplitN(string(failed), `"`, 3)[1]
+	rec = httptest.NewRecorder()
+	suspense.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/deferred/"+id, nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("ServeHTTP returned status %d, expected %d", rec.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestSuspenseClose(t *testing.T) {
+	var logs bytes.Buffer
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("reviews", templatebox.TemplateSet{Templates: []string{`{{ . }}`}})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	suspense := box.NewSuspense("/deferred", templatebox.SuspenseOptions{})
+	release := make(chan struct{})
+	_, err = suspense.Defer("reviews", func() (any, error) {
+		<-release
+		return nil, errors.New("database down")
+	})
+	if err != nil {
+		t.Fatalf("Defer failed: %v", err)
+	}
+
+	// Close waits for the deferred render
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := box.Close(ctx); err == nil {
+		t.Fatalf("Close returned before the deferred render finished")
+	}
+	close(release)
+	if err := box.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// the failure is logged without the placeholder being requested
+	if !strings.Contains(logs.String(), "deferred render failed") || !strings.Contains(logs.String(), "database down") {
+		t.Fatalf("logs are %q, expected the deferred render failure", logs.String())
+	}
+
+	_, err = suspense.Defer("reviews", func() (any, error) { return nil, nil })
+	if !errors.Is(err, templatebox.ErrClosed) {
+		t.Fatalf("Defer after Close returned %v, expected ErrClosed", err)
+	}
+}
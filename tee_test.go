@@ -0,0 +1,86 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestTee(t *testing.T) {
+	type render struct {
+		name   string
+		output string
+	}
+	var got []render
+
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		Tee: func(name string, output []byte) {
+			got = append(got, render{name, string(output)})
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("hello", templatebox.TemplateSet{
+		Templates: []string{`Hello {{ index . 0 }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "hello", []string{"ada"}); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+	if buf.String() != "Hello ada" {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), "Hello ada")
+	}
+
+	// failed renders are not passed to the tee
+	if err := box.RenderHTML(&buf, "hello", []string{}); err == nil {
+		t.Fatalf("RenderHTML succeeded, expected an index error")
+	}
+
+	if len(got) != 1 || got[0] != (render{"hello", "Hello ada"}) {
+		t.Fatalf("Tee received %v, expected one render of hello", got)
+	}
+}
+
+func TestExtractText(t *testing.T) {
+	var texts []string
+	var names []string
+
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		Tee: templatebox.MultiTee(
+			func(name string, output []byte) { names = append(names, name) },
+			templatebox.ExtractText(func(name, text string) { texts = append(texts, text) }),
+		),
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("article", templatebox.TemplateSet{
+		Templates: []string{`<!DOCTYPE html><html><head><title>Fish &amp; Chips</title>
+<style>body { color: red; }</style><script>var x = "<p>not text</p>";</script></head>
+<body><!-- comment --><h1 class="a>b">Hello</h1><p>World&#39;s <b>best</b>
+   chips</p></body></html>`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	if _, err := box.RenderString("article", nil); err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+
+	expected := "Fish & Chips Hello World's best chips"
+	if len(texts) != 1 || texts[0] != expected {
+		t.Fatalf("ExtractText received %q, expected %q", texts, expected)
+	}
+	if len(names) != 1 || names[0] != "article" {
+		t.Fatalf("MultiTee received names %v, expected [article]", names)
+	}
+}
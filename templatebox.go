@@ -1,13 +1,21 @@
 package templatebox
 
 import (
+	"bytes"
+	"context"
 	"embed"
 	"fmt"
 	"html/template"
 	"io"
+	"io/fs"
+	"log/slog"
 	"os"
-	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // FuncMap is a map of functions that can be added to a template.
@@ -18,16 +26,110 @@ type FuncMap map[string]any
 type Box struct {
 	cfg           *Config
 	fs            *embed.FS
+	files         map[string][]byte // template files of a Box from ImportBox
 	templateDir   string
 	globalFuncMap FuncMap
-
-	mu   sync.RWMutex
-	html map[string]*template.Template
-
-	// set of name to template map to be used for rebuilding the template
-	// upon every request
-	muHTMLRerender        sync.RWMutex
-	rerenderTemplatesHTML map[string]FileSet
+	unsafeFuncs   map[string]registeredFunc
+	sanitizer     Sanitizer
+	markdown      MarkdownConverter
+	highlighter   Highlighter
+	assets        Assets
+	redactor      func(data any) any
+	recordDir     string
+	rewriters     []SourceRewriter
+	buildInfo     *BuildInfo
+	envSource     *valueSource
+	secretSource  *valueSource
+	include       *includeRoot
+
+	// icon configuration and the parsed files of Icons.Dir
+	muIcons   sync.RWMutex
+	icons     Icons
+	iconCache map[string]*parsedIcon
+
+	// guards reads of Config.Random
+	muRandom sync.Mutex
+
+	mu      sync.RWMutex
+	fast    atomic.Pointer[map[string]Template] // snapshot of html with Config.FastPath
+	html    map[string]Template
+	hashes  map[string]TemplateHash
+	opts    map[string]renderOptions
+	sources map[string][]Source // only with Config.KeepVersions
+
+	// shortcode templates expanded by markdown
+	shortcodes map[string]Template
+
+	// template sets parsed again for Pushers with PushOptions.Funcs
+	muPush   sync.Mutex
+	pushSets map[string]pushSet
+
+	// recent changes to the templates, oldest first
+	muAudit  sync.Mutex
+	auditLog []AuditEntry
+
+	// template set versions recorded by Reload, oldest first
+	muVersions sync.RWMutex
+	versions   []version
+
+	// FileSet of every template added with AddTemplate, used for rebuilding
+	// the template upon every request in debug mode and for reporting on
+	// the files in use, TemplateSet of every template added with
+	// AddTemplateRaw, and the source function of every template added with
+	// AddTemplateRawFunc, called to rebuild the template in debug mode
+	muFileSets sync.RWMutex
+	fileSets   map[string]FileSet
+	rawSets    map[string]TemplateSet
+	rawFuncs   map[string]func() []string
+	rebuilds   flightGroup
+
+	// directory set with SetScratchDir and the template strings of every
+	// raw template written to it, as added before any edits
+	scratchDir string
+	scratch    map[string][]string
+
+	// additional template roots registered with AddSource
+	muRoots sync.RWMutex
+	roots   map[string]fs.FS
+
+	// slots limiting concurrent renders, nil for no limit
+	renderSlots chan struct{}
+
+	// worker pool queue used by RenderAsync, started on first use
+	asyncOnce sync.Once
+	asyncJobs chan asyncJob
+
+	// background goroutines stopped by Close. muAsync guards closed and
+	// the start of background goroutines against a concurrent Close.
+	muAsync    sync.RWMutex
+	closeOnce  sync.Once
+	closing    chan struct{}
+	closed     bool
+	background sync.WaitGroup
+
+	// cache used for the output of templates with CacheOptions
+	muCache    sync.RWMutex
+	cache      Cache
+	revalidate flightGroup
+
+	// time each template was last rendered successfully
+	lastRendered shardedMap[time.Time]
+
+	// maps handed out by Data
+	data dataPool
+
+	// template sets built by Compose
+	compositions compositions
+
+	// example data, recorded data and data types used by Preview
+	muPreview sync.RWMutex
+	examples  map[string]any
+	recorded  map[string]any
+	dataTypes map[string]reflect.Type
+
+	// JSON Schemas of the render data set with SetSchema
+	muSchemas sync.RWMutex
+	schemas   map[string]*jsonSchema
 }
 
 // Config is a configuration struct for creating a new Box. The Debug field
@@ -37,6 +139,175 @@ type Box struct {
 // changes.
 type Config struct {
 	Debug bool
+
+	// Environment is the deployment environment, available to templates
+	// through the env, isDev, isStaging and isProd functions. When it is
+	// EnvProd debug mode is disabled even if Debug is set.
+	Environment Environment
+
+	// Logger receives log messages from background operations such as
+	// signal triggered reloads, and render failures in debug mode. If nil
+	// slog.Default() is used.
+	Logger *slog.Logger
+
+	// SourceComments wraps the output of every template and define in
+	// HTML comments naming the template and file that produced it, in
+	// debug mode only. Templates rendered inside attribute values, scripts
+	// or style elements will have the comments escaped into their output,
+	// so leave it off for pages that use such templates.
+	SourceComments bool
+
+	// Tee, if set, receives the name and a copy of the output of every
+	// successful render, for audit logging, search indexing or warming a
+	// CDN. It is called synchronously after the output has been written,
+	// and may keep the output slice.
+	Tee func(name string, output []byte)
+
+	// OnRebuildError, if set, is called when a template fails to rebuild
+	// in debug mode, for example because a file was saved with a syntax
+	// error. The previously loaded template continues to be rendered
+	// until a rebuild succeeds. If nil the error is logged to the Logger.
+	OnRebuildError func(name string, err error)
+
+	// ValidateHTML checks the output of every html/template render in
+	// debug mode for unclosed and mismatched tags, logging the problems
+	// found as warnings. Use CheckOutput to run the same checks in CI.
+	ValidateHTML bool
+
+	// CheckAccessibility adds accessibility checks to ValidateHTML and
+	// CheckOutput: images without alt text, form controls without labels
+	// and duplicate ids.
+	CheckAccessibility bool
+
+	// CanaryReload makes Reload render every changed template with its
+	// sample data, as used by Preview, before replacing any template. If
+	// a render fails the reload is rejected and the previous templates are
+	// kept, catching errors that depend on data rather than syntax.
+	// Templates without example, recorded or typed data are not rendered.
+	CanaryReload bool
+
+	// AuditSink, if set, receives an AuditEntry for every template
+	// registration and reload, for a change log kept outside the process.
+	// It is called synchronously after the change.
+	AuditSink func(AuditEntry)
+
+	// KeepVersions is the number of template set versions recorded by
+	// Reload that are kept for Versions and Diff. Keeping versions holds
+	// the sources of every template in memory. Zero disables versions.
+	KeepVersions int
+
+	// RecordData keeps the last data passed to each template in debug mode
+	// so that it can be used by Preview and retrieved with RecordedData.
+	// Data passes through the Redactor before it is recorded.
+	RecordData bool
+
+	// StrictHTML rejects templates whose global or local FuncMap contains
+	// a function returning template.HTML or another trusted html/template
+	// type. Such functions must be added with RegisterUnsafeFunc instead,
+	// so that UnsafeFuncs lists every escaping bypass.
+	StrictHTML bool
+
+	// StrictText makes RenderTree suitable for whitespace-sensitive output
+	// such as YAML and INI files. Templates using the {{- and -}} trim
+	// markers are rejected, so the whitespace of the output is exactly
+	// that of the template. Functions returning trusted html/template
+	// types, such as markdown, are not available, so no HTML escaping or
+	// markup reaches the output. Output containing a control character
+	// other than tab, newline or carriage return fails to render.
+	StrictText bool
+
+	// StrictData checks the data of every render against the fields the
+	// template declares with a requires comment, such as
+	// {{/* requires: Title string, Items []Item */}}, failing the render
+	// with an error naming each missing or mistyped field instead of
+	// rendering blanks, and validates the data against the JSON Schema set
+	// with SetSchema. The checks are always made in debug mode.
+	StrictData bool
+
+	// StrictSlots fails AddTemplate and AddTemplateRaw for a template set
+	// whose layout, its first source, has a slot that no other source fills
+	// and that has no default content, rather than rendering a blank
+	// region. See Box.Slots.
+	StrictSlots bool
+
+	// Clock, if set, returns the current time for the now template function
+	// and the other functions depending on the time, so golden tests and
+	// static site builds render the same output on every run. If nil
+	// time.Now is used.
+	Clock func() time.Time
+
+	// Random, if set, is the source of the random bytes of the uuid and
+	// randomHex template functions, so tests can use a seeded source such
+	// as rand.New(rand.NewSource(1)) for deterministic output. Reads are
+	// serialised, so the source need not be safe for concurrent use. If
+	// nil crypto/rand.Reader is used.
+	Random io.Reader
+
+	// DevOverlayDir is an OS directory mirroring the template directory of
+	// an embed.FS backed Box, typically the source directory the files are
+	// embedded from. In debug mode files found in it are read in
+	// preference to the embedded copies and templates are rebuilt on every
+	// render, so an embed.FS backed Box can hot-reload during development.
+	// Files missing from it are read from the embed.FS.
+	DevOverlayDir string
+
+	// AllowOutsideDir permits FileSet filenames that are absolute or use
+	// ".." to refer outside the template directory. Absolute paths are
+	// read as given on the OS filesystem. Leave it off when filenames can
+	// come from configuration or user input.
+	AllowOutsideDir bool
+
+	// StripComments removes {{/* */}} template comments and HTML comments
+	// from template sources before parsing, so that comments meant for
+	// developers are not sent to browsers.
+	StripComments bool
+
+	// Banner, if set, is output as an HTML comment after the doctype of
+	// every html/template page, for example "generated by shop v1.4.2".
+	Banner string
+
+	// MaxConcurrentRenders limits the number of templates rendered at the
+	// same time, so that a burst of expensive renders cannot starve the
+	// rest of the process. Excess renders wait for a free slot, or until
+	// the context passed to RenderHTMLContext is done. Zero means no limit.
+	// Templates rendered from template functions, such as with
+	// RenderHTMLInto, take a slot of their own, so keep the limit above
+	// the nesting depth of such calls.
+	MaxConcurrentRenders int
+
+	// RejectWhenBusy makes renders fail with ErrTooManyRenders instead of
+	// waiting when MaxConcurrentRenders renders are in progress.
+	RejectWhenBusy bool
+
+	// SourceRetries is the number of times a failed read from a source
+	// registered with AddSource is retried, for sources backed by a remote
+	// store such as HTTP or a database. Retries wait SourceRetryDelay,
+	// doubling after each attempt. A file that does not exist is never
+	// retried. When a debug rebuild still fails to read its files the
+	// previously loaded template is rendered instead, see OnRebuildError.
+	SourceRetries int
+
+	// SourceRetryDelay is the wait before the first retry of a failed
+	// source read. If zero 100ms is used.
+	SourceRetryDelay time.Duration
+
+	// AsyncWorkers is the number of goroutines rendering templates queued
+	// with RenderAsync. If zero GOMAXPROCS is used.
+	AsyncWorkers int
+
+	// AsyncQueueSize is the number of renders RenderAsync can queue before
+	// it waits for a worker. If zero 64 per worker is used.
+	AsyncQueueSize int
+
+	// FastPath renders templates with close to the cost of calling Execute
+	// on an html/template directly, for services rendering on a hot path.
+	// Templates are looked up in an immutable snapshot of the registered
+	// templates without taking a lock, and the per-render bookkeeping is
+	// skipped, so LastRendered and Unrendered report nothing. Debug mode
+	// is disabled. Renders that need a hook, because Tee, StrictData,
+	// MaxConcurrentRenders or recording is set or the template has
+	// CacheOptions, take the normal path.
+	FastPath bool
 }
 
 // default config
@@ -57,16 +328,57 @@ func NewBoxFromFSDir(fs *embed.FS, templateDir string, cfg *Config) (*Box, error
 	if fs == nil {
 		return nil, fmt.Errorf("embed.FS cannot be nil")
 	}
-	box := Box{
-		cfg:         cfg,
-		fs:          fs,
-		templateDir: templateDir,
-		html:        make(map[string]*template.Template),
+	box := newBox(cfg)
+	box.fs = fs
+	box.templateDir = templateDir
+	if box.debug() && !box.DebugEffective() {
+		box.logger().Warn("templatebox: debug mode has no effect on an embed.FS without Config.DevOverlayDir",
+			"templateDir", templateDir)
+	}
+	return box, nil
+}
+
+// newBox returns an empty Box with the given configuration.
+func newBox(cfg *Config) *Box {
+	b := &Box{
+		cfg:      cfg,
+		html:     make(map[string]Template),
+		hashes:   make(map[string]TemplateHash),
+		sources:  make(map[string][]Source),
+		opts:     make(map[string]renderOptions),
+		fileSets: make(map[string]FileSet),
+		rawSets:  make(map[string]TemplateSet),
+		rawFuncs: make(map[string]func() []string),
+
+		examples:  make(map[string]any),
+		recorded:  make(map[string]any),
+		dataTypes: make(map[string]reflect.Type),
+		schemas:   make(map[string]*jsonSchema),
+
+		closing: make(chan struct{}),
+	}
+	if cfg.MaxConcurrentRenders > 0 {
+		b.renderSlots = make(chan struct{}, cfg.MaxConcurrentRenders)
+	}
+	if cfg.Debug && cfg.Environment == EnvProd {
+		b.logger().Warn("templatebox: debug mode is disabled in the prod environment")
+	}
+	if cfg.Debug && cfg.FastPath {
+		b.logger().Warn("templatebox: debug mode is disabled by Config.FastPath")
 	}
-	if cfg.Debug {
-		box.rerenderTemplatesHTML = make(map[string]FileSet)
+	return b
+}
+
+// NewBoxFromFiles creates a new Box for the OS filesystem without a
+// template directory, for projects whose templates are spread across
+// several directories. FileSet filenames are relative to the working
+// directory, for example "internal/billing/templates/invoice.html", and
+// are not checked until the template is added.
+func NewBoxFromFiles(cfg *Config) *Box {
+	if cfg == nil {
+		cfg = defaultConfig
 	}
-	return &box, nil
+	return newBox(cfg)
 }
 
 // NewBoxFromOSDir creates a new Box for the OS filesystem at the given
@@ -85,15 +397,32 @@ func NewBoxFromOSDir(templateDir string, cfg *Config) (*Box, error) {
 		return nil, fmt.Errorf("os.Stat failed: %w", err)
 	}
 
-	box := Box{
-		cfg:         cfg,
-		templateDir: templateDir,
-		html:        make(map[string]*template.Template),
-	}
-	if cfg.Debug {
-		box.rerenderTemplatesHTML = make(map[string]FileSet)
+	box := newBox(cfg)
+	box.templateDir = templateDir
+	return box, nil
+}
+
+// NewBoxAuto creates a Box for the common "live files in development,
+// embedded files in production" setup. If osDir is not empty and exists
+// the Box reads templates from it, with Debug enabled on a copy of cfg so
+// edits appear without a restart. Otherwise the Box reads templates from
+// templateDir within the embed.FS using cfg as given.
+func NewBoxAuto(fs *embed.FS, templateDir, osDir string, cfg *Config) (*Box, error) {
+	if osDir != "" {
+		info, err := os.Stat(osDir)
+		switch {
+		case err == nil && info.IsDir():
+			devCfg := Config{}
+			if cfg != nil {
+				devCfg = *cfg
+			}
+			devCfg.Debug = true
+			return NewBoxFromOSDir(osDir, &devCfg)
+		case err != nil && !os.IsNotExist(err):
+			return nil, fmt.Errorf("os.Stat failed: %w", err)
+		}
 	}
-	return &box, nil
+	return NewBoxFromFSDir(fs, templateDir, cfg)
 }
 
 // FileSet is a set of template files and a FuncMap. The FuncMap is used to
@@ -101,6 +430,19 @@ func NewBoxFromOSDir(templateDir string, cfg *Config) (*Box, error) {
 type FileSet struct {
 	Filenames []string
 	FuncMap   FuncMap
+
+	// Meta holds optional defaults for the metaTags template function.
+	Meta *Meta
+
+	// Output is the kind of content the template produces. The zero value
+	// is OutputPage.
+	Output Output
+
+	// Cache enables memoization of rendered output for the template.
+	Cache *CacheOptions
+
+	// Engine parses the template. If nil html/template is used.
+	Engine Engine
 }
 
 // TemplateSet is a set of template strings and a FuncMap. The FuncMap is used to
@@ -108,6 +450,19 @@ type FileSet struct {
 type TemplateSet struct {
 	Templates []string
 	FuncMap   FuncMap
+
+	// Meta holds optional defaults for the metaTags template function.
+	Meta *Meta
+
+	// Output is the kind of content the template produces. The zero value
+	// is OutputPage.
+	Output Output
+
+	// Cache enables memoization of rendered output for the template.
+	Cache *CacheOptions
+
+	// Engine parses the template. If nil html/template is used.
+	Engine Engine
 }
 
 // SetGlobalFuncMap sets the global FuncMap available to all templates.
@@ -115,6 +470,12 @@ func (b *Box) SetGlobalFuncMap(g FuncMap) {
 	b.globalFuncMap = g
 }
 
+// newTemplate returns a new named html/template template with the FuncMap
+// returned by funcs added.
+func (b *Box) newTemplate(name string, meta *Meta, local FuncMap) *template.Template {
+	return template.New(name).Funcs(template.FuncMap(b.funcs(meta, local)))
+}
+
 // AddTemplateMap accepts a map of template names to FileSets and adds the
 // templates to the Box. The map key is the name of the template and the value
 // is the FileSet. The FileSet must contain at least one filename. The first
@@ -133,59 +494,95 @@ func (b *Box) AddTemplateMap(m map[string]FileSet) error {
 // AddTemplate accepts either a FileSet or StringSet and adds the template to
 // the Box.
 func (b *Box) AddTemplate(name string, s FileSet) error {
-	if len(s.Filenames) == 0 {
-		return fmt.Errorf("no filenames provided")
-	}
+	return b.AddTemplateContext(context.Background(), name, s)
+}
 
-	// the first filename in the FileSet is used as the name of the template
-	// although RenderHTML will call Execute without a name so the name is
-	// not strictly necessary but it is useful for debugging.
-	t := template.New(s.Filenames[0])
-	if b.globalFuncMap != nil {
-		t = t.Funcs(template.FuncMap(b.globalFuncMap))
+// AddTemplateContext is like AddTemplate but records the actor of ctx, set
+// with WithActor, in the audit log.
+func (b *Box) AddTemplateContext(ctx context.Context, name string, s FileSet) error {
+	if err := b.addFileSet(name, s); err != nil {
+		return err
 	}
-	if s.FuncMap != nil {
-		t = t.Funcs(template.FuncMap(s.FuncMap))
+	b.audit(ctx, AuditRegister, name)
+	return nil
+}
+
+// addFileSet parses and adds the FileSet without recording it in the audit
+// log, for debug rebuilds.
+func (b *Box) addFileSet(name string, s FileSet) error {
+	p, err := b.parseFileSet(s)
+	if err != nil {
+		return err
 	}
+	b.installFileSet(name, p, s)
+	return nil
+}
 
-	// all templates filenames within the FileSet must be relative to the
-	// templateDir
-	var filenames []string
-	if b.templateDir != "" {
-		filenames = make([]string, len(s.Filenames))
-		for i, filename := range s.Filenames {
-			filenames[i] = filepath.Join(b.templateDir, filename)
-		}
-	} else {
-		filenames = s.Filenames
+// parsedTemplate is a parsed template with the sources it was parsed from
+// and their hash.
+type parsedTemplate struct {
+	t    Template
+	hash TemplateHash
+	srcs []Source
+}
+
+// parseFileSet reads and parses the files of the FileSet without adding the
+// resulting template to the Box.
+func (b *Box) parseFileSet(s FileSet) (parsedTemplate, error) {
+	if len(s.Filenames) == 0 {
+		return parsedTemplate{}, fmt.Errorf("no filenames provided")
+	}
+	if err := b.checkFuncMaps(s.FuncMap); err != nil {
+		return parsedTemplate{}, fmt.Errorf("add template failed: %w", err)
 	}
 
-	// if b.fs is nil then we are using the OS filesystem
-	// and we need to read the template files from the OS filesystem
-	// otherwise we are using the embed.FS and we need to read the
-	// template files from the embed.FS.
-	var err error
-	if b.fs == nil {
-		t, err = t.ParseFiles(filenames...)
-	} else if len(s.Filenames) > 0 {
-		t, err = t.ParseFS(b.fs, filenames...)
+	srcs, err := b.fileSetSources(s)
+	if err != nil {
+		return parsedTemplate{}, fmt.Errorf("add template failed: %w", err)
 	}
+
+	// the first file in the FileSet is used as the name of the template
+	// although RenderHTML will call Execute without a name so the name is
+	// not strictly necessary but it is useful for debugging.
+	t, err := b.parse(srcs[0].Name, srcs, s.Engine, s.Meta, s.FuncMap)
 	if err != nil {
-		return fmt.Errorf("add template failed: %w", err)
+		return parsedTemplate{}, fmt.Errorf("add template failed: %w", err)
 	}
+	if err := b.checkSlots(srcs[0].Name, srcs, s.Engine); err != nil {
+		return parsedTemplate{}, fmt.Errorf("add template failed: %w", err)
+	}
+	return parsedTemplate{t: t, hash: hashSources(srcs), srcs: srcs}, nil
+}
 
-	b.mu.Lock()
-	b.html[name] = t
-	b.mu.Unlock()
+// installFileSet adds the parsed template p of the FileSet s to the Box
+// under the given name.
+func (b *Box) installFileSet(name string, p parsedTemplate, s FileSet) {
+	b.install(name, p, renderOptions{output: s.Output, cache: s.Cache})
 
 	// keep a copy of the FileSet to be used for rebuilding the template
-	// upon every call to RenderHTML
-	if b.cfg.Debug {
-		b.muHTMLRerender.Lock()
-		b.rerenderTemplatesHTML[name] = s
-		b.muHTMLRerender.Unlock()
+	// upon every call to RenderHTML in debug mode
+	b.muFileSets.Lock()
+	b.fileSets[name] = s
+	delete(b.rawSets, name)
+	delete(b.rawFuncs, name)
+	delete(b.scratch, name)
+	b.muFileSets.Unlock()
+}
+
+// install adds the parsed template p to the Box under the given name,
+// keeping its sources when Config.KeepVersions is set.
+func (b *Box) install(name string, p parsedTemplate, opts renderOptions) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	opts.requires = parseRequires(p.srcs)
+	b.html[name] = p.t
+	b.hashes[name] = p.hash
+	b.opts[name] = opts
+	if b.cfg.KeepVersions > 0 {
+		b.sources[name] = p.srcs
 	}
-	return nil
+	b.publishLocked()
 }
 
 // AddTemplateRaw accepts a name and a TemplateSet and adds the template
@@ -195,33 +592,140 @@ func (b *Box) AddTemplate(name string, s FileSet) error {
 // in the TemplateSet is added to the template. The template is parsed using
 // the html/template package.
 func (b *Box) AddTemplateRaw(name string, s TemplateSet) error {
+	return b.AddTemplateRawContext(context.Background(), name, s)
+}
+
+// AddTemplateRawContext is like AddTemplateRaw but records the actor of
+// ctx, set with WithActor, in the audit log.
+func (b *Box) AddTemplateRawContext(ctx context.Context, name string, s TemplateSet) error {
+	if err := b.addRaw(name, s); err != nil {
+		return err
+	}
+	b.muFileSets.Lock()
+	delete(b.rawFuncs, name)
+	b.muFileSets.Unlock()
+	b.scratchTemplate(name, s.Templates)
+
+	b.audit(ctx, AuditRegister, name)
+	return nil
+}
+
+// AddTemplateRawFunc adds a template whose template strings are returned
+// by src, such as templates stored in a database, as AddTemplateRaw does
+// for a TemplateSet of the strings. In debug mode src is called on every
+// render of the template and the template is rebuilt when the strings
+// change, so a template edited at its source is reloaded just as a file is.
+// src returns the whole set, layout and pages, so a change to any of them
+// rebuilds the set. If the rebuild fails the previous template is rendered
+// and the error is reported as for files. Rebuilds are recorded in the
+// audit log as AuditRebuild.
+func (b *Box) AddTemplateRawFunc(name string, src func() []string) error {
+	return b.AddTemplateRawFuncContext(context.Background(), name, src)
+}
+
+// AddTemplateRawFuncContext is like AddTemplateRawFunc but records the
+// actor of ctx, set with WithActor, in the audit log.
+func (b *Box) AddTemplateRawFuncContext(ctx context.Context, name string, src func() []string) error {
+	if err := b.addRaw(name, TemplateSet{Templates: src()}); err != nil {
+		return err
+	}
+	b.muFileSets.Lock()
+	b.rawFuncs[name] = src
+	delete(b.scratch, name)
+	b.muFileSets.Unlock()
+
+	b.audit(ctx, AuditRegister, name)
+	return nil
+}
+
+// reloadRawFunc rebuilds a template added with AddTemplateRawFunc if the
+// template strings returned by its source function have changed.
+func (b *Box) reloadRawFunc(name string, src func() []string) error {
+	b.muFileSets.RLock()
+	s := b.rawSets[name]
+	b.muFileSets.RUnlock()
+
+	templates := src()
+	if slices.Equal(templates, s.Templates) {
+		return nil
+	}
+	s.Templates = templates
+	if err := b.addRaw(name, s); err != nil {
+		return err
+	}
+	b.audit(context.Background(), AuditRebuild, name)
+	return nil
+}
+
+// addRaw parses and adds the TemplateSet without recording it in the audit
+// log, for debug rebuilds.
+func (b *Box) addRaw(name string, s TemplateSet) error {
 	if len(s.Templates) == 0 {
 		return fmt.Errorf("no templates provided")
 	}
+	if err := b.checkFuncMaps(s.FuncMap); err != nil {
+		return fmt.Errorf("add template %s failed: %w", name, err)
+	}
 
-	// initialise the template with the first template string in the TemplateSet
-	t := template.New(name)
-	if b.globalFuncMap != nil {
-		t = t.Funcs(template.FuncMap(b.globalFuncMap))
+	p, err := b.parseRaw(name, s)
+	if err != nil {
+		return err
 	}
-	if s.FuncMap != nil {
-		t = t.Funcs(template.FuncMap(s.FuncMap))
+	b.install(name, p, renderOptions{output: s.Output, cache: s.Cache})
+
+	// a raw template replaces any file based template of the same name
+	b.muFileSets.Lock()
+	delete(b.fileSets, name)
+	b.rawSets[name] = s
+	b.muFileSets.Unlock()
+	return nil
+}
+
+// parseRaw parses the template strings of the TemplateSet, with
+// html/template unless the TemplateSet has an Engine.
+func (b *Box) parseRaw(name string, s TemplateSet) (parsedTemplate, error) {
+	srcs, err := b.rawSources(name, s)
+	if err != nil {
+		return parsedTemplate{}, fmt.Errorf("failed to parse template %s: %w", name, err)
 	}
 
-	for i, tmplStr := range s.Templates {
-		var err error
-		t, err = t.Parse(tmplStr)
+	if s.Engine != nil {
+		t, err := b.parse(name, srcs, s.Engine, s.Meta, s.FuncMap)
 		if err != nil {
-			return fmt.Errorf("failed to parse template %s at index %d: %w\nTemplate content:\n%s",
-				name, i, err, tmplStr)
+			return parsedTemplate{}, fmt.Errorf("failed to parse template %s: %w", name, err)
 		}
+		if err := b.checkSlots(name, srcs, s.Engine); err != nil {
+			return parsedTemplate{}, err
+		}
+		return parsedTemplate{t: t, hash: hashSources(srcs), srcs: srcs}, nil
 	}
 
-	b.mu.Lock()
-	b.html[name] = t
-	b.mu.Unlock()
+	// initialise the template with the first template string in the TemplateSet
+	t := b.newTemplate(name, s.Meta, s.FuncMap)
 
-	return nil
+	for i, src := range srcs {
+		var err error
+		t, err = t.Parse(string(src.Text))
+		if err != nil {
+			return parsedTemplate{}, fmt.Errorf("failed to parse template %s at index %d: %w\nTemplate content:\n%s",
+				name, i, err, src.Text)
+		}
+	}
+	if err := b.annotate(t); err != nil {
+		return parsedTemplate{}, err
+	}
+	if err := b.checkSlots(name, srcs, nil); err != nil {
+		return parsedTemplate{}, err
+	}
+	return parsedTemplate{t: t, hash: hashSources(srcs), srcs: srcs}, nil
+}
+
+// DebugEffective reports whether templates are rebuilt from their files on
+// every render. It is false unless Config.Debug is set, and also false for
+// an embed.FS backed Box without Config.DevOverlayDir and for a Box from
+// ImportBox, whose files cannot change.
+func (b *Box) DebugEffective() bool {
+	return b.debug() && b.files == nil && (b.fs == nil || b.overlayActive())
 }
 
 // Config returns the Box configuration.
@@ -229,6 +733,14 @@ func (b *Box) Config() *Config {
 	return b.cfg
 }
 
+// logger returns the configured Logger or slog.Default().
+func (b *Box) logger() *slog.Logger {
+	if b.cfg.Logger != nil {
+		return b.cfg.Logger
+	}
+	return slog.Default()
+}
+
 // TemplateDir returns the template directory.
 func (b *Box) TemplateDir() string {
 	return b.templateDir
@@ -241,26 +753,152 @@ func (b *Box) TemplateDir() string {
 // otherwise an error is returned. The name of the template is the key used to
 // add the template to the Box.
 func (b *Box) RenderHTML(w io.Writer, name string, data any) error {
-	if b.cfg.Debug {
+	return b.render(context.Background(), w, name, data)
+}
+
+// render implements RenderHTML and RenderHTMLContext, waiting for a render
+// slot when Config.MaxConcurrentRenders is set.
+func (b *Box) render(ctx context.Context, w io.Writer, name string, data any) error {
+	if b.retainData(data) {
+		defer b.releaseData(data)
+	}
+	if t, ok := b.fastLookup(name); ok {
+		if err := t.Execute(w, data); err != nil {
+			b.logRenderError(name, data, err)
+			return err
+		}
+		return nil
+	}
+
+	release, err := b.acquireRender(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	t, opts, err := b.lookup(name)
+	if err != nil {
+		return err
+	}
+	return b.execute(w, name, t, opts, data)
+}
+
+// execute renders the template t, looked up under name, to w with data:
+// the data checks, output cache, Tee, recording and usage tracking of every
+// render.
+func (b *Box) execute(w io.Writer, name string, t Template, opts renderOptions, data any) error {
+	if err := b.checkData(name, opts, data); err != nil {
+		return err
+	}
+	b.recordData(name, data)
+
+	var tee *bytes.Buffer
+	validate := b.cfg.ValidateHTML && b.debug()
+	if b.cfg.Tee != nil || b.recordDir != "" || validate {
+		tee = new(bytes.Buffer)
+		w = io.MultiWriter(w, tee)
+	}
+
+	var err error
+	if opts.cache != nil && !b.debug() {
+		err = b.renderCached(w, name, t, data, opts.cache)
+	} else {
+		err = t.Execute(w, data)
+	}
+	if err != nil {
+		b.logRenderError(name, data, err)
+		return err
+	}
+	b.markRendered(name, time.Now())
+	if validate {
+		b.validateOutput(name, t, opts, tee.Bytes())
+	}
+	if b.recordDir != "" {
+		b.recordRender(name, data, tee.Bytes())
+	}
+	if b.cfg.Tee != nil {
+		b.cfg.Tee(name, tee.Bytes())
+	}
+	return nil
+}
+
+// checkData checks data against the required fields and the JSON Schema
+// of the named template in debug mode or with Config.StrictData.
+func (b *Box) checkData(name string, opts renderOptions, data any) error {
+	if !b.debug() && !b.cfg.StrictData {
+		return nil
+	}
+	if err := checkRequired(name, opts.requires, data); err != nil {
+		return err
+	}
+	return b.checkSchema(name, data)
+}
+
+// rebuildFailed reports a failed debug rebuild of the named template.
+func (b *Box) rebuildFailed(name string, err error) {
+	if b.cfg.OnRebuildError != nil {
+		b.cfg.OnRebuildError(name, err)
+		return
+	}
+	b.logger().Error("templatebox: rebuild failed; rendering previous template",
+		"template", name, "error", err)
+}
+
+// renderOptions holds the per-template settings taken from the FileSet or
+// TemplateSet the template was added with.
+type renderOptions struct {
+	output   Output
+	cache    *CacheOptions
+	requires []requiredField
+}
+
+// lookup returns the named template, rebuilding it first when the Box is in
+// debug mode.
+func (b *Box) lookup(name string) (Template, renderOptions, error) {
+	if b.debug() {
 		// check if the template needs to be rebuilt
-		b.muHTMLRerender.RLock()
-		s1, ok := b.rerenderTemplatesHTML[name]
-		b.muHTMLRerender.RUnlock()
-
-		// only rebuild from OS filesystem (embed.FS is read-only)
-		if ok && b.fs == nil {
-			if err := b.AddTemplate(name, s1); err != nil {
-				return fmt.Errorf("rebuild HTML template failed: %w", err)
+		b.muFileSets.RLock()
+		s1, ok := b.fileSets[name]
+		src, isFunc := b.rawFuncs[name]
+		_, isScratch := b.scratch[name]
+		b.muFileSets.RUnlock()
+
+		// only rebuild from OS filesystem or the overlay directory of an
+		// embed.FS (embed.FS is read-only), or from the source function or
+		// scratch directory files of a raw template. Concurrent renders of
+		// the same template share a single rebuild rather than each
+		// re-parsing the files.
+		var rebuild func() error
+		switch {
+		case ok && b.DebugEffective():
+			rebuild = func() error { return b.addFileSet(name, s1) }
+		case isFunc:
+			rebuild = func() error { return b.reloadRawFunc(name, src) }
+		case isScratch:
+			rebuild = func() error { return b.reloadScratch(name) }
+		}
+		if rebuild != nil {
+			if err := b.rebuilds.do(name, rebuild); err != nil {
+				// a file saved mid-edit must not break every render, so
+				// report the error and keep serving the last good template
+				b.rebuildFailed(name, err)
 			}
 		}
 	}
 
 	b.mu.RLock()
 	t, ok := b.html[name]
+	opts := b.opts[name]
 	b.mu.RUnlock()
 	if !ok {
-		return fmt.Errorf("template %s not found", name)
+		// reserved names fall back to their built-in template
+		if t, ok := builtinTemplates[name]; ok {
+			return t, renderOptions{}, nil
+		}
+		if page, fragment, ok := strings.Cut(name, "#"); ok {
+			return b.lookupFragment(name, page, fragment)
+		}
+		return nil, renderOptions{}, fmt.Errorf("template %s not found", name)
 	}
-
-	return t.Execute(w, data)
+	return t, opts, nil
 }
@@ -3,9 +3,12 @@ package templatebox_test
 import (
 	"bytes"
 	"embed"
+	"errors"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/andyfusniak/templatebox"
@@ -350,3 +353,341 @@ func TestBoxOSDirBasicWithRawTemplates(t *testing.T) {
 		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
 	}
 }
+
+// TestBoxOSDirConcurrentDebugRenders renders the same template from many
+// goroutines in debug mode while the template file changes. Run with -race
+// to check that concurrent rebuilds are safe.
+func TestBoxOSDirConcurrentDebugRenders(t *testing.T) {
+	path := t.TempDir()
+	err := os.WriteFile(filepath.Join(path, "a.html"), []byte(`version 1`), 0644)
+	if err != nil {
+		t.Fatalf("os.WriteFile failed: %v", err)
+	}
+
+	box, err := templatebox.NewBoxFromOSDir(path, &templatebox.Config{
+		Debug: true,
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplate("a", templatebox.FileSet{
+		Filenames: []string{"a.html"},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var buf bytes.Buffer
+			if err := box.RenderHTML(&buf, "a", nil); err != nil {
+				t.Errorf("RenderHTML failed: %v", err)
+				return
+			}
+			if s := buf.String(); s != "version 1" && s != "version 2" {
+				t.Errorf("RenderHTML returned %s", s)
+			}
+		}()
+
+		// replace the file atomically so that no render sees it truncated
+		if i == 25 {
+			tmp := filepath.Join(path, "a.html.tmp")
+			if err := os.WriteFile(tmp, []byte(`version 2`), 0644); err != nil {
+				t.Fatalf("os.WriteFile failed: %v", err)
+			}
+			if err := os.Rename(tmp, filepath.Join(path, "a.html")); err != nil {
+				t.Fatalf("os.Rename failed: %v", err)
+			}
+		}
+	}
+	wg.Wait()
+}
+
+func TestNewBoxAuto(t *testing.T) {
+	box, err := templatebox.NewBoxAuto(&templateFS, "testdata/templates", "testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxAuto failed: %v", err)
+	}
+	if !box.Config().Debug {
+		t.Fatalf("Config().Debug returned false, expected true for an existing OS directory")
+	}
+
+	box, err = templatebox.NewBoxAuto(&templateFS, "testdata/templates", filepath.Join(t.TempDir(), "missing"), nil)
+	if err != nil {
+		t.Fatalf("NewBoxAuto failed: %v", err)
+	}
+	if box.Config().Debug {
+		t.Fatalf("Config().Debug returned true, expected false for the embed.FS")
+	}
+
+	err = box.AddTemplate("a", templatebox.FileSet{Filenames: []string{"layout.html", "a.html"}})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+}
+
+func TestBoxFSDirDevOverlay(t *testing.T) {
+	overlay := t.TempDir()
+	box, err := templatebox.NewBoxFromFSDir(&templateFS, "testdata/templates", &templatebox.Config{
+		Debug:         true,
+		DevOverlayDir: overlay,
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromFSDir failed: %v", err)
+	}
+
+	err = box.AddTemplate("a", templatebox.FileSet{Filenames: []string{"a.html"}})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	var embedded bytes.Buffer
+	if err := box.RenderHTML(&embedded, "a", nil); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	writeTemplates(t, overlay, map[string]string{"a.html": `overlay`})
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "a", nil); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+	if buf.String() != "overlay" {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), "overlay")
+	}
+
+	if err := os.Remove(filepath.Join(overlay, "a.html")); err != nil {
+		t.Fatalf("os.Remove failed: %v", err)
+	}
+	buf.Reset()
+	if err := box.RenderHTML(&buf, "a", nil); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+	if buf.String() != embedded.String() {
+		t.Fatalf("RenderHTML returned %s, expected the embedded %s", buf.String(), embedded.String())
+	}
+}
+
+func TestBoxFSDirDebugIneffective(t *testing.T) {
+	var logs bytes.Buffer
+	box, err := templatebox.NewBoxFromFSDir(&templateFS, "testdata/templates", &templatebox.Config{
+		Debug:  true,
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromFSDir failed: %v", err)
+	}
+
+	if box.DebugEffective() {
+		t.Fatalf("DebugEffective returned true, expected false for an embed.FS")
+	}
+	if !strings.Contains(logs.String(), "debug mode has no effect") {
+		t.Fatalf("expected a warning to be logged, got %q", logs.String())
+	}
+
+	box, err = templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{Debug: true})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	if !box.DebugEffective() {
+		t.Fatalf("DebugEffective returned false, expected true for an OS directory")
+	}
+}
+
+func TestAddTemplateInvalidPath(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "templates")
+	writeTemplates(t, parent, map[string]string{
+		"secret.html":         `secret`,
+		"templates/page.html": `page`,
+	})
+
+	box, err := templatebox.NewBoxFromOSDir(dir, nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	for _, filename := range []string{"../secret.html", "a/../../secret.html", filepath.Join(parent, "secret.html")} {
+		err := box.AddTemplate("bad", templatebox.FileSet{Filenames: []string{filename}})
+		if !errors.Is(err, templatebox.ErrInvalidPath) {
+			t.Fatalf("AddTemplate %s returned %v, expected ErrInvalidPath", filename, err)
+		}
+	}
+	if err := box.AddTemplate("ok", templatebox.FileSet{Filenames: []string{"x/../page.html"}}); err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	box, err = templatebox.NewBoxFromOSDir(dir, &templatebox.Config{AllowOutsideDir: true})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	if err := box.AddTemplate("outside", templatebox.FileSet{Filenames: []string{"../secret.html"}}); err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+}
+
+func TestBoxFSDirBackslashPaths(t *testing.T) {
+	box, err := templatebox.NewBoxFromFSDir(&templateFS, `testdata\templates`, nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromFSDir failed: %v", err)
+	}
+
+	err = box.AddTemplate("a", templatebox.FileSet{Filenames: []string{`.\layout.html`, "a.html"}})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	missing, err := box.MissingFiles()
+	if err != nil {
+		t.Fatalf("MissingFiles failed: %v", err)
+	}
+	if len(missing) != 0 {
+		t.Fatalf("MissingFiles returned %v, expected none", missing)
+	}
+
+	orphans, err := box.Orphans()
+	if err != nil {
+		t.Fatalf("Orphans failed: %v", err)
+	}
+	expected := "b.html c.html d.html"
+	if strings.Join(orphans, " ") != expected {
+		t.Fatalf("Orphans returned %v, expected %s", orphans, expected)
+	}
+
+	err = box.AddTemplate("bad", templatebox.FileSet{Filenames: []string{`..\secret.html`}})
+	if !errors.Is(err, templatebox.ErrInvalidPath) {
+		t.Fatalf("AddTemplate returned %v, expected ErrInvalidPath", err)
+	}
+}
+
+func TestNewBoxFromFiles(t *testing.T) {
+	box := templatebox.NewBoxFromFiles(nil)
+	err := box.AddTemplate("a", templatebox.FileSet{
+		Filenames: []string{"testdata/templates/layout.html", "testdata/templates/a.html"},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	dirBox, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = dirBox.AddTemplate("a", templatebox.FileSet{Filenames: []string{"layout.html", "a.html"}})
+	if err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	var got, expected bytes.Buffer
+	if err := box.RenderHTML(&got, "a", nil); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+	if err := dirBox.RenderHTML(&expected, "a", nil); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+	if got.String() != expected.String() {
+		t.Fatalf("RenderHTML returned %s, expected %s", got.String(), expected.String())
+	}
+}
+
+func TestDebugRebuildFailureKeepsTemplate(t *testing.T) {
+	path := t.TempDir()
+	writeTemplates(t, path, map[string]string{"page.html": `version 1`})
+
+	var failed []string
+	box, err := templatebox.NewBoxFromOSDir(path, &templatebox.Config{
+		Debug: true,
+		OnRebuildError: func(name string, err error) {
+			failed = append(failed, name)
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	if err := box.AddTemplate("page", templatebox.FileSet{Filenames: []string{"page.html"}}); err != nil {
+		t.Fatalf("AddTemplate failed: %v", err)
+	}
+
+	// a file saved mid-edit keeps the previous template
+	writeTemplates(t, path, map[string]string{"page.html": `version 2 {{ if }}`})
+	s, err := box.RenderString("page", nil)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	expected := "version 1"
+	if s != expected {
+		t.Fatalf("RenderString returned %s, expected %s", s, expected)
+	}
+	if len(failed) != 1 || failed[0] != "page" {
+		t.Fatalf("OnRebuildError called for %v, expected [page]", failed)
+	}
+
+	writeTemplates(t, path, map[string]string{"page.html": `version 2`})
+	s, err = box.RenderString("page", nil)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	expected = "version 2"
+	if s != expected {
+		t.Fatalf("RenderString returned %s, expected %s", s, expected)
+	}
+}
+
+func TestAddTemplateRawFunc(t *testing.T) {
+	var failed []string
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		Debug: true,
+		OnRebuildError: func(name string, err error) {
+			failed = append(failed, name)
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	// the layout and page as stored in a database
+	layout := `<main>{{ template "content" . }}</main>`
+	page := `{{ define "content" }}version 1{{ end }}`
+	err = box.AddTemplateRawFunc("page", func() []string { return []string{layout, page} })
+	if err != nil {
+		t.Fatalf("AddTemplateRawFunc failed: %v", err)
+	}
+
+	render := func(expected string) {
+		t.Helper()
+		s, err := box.RenderString("page", nil)
+		if err != nil {
+			t.Fatalf("RenderString failed: %v", err)
+		}
+		if s != expected {
+			t.Fatalf("RenderString returned %s, expected %s", s, expected)
+		}
+	}
+	render("<main>version 1</main>")
+
+	// a change to the layout or the page is reloaded on the next render
+	page = `{{ define "content" }}version 2{{ end }}`
+	render("<main>version 2</main>")
+	layout = `<article>{{ template "content" . }}</article>`
+	render("<article>version 2</article>")
+
+	// a broken edit keeps the previous template
+	page = `{{ define "content" }}{{ if }}{{ end }}`
+	render("<article>version 2</article>")
+	if len(failed) != 1 || failed[0] != "page" {
+		t.Fatalf("OnRebuildError called for %v, expected [page]", failed)
+	}
+
+	// a template added with AddTemplateRaw is no longer reloaded
+	err = box.AddTemplateRaw("page", templatebox.TemplateSet{Templates: []string{`fixed`}})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	page = `{{ define "content" }}version 3{{ end }}`
+	render("fixed")
+}
@@ -0,0 +1,85 @@
+package templatebox
+
+import (
+	"html/template"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TextFuncs returns the optional text functions. Add them with
+// SetGlobalFuncMap or to the FuncMap of a template set:
+//
+//   - slugify lower-cases text and joins its words with hyphens: "Hello, World!" is "hello-world"
+//   - truncateWords keeps the first n words, adding an ellipsis if any are removed: {{ .Body | truncateWords 20 }}
+//   - stripTags returns the text content of HTML, with entities decoded
+//   - nl2br escapes text and replaces its line breaks with <br> elements
+//   - wordcount returns the number of words in text
+//   - initials returns the upper-cased first letters of the first and last words: "Ada Lovelace" is "AL"
+//
+// nl2br returns template.HTML, so with Config.StrictHTML it must be added
+// with RegisterUnsafeFunc instead. The other functions return plain
+// strings that html/template escapes as usual.
+func TextFuncs() FuncMap {
+	return FuncMap{
+		"slugify":       slugify,
+		"truncateWords": truncateWords,
+		"stripTags":     stripTags,
+		"nl2br":         nl2br,
+		"wordcount":     wordcount,
+		"initials":      initials,
+	}
+}
+
+// truncateWords implements the truncateWords function. Whitespace between
+// the words kept is preserved.
+func truncateWords(n int, s string) string {
+	if n < 0 {
+		n = 0
+	}
+	words := 0
+	inWord := false
+	for i, r := range s {
+		if unicode.IsSpace(r) {
+			inWord = false
+			continue
+		}
+		if inWord {
+			continue
+		}
+		if words == n {
+			return strings.TrimRightFunc(s[:i], unicode.IsSpace) + "…"
+		}
+		words++
+		inWord = true
+	}
+	return s
+}
+
+// nl2br implements the nl2br function.
+func nl2br(s string) template.HTML {
+	s = template.HTMLEscapeString(s)
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	return template.HTML(strings.ReplaceAll(s, "\n", "<br>\n"))
+}
+
+// wordcount implements the wordcount function.
+func wordcount(s string) int {
+	return len(strings.Fields(s))
+}
+
+// initials implements the initials function.
+func initials(s string) string {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return ""
+	}
+	first, _ := utf8.DecodeRuneInString(words[0])
+	out := string(unicode.ToUpper(first))
+	if len(words) > 1 {
+		last, _ := utf8.DecodeRuneInString(words[len(words)-1])
+		out += string(unicode.ToUpper(last))
+	}
+	return out
+}
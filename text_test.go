@@ -0,0 +1,62 @@
+package templatebox_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestTextFuncs(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetGlobalFuncMap(templatebox.TextFuncs())
+
+	tests := []struct {
+		template string
+		data     string
+		expected string
+	}{
+		{`{{ slugify . }}`, "Hello, World! Ünïcode 2024", "hello-world-ünïcode-2024"},
+		{`<a href="/tags/{{ slugify . }}">`, `"><script>`, `<a href="/tags/script">`},
+		{`{{ . | truncateWords 3 }}`, "one two  three four five", "one two  three…"},
+		{`{{ . | truncateWords 3 }}`, "one two three ", "one two three "},
+		{`{{ . | truncateWords 2 }}`, "<b>bold</b> text <i>more</i>", "&lt;b&gt;bold&lt;/b&gt; text…"},
+		{`{{ stripTags . }}`, "<p>Fish &amp; <em>chips</em></p><script>x</script>", "Fish &amp; chips"},
+		{`{{ stripTags . }}`, "&lt;script&gt;alert(1)&lt;/script&gt;", "&lt;script&gt;alert(1)&lt;/script&gt;"},
+		{`{{ nl2br . }}`, "line 1\r\n<script>alert(1)</script>\nline 3", "line 1<br>\n&lt;script&gt;alert(1)&lt;/script&gt;<br>\nline 3"},
+		{`<p title="{{ nl2br . }}">`, "a\n<b>", "<p title=\"a\n&lt;b&gt;\">"},
+		{`{{ wordcount . }}`, "  the quick\tbrown\nfox ", "4"},
+		{`{{ initials . }}`, "ada king lovelace", "AL"},
+		{`{{ initials . }}`, "Émile", "É"},
+		{`{{ initials . }}`, "<b> x", "&lt;X"},
+	}
+	for i, tt := range tests {
+		err := box.AddTemplateRaw("text", templatebox.TemplateSet{Templates: []string{tt.template}})
+		if err != nil {
+			t.Fatalf("AddTemplateRaw failed: %v", err)
+		}
+		got, err := box.RenderString("text", tt.data)
+		if err != nil {
+			t.Fatalf("test %d: RenderString failed: %v", i, err)
+		}
+		if got != tt.expected {
+			t.Fatalf("test %d: %s returned %q, expected %q", i, tt.template, got, tt.expected)
+		}
+	}
+}
+
+func TestTextFuncsStrictHTML(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{StrictHTML: true})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	box.SetGlobalFuncMap(templatebox.TextFuncs())
+
+	err = box.AddTemplateRaw("text", templatebox.TemplateSet{Templates: []string{`{{ nl2br . }}`}})
+	if err == nil || !strings.Contains(err.Error(), "func nl2br returns template.HTML") {
+		t.Fatalf("AddTemplateRaw returned %v, expected a strict mode error for nl2br", err)
+	}
+}
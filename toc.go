@@ -0,0 +1,107 @@
+package templatebox
+
+import (
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// TOCEntry is a heading of converted markdown, for building a table of
+// contents.
+type TOCEntry struct {
+	// Level is the heading level, 1 for <h1> to 6 for <h6>.
+	Level int
+
+	// ID is the id attribute of the heading, to link to as "#" + ID.
+	ID string
+
+	// Title is the text of the heading.
+	Title string
+}
+
+// MarkdownContent is markdown converted to HTML by Markdown, along with
+// its headings.
+type MarkdownContent struct {
+	HTML template.HTML
+	TOC  []TOCEntry
+}
+
+// headingPattern matches a heading element, capturing its level, its
+// attributes and its content.
+var headingPattern = regexp.MustCompile(`(?is)<h([1-6])(\s[^>]*)?>(.*?)</h[1-6]\s*>`)
+
+// idAttrPattern matches an id attribute, capturing its value.
+var idAttrPattern = regexp.MustCompile(`(?i)\sid\s*=\s*"([^"]*)"`)
+
+// Markdown converts the markdown source to sanitized HTML in the same way as
+// the markdown template function, and returns it with the headings of the
+// document, so that a layout can render a table of contents next to the
+// content:
+//
+//	<nav>{{ range .Content.TOC }}<a href="#{{ .ID }}">{{ .Title }}</a>{{ end }}</nav>
+//	<article>{{ .Content.HTML }}</article>
+func (b *Box) Markdown(src string) (MarkdownContent, error) {
+	out, toc, err := b.convertMarkdown(src)
+	if err != nil {
+		return MarkdownContent{}, err
+	}
+	return MarkdownContent{HTML: out, TOC: toc}, nil
+}
+
+// addHeadingIDs gives every heading in html without an id one derived from
+// its text, unique within the document, and returns the headings.
+func addHeadingIDs(html string) (string, []TOCEntry) {
+	var toc []TOCEntry
+	used := make(map[string]int)
+	out := headingPattern.ReplaceAllStringFunc(html, func(m string) string {
+		sub := headingPattern.FindStringSubmatch(m)
+		level, attrs, content := sub[1], sub[2], sub[3]
+		title := stripTags(content)
+
+		if id := idAttrPattern.FindStringSubmatch(attrs); id != nil {
+			used[id[1]]++
+			toc = append(toc, TOCEntry{Level: int(level[0] - '0'), ID: id[1], Title: title})
+			return m
+		}
+
+		id := slugify(title)
+		if id == "" {
+			id = "section"
+		}
+		if used[id] > 0 {
+			// the suffixed id may itself be taken, such as by a heading
+			// titled "a-1" before the second "a"
+			base := id
+			for n := used[base]; used[id] > 0; n++ {
+				id = fmt.Sprintf("%s-%d", base, n)
+			}
+			used[base]++
+		}
+		used[id]++
+
+		toc = append(toc, TOCEntry{Level: int(level[0] - '0'), ID: id, Title: title})
+		return fmt.Sprintf(`<h%s id="%s"%s>%s</h%s>`, level, id, attrs, content, level)
+	})
+	return out, toc
+}
+
+// slugify returns s lower-cased with runs of characters other than letters
+// and digits replaced by a single hyphen.
+func slugify(s string) string {
+	var sb strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return sb.String()
+}
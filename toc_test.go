@@ -0,0 +1,56 @@
+package templatebox_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestMarkdownTOC(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	// convert "#" prefixed lines to headings
+	box.SetMarkdownConverter(templatebox.MarkdownConverterFunc(func(s string) (string, error) {
+		var out string
+		for _, line := range strings.Split(s, "\n") {
+			level := len(line) - len(strings.TrimLeft(line, "#"))
+			if level == 0 {
+				out += "<p>" + line + "</p>"
+				continue
+			}
+			tag := string(rune('0' + level))
+			out += "<h" + tag + ">" + strings.TrimSpace(line[level:]) + "</h" + tag + ">"
+		}
+		return out, nil
+	}))
+	box.SetSanitizer(templatebox.SanitizerFunc(func(s string) string { return s }))
+
+	content, err := box.Markdown("# Getting Started\ntext\n## Install <em>now</em>\n## Install now\n## A 1\n## A\n## A")
+	if err != nil {
+		t.Fatalf("Markdown failed: %v", err)
+	}
+
+	expected := `<h1 id="getting-started">Getting Started</h1><p>text</p>` +
+		`<h2 id="install-now">Install <em>now</em></h2><h2 id="install-now-1">Install now</h2>` +
+		`<h2 id="a-1">A 1</h2><h2 id="a">A</h2><h2 id="a-2">A</h2>`
+	if string(content.HTML) != expected {
+		t.Fatalf("Markdown returned %s, expected %s", content.HTML, expected)
+	}
+
+	expectedTOC := []templatebox.TOCEntry{
+		{Level: 1, ID: "getting-started", Title: "Getting Started"},
+		{Level: 2, ID: "install-now", Title: "Install now"},
+		{Level: 2, ID: "install-now-1", Title: "Install now"},
+		{Level: 2, ID: "a-1", Title: "A 1"},
+		{Level: 2, ID: "a", Title: "A"},
+		{Level: 2, ID: "a-2", Title: "A"},
+	}
+	if !reflect.DeepEqual(content.TOC, expectedTOC) {
+		t.Fatalf("Markdown returned TOC %+v, expected %+v", content.TOC, expectedTOC)
+	}
+}
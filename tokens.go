@@ -0,0 +1,201 @@
+package templatebox
+
+import (
+	"bytes"
+	"html"
+)
+
+// tokenType is the type of an htmlToken.
+type tokenType int
+
+const (
+	startTagToken tokenType = iota
+	endTagToken
+	selfClosingTagToken
+)
+
+// htmlAttr is an attribute of an htmlToken, with a lower case key and an
+// unescaped value.
+type htmlAttr struct {
+	Key, Val string
+}
+
+// htmlToken is a tag found in rendered HTML by scanTokens, with a lower case
+// name in Data.
+type htmlToken struct {
+	Type tokenType
+	Data string
+	Attr []htmlAttr
+}
+
+// rawTextTags are the elements whose content is text up to their end tag,
+// so that markup inside them, such as "<b>" in a script, is not read as
+// tags.
+var rawTextTags = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true,
+	"iframe": true, "noembed": true, "noframes": true, "noscript": true,
+	"xmp": true,
+}
+
+// scanTokens calls fn with each tag of the HTML in output and the line and
+// column it starts at. Text, comments and doctypes are skipped. It reads
+// tags the way browsers tokenize them, which is all the output checks
+// need, rather than building a document tree.
+func scanTokens(output []byte, fn func(tok htmlToken, line, col int)) {
+	line, col, pos := 1, 1, 0
+	advance := func(to int) {
+		for _, c := range output[pos:to] {
+			if c == '\n' {
+				line, col = line+1, 1
+			} else {
+				col++
+			}
+		}
+		pos = to
+	}
+
+	for i := 0; i < len(output); {
+		j := bytes.IndexByte(output[i:], '<')
+		if j < 0 {
+			return
+		}
+		i += j
+		rest := output[i:]
+
+		switch {
+		case bytes.HasPrefix(rest, []byte("<!--")):
+			i = skipPast(output, i+4, "-->")
+			continue
+		case len(rest) > 1 && (rest[1] == '!' || rest[1] == '?'):
+			i = skipPast(output, i+2, ">")
+			continue
+		case len(rest) > 2 && rest[1] == '/' && isASCIILetter(rest[2]):
+			name, end := readTagName(output, i+2)
+			advance(i)
+			fn(htmlToken{Type: endTagToken, Data: name}, line, col)
+			i = skipPast(output, end, ">")
+			continue
+		case len(rest) > 1 && isASCIILetter(rest[1]):
+		default:
+			i++
+			continue
+		}
+
+		tok, end := readStartTag(output, i)
+		advance(i)
+		fn(tok, line, col)
+		i = end
+		if tok.Type == startTagToken && rawTextTags[tok.Data] {
+			i = skipRawText(output, i, tok.Data)
+		}
+	}
+}
+
+// readStartTag reads the start tag at output[i], returning it and the
+// offset just past its closing bracket.
+func readStartTag(output []byte, i int) (htmlToken, int) {
+	tok := htmlToken{Type: startTagToken}
+	tok.Data, i = readTagName(output, i+1)
+	for i < len(output) {
+		c := output[i]
+		switch {
+		case isSpace(c):
+			i++
+		case c == '>':
+			return tok, i + 1
+		case c == '/':
+			i++
+			if i < len(output) && output[i] == '>' {
+				tok.Type = selfClosingTagToken
+				return tok, i + 1
+			}
+		default:
+			var a htmlAttr
+			a, i = readAttr(output, i)
+			tok.Attr = append(tok.Attr, a)
+		}
+	}
+	return tok, i
+}
+
+// readAttr reads the attribute at output[i], returning it and the offset
+// just past it.
+func readAttr(output []byte, i int) (htmlAttr, int) {
+	start := i
+	for i < len(output) && !isSpace(output[i]) && output[i] != '=' && output[i] != '>' && output[i] != '/' {
+		i++
+	}
+	// an attribute name can start with "=" or "/", which must be consumed
+	if i == start {
+		i++
+	}
+	a := htmlAttr{Key: string(bytes.ToLower(output[start:i]))}
+
+	j := i
+	for j < len(output) && isSpace(output[j]) {
+		j++
+	}
+	if j >= len(output) || output[j] != '=' {
+		return a, i
+	}
+	i = j + 1
+	for i < len(output) && isSpace(output[i]) {
+		i++
+	}
+	if i >= len(output) {
+		return a, i
+	}
+
+	if q := output[i]; q == '"' || q == '\'' {
+		end := bytes.IndexByte(output[i+1:], q)
+		if end < 0 {
+			a.Val = html.UnescapeString(string(output[i+1:]))
+			return a, len(output)
+		}
+		a.Val = html.UnescapeString(string(output[i+1 : i+1+end]))
+		return a, i + end + 2
+	}
+	start = i
+	for i < len(output) && !isSpace(output[i]) && output[i] != '>' {
+		i++
+	}
+	a.Val = html.UnescapeString(string(output[start:i]))
+	return a, i
+}
+
+// readTagName reads the tag name starting at output[i], returning it in lower
+// case and the offset just past it.
+func readTagName(output []byte, i int) (string, int) {
+	start := i
+	for i < len(output) && !isSpace(output[i]) && output[i] != '/' && output[i] != '>' {
+		i++
+	}
+	return string(bytes.ToLower(output[start:i])), i
+}
+
+// skipRawText returns the offset of the end tag of the raw text element
+// name in output, searching from i, or the end of output if it has none.
+func skipRawText(output []byte, i int, name string) int {
+	end := []byte("</" + name)
+	for {
+		j := bytes.Index(bytes.ToLower(output[i:]), end)
+		if j < 0 {
+			return len(output)
+		}
+		k := i + j + len(end)
+		if k >= len(output) || isSpace(output[k]) || output[k] == '>' || output[k] == '/' {
+			return i + j
+		}
+		i = k
+	}
+}
+
+// skipPast returns the offset just past the first occurrence of sep in
+// output at or after i, or the end of output if there is none.
+func skipPast(output []byte, i int, sep string) int {
+	j := bytes.Index(output[i:], []byte(sep))
+	if j < 0 {
+		return len(output)
+	}
+	return i + j + len(sep)
+}
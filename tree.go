@@ -0,0 +1,225 @@
+package templatebox
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	texttemplate "text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RenderTree renders a directory of text templates, such as nginx or
+// Kubernetes configuration files, against data into an output tree. Every
+// file in srcDir with a .tmpl extension is parsed with text/template, so
+// its output is not escaped, and executed with data. The output is written
+// to the same relative path in outDir without the .tmpl extension. Other
+// files are copied unchanged. Directories and files are created with the
+// permissions of their source, so an executable script stays executable.
+//
+// Templates have the builtin functions and the global FuncMap of the Box.
+// A missing map key is an error rather than an empty value, so a typo in
+// a template cannot silently produce a broken configuration. If a template
+// fails to parse or execute, RenderTree returns an error listing every
+// failure after writing the files that succeeded. Set Config.StrictText
+// for whitespace-sensitive formats such as YAML.
+func (b *Box) RenderTree(srcDir, outDir string, data any) error {
+	_, err := b.RenderTreeWithOptions(srcDir, outDir, data, TreeOptions{})
+	return err
+}
+
+// TreeOptions configures RenderTreeWithOptions.
+type TreeOptions struct {
+	// DryRun renders the templates and returns the changes that would be
+	// made to the output tree without writing any file or directory, so a
+	// pipeline can show a plan before applying it.
+	DryRun bool
+}
+
+// FileChange is a change made, or with TreeOptions.DryRun to be made, to
+// a file of the output tree of RenderTreeWithOptions.
+type FileChange struct {
+	// File is the output file, relative to the output directory and using
+	// forward slashes.
+	File string
+
+	// Created is true if the file does not exist.
+	Created bool
+
+	// OldMode and Mode are the permissions of the existing file and of the
+	// file written. They differ when the permissions change.
+	OldMode fs.FileMode
+	Mode    fs.FileMode
+
+	// Diff is the unified diff of the existing content and the new
+	// content, empty if only the permissions change.
+	Diff string
+}
+
+// RenderTreeWithOptions renders a directory of text templates into an
+// output tree as RenderTree does, returning the changes to the files of
+// the output tree in walk order. Files whose content and permissions are
+// unchanged are not included.
+func (b *Box) RenderTreeWithOptions(srcDir, outDir string, data any, opts TreeOptions) ([]FileChange, error) {
+	if outDir == "" {
+		return nil, fmt.Errorf("output directory cannot be empty")
+	}
+	funcs := texttemplate.FuncMap(b.funcs(nil, nil))
+	if b.cfg.StrictText {
+		for name, fn := range funcs {
+			if trustedResult(fn) != nil {
+				delete(funcs, name)
+			}
+		}
+	}
+
+	var changes []FileChange
+	var errs []error
+	err := filepath.WalkDir(srcDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(outDir, rel)
+
+		if d.IsDir() {
+			if opts.DryRun {
+				return nil
+			}
+			if err := os.MkdirAll(dst, 0755); err != nil {
+				return err
+			}
+			return os.Chmod(dst, info.Mode().Perm())
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		text, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(p, ".tmpl") {
+			out, err := b.renderTreeFile(filepath.ToSlash(rel), text, funcs, data)
+			if err != nil {
+				errs = append(errs, err)
+				return nil
+			}
+			text, dst = out, strings.TrimSuffix(dst, ".tmpl")
+		}
+
+		file := filepath.ToSlash(strings.TrimSuffix(rel, ".tmpl"))
+		change, err := fileChange(dst, file, text, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if change == nil {
+			return nil
+		}
+		changes = append(changes, *change)
+		if opts.DryRun {
+			return nil
+		}
+		return writeFileMode(dst, text, info.Mode().Perm())
+	})
+	if err != nil {
+		return changes, fmt.Errorf("render tree %s: %w", srcDir, err)
+	}
+	return changes, errors.Join(errs...)
+}
+
+// fileChange compares the existing output file filename with the content
+// and permissions about to be written, returning nil if they are the same.
+func fileChange(filename, file string, text []byte, perm fs.FileMode) (*FileChange, error) {
+	old, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &FileChange{
+			File:    file,
+			Created: true,
+			Mode:    perm,
+			Diff:    unifiedDiff("/dev/null", "b/"+file, "", string(text)),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := unifiedDiff("a/"+file, "b/"+file, string(old), string(text))
+	if diff == "" && info.Mode().Perm() == perm {
+		return nil, nil
+	}
+	return &FileChange{File: file, OldMode: info.Mode().Perm(), Mode: perm, Diff: diff}, nil
+}
+
+// renderTreeFile parses and executes a template file of RenderTree.
+func (b *Box) renderTreeFile(name string, text []byte, funcs texttemplate.FuncMap, data any) ([]byte, error) {
+	if b.cfg.StrictText {
+		if loc := trimMarkerPattern.FindIndex(text); loc != nil {
+			return nil, fmt.Errorf("parse %s: strict mode: trim marker at line %d", name, lineAt(text, loc[0]))
+		}
+	}
+	t, err := texttemplate.New(name).Funcs(funcs).Option("missingkey=error").Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render %s: %w", name, err)
+	}
+	if b.cfg.StrictText {
+		if err := checkControlChars(buf.Bytes()); err != nil {
+			return nil, fmt.Errorf("render %s: strict mode: %w", name, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// trimMarkerPattern matches the trim markers of text/template actions.
+var trimMarkerPattern = regexp.MustCompile(`\{\{-\s|\s-\}\}`)
+
+// checkControlChars returns an error for the first control character in
+// output other than tab, newline and carriage return, including the C1
+// controls, and for invalid UTF-8.
+func checkControlChars(output []byte) error {
+	for i := 0; i < len(output); {
+		r, size := utf8.DecodeRune(output[i:])
+		if r == utf8.RuneError && size == 1 {
+			return fmt.Errorf("invalid UTF-8 at line %d", lineAt(output, i))
+		}
+		if unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+			return fmt.Errorf("control character %U at line %d", r, lineAt(output, i))
+		}
+		i += size
+	}
+	return nil
+}
+
+// lineAt returns the line number of the byte offset i in text.
+func lineAt(text []byte, i int) int {
+	return bytes.Count(text[:i], []byte("\n")) + 1
+}
+
+// writeFileMode writes data to the named file and sets its permissions to
+// perm, which os.WriteFile only applies, less the umask, to new files.
+func writeFileMode(filename string, data []byte, perm fs.FileMode) error {
+	if err := os.WriteFile(filename, data, perm); err != nil {
+		return err
+	}
+	return os.Chmod(filename, perm)
+}
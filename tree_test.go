@@ -0,0 +1,173 @@
+package templatebox_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestRenderTree(t *testing.T) {
+	src := t.TempDir()
+	files := map[string]struct {
+		text string
+		mode os.FileMode
+	}{
+		"nginx.conf.tmpl":     {"server {\n    listen {{ .Port }};\n    server_name <{{ .Host }}>;\n}\n", 0644},
+		"bin/reload.sh.tmpl":  {"#!/bin/sh\nkill -HUP {{ .PID }}\n", 0755},
+		"conf.d/static.conf":  {"gzip on;\n", 0600},
+		"k8s/deploy.yml.tmpl": {"replicas: {{ .Replicas }}\n", 0644},
+	}
+	for name, f := range files {
+		filename := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+		if err := os.WriteFile(filename, []byte(f.text), f.mode); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+
+	box := templatebox.NewBoxFromFiles(nil)
+	out := filepath.Join(t.TempDir(), "out")
+	data := map[string]any{"Port": 8080, "Host": "example.com", "PID": 42, "Replicas": 3}
+	if err := box.RenderTree(src, out, data); err != nil {
+		t.Fatalf("RenderTree failed: %v", err)
+	}
+
+	expected := map[string]struct {
+		text string
+		mode os.FileMode
+	}{
+		"nginx.conf":         {"server {\n    listen 8080;\n    server_name <example.com>;\n}\n", 0644},
+		"bin/reload.sh":      {"#!/bin/sh\nkill -HUP 42\n", 0755},
+		"conf.d/static.conf": {"gzip on;\n", 0600},
+		"k8s/deploy.yml":     {"replicas: 3\n", 0644},
+	}
+	for name, e := range expected {
+		filename := filepath.Join(out, filepath.FromSlash(name))
+		got, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("ReadFile failed: %v", err)
+		}
+		if string(got) != e.text {
+			t.Fatalf("%s is %q, expected %q", name, got, e.text)
+		}
+		info, err := os.Stat(filename)
+		if err != nil {
+			t.Fatalf("Stat failed: %v", err)
+		}
+		if info.Mode().Perm() != e.mode {
+			t.Fatalf("%s has mode %v, expected %v", name, info.Mode().Perm(), e.mode)
+		}
+	}
+
+	// a missing key fails rather than rendering an empty value
+	delete(data, "Replicas")
+	err := box.RenderTree(src, out, data)
+	if err == nil || !strings.Contains(err.Error(), "k8s/deploy.yml.tmpl") {
+		t.Fatalf("RenderTree returned %v, expected an error for k8s/deploy.yml.tmpl", err)
+	}
+}
+
+func TestRenderTreeStrictText(t *testing.T) {
+	src := t.TempDir()
+	files := map[string]string{
+		"app.yml.tmpl":     "name: {{ .Name }}\nport: {{ .Port }}\n",
+		"trim.yml.tmpl":    "items:\n{{- range .Items }}\n  - {{ . }}\n{{- end }}\n",
+		"control.ini.tmpl": "[app]\nname = {{ .Bell }}\n",
+		"markdown.md.tmpl": "{{ markdown .Name }}\n",
+	}
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(text), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+
+	box := templatebox.NewBoxFromFiles(&templatebox.Config{StrictText: true})
+	out := t.TempDir()
+	data := map[string]any{"Name": "app", "Port": 8080, "Items": []string{"a"}, "Bell": "ding\a"}
+	err := box.RenderTree(src, out, data)
+	if err == nil {
+		t.Fatalf("RenderTree succeeded, expected strict mode errors")
+	}
+	for _, expected := range []string{
+		"parse trim.yml.tmpl: strict mode: trim marker at line 2",
+		"render control.ini.tmpl: strict mode: control character U+0007 at line 2",
+		`function "markdown" not defined`,
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("RenderTree returned %v, expected it to contain %q", err, expected)
+		}
+	}
+
+	got, err := os.ReadFile(filepath.Join(out, "app.yml"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if expected := "name: app\nport: 8080\n"; string(got) != expected {
+		t.Fatalf("app.yml is %q, expected %q", got, expected)
+	}
+}
+
+func TestRenderTreeDryRun(t *testing.T) {
+	src := t.TempDir()
+	out := t.TempDir()
+	for name, text := range map[string]string{
+		"app.conf.tmpl": "port = {{ .Port }}\nworkers = 4\n",
+		"new.conf.tmpl": "host = {{ .Host }}\n",
+		"same.conf":     "gzip = on\n",
+	} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(text), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	for name, text := range map[string]string{
+		"app.conf":  "port = 80\nworkers = 4\n",
+		"same.conf": "gzip = on\n",
+	} {
+		if err := os.WriteFile(filepath.Join(out, name), []byte(text), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+
+	box := templatebox.NewBoxFromFiles(nil)
+	data := map[string]any{"Port": 8080, "Host": "example.com"}
+	changes, err := box.RenderTreeWithOptions(src, out, data, templatebox.TreeOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("RenderTreeWithOptions failed: %v", err)
+	}
+
+	expected := []templatebox.FileChange{
+		{
+			File:    "app.conf",
+			OldMode: 0644,
+			Mode:    0644,
+			Diff:    "--- a/app.conf\n+++ b/app.conf\n@@ -1,2 +1,2 @@\n-port = 80\n+port = 8080\n workers = 4\n",
+		},
+		{
+			File:    "new.conf",
+			Created: true,
+			Mode:    0644,
+			Diff:    "--- /dev/null\n+++ b/new.conf\n@@ -0,0 +1 @@\n+host = example.com\n",
+		},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("RenderTreeWithOptions returned %+v, expected %+v", changes, expected)
+	}
+
+	// nothing is written in a dry run
+	got, err := os.ReadFile(filepath.Join(out, "app.conf"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if string(got) != "port = 80\nworkers = 4\n" {
+		t.Fatalf("app.conf is %q, expected it unchanged", got)
+	}
+	if _, err := os.Stat(filepath.Join(out, "new.conf")); !os.IsNotExist(err) {
+		t.Fatalf("Stat of new.conf returned %v, expected it not to exist", err)
+	}
+}
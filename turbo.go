@@ -0,0 +1,66 @@
+package templatebox
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+)
+
+// TurboStreamContentType is the content type of Turbo Stream responses.
+const TurboStreamContentType = "text/vnd.turbo-stream.html; charset=utf-8"
+
+// turboActions are the actions of a Turbo Stream element, and whether
+// each one takes a template.
+var turboActions = map[string]bool{
+	"append":  true,
+	"prepend": true,
+	"replace": true,
+	"update":  true,
+	"before":  true,
+	"after":   true,
+	"remove":  false,
+	"refresh": false,
+}
+
+// RenderTurboStream renders the named template as the content of a Turbo
+// Stream element performing action, such as "append" or "replace", on the
+// element with the id target, for Hotwire frontends:
+//
+//	<turbo-stream action="append" target="messages"><template>...</template></turbo-stream>
+//
+// The remove and refresh actions take no template, so name must be empty.
+// If w is an http.ResponseWriter without a Content-Type, it is set to
+// TurboStreamContentType. Call RenderTurboStream again to write further
+// streams to the same response. Nothing is written if the render fails.
+func (b *Box) RenderTurboStream(w io.Writer, action, target, name string, data any) error {
+	hasTemplate, ok := turboActions[action]
+	if !ok {
+		return fmt.Errorf("turbo stream: unknown action %q", action)
+	}
+	if !hasTemplate && name != "" {
+		return fmt.Errorf("turbo stream: the %s action takes no template, got %s", action, name)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<turbo-stream action="%s"`, action)
+	if target != "" {
+		fmt.Fprintf(&buf, ` target="%s"`, html.EscapeString(target))
+	}
+	buf.WriteByte('>')
+	if hasTemplate {
+		buf.WriteString("<template>")
+		if err := b.RenderHTML(&buf, name, data); err != nil {
+			return err
+		}
+		buf.WriteString("</template>")
+	}
+	buf.WriteString("</turbo-stream>\n")
+
+	if rw, ok := w.(http.ResponseWriter); ok && rw.Header().Get("Content-Type") == "" {
+		rw.Header().Set("Content-Type", TurboStreamContentType)
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
@@ -0,0 +1,42 @@
+package templatebox_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestRenderTurboStream(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateRaw("message", templatebox.TemplateSet{
+		Templates: []string{`<div id="message_{{ .ID }}">{{ .Text }}</div>`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	if err := box.RenderTurboStream(rec, "append", "messages", "message", map[string]any{"ID": 7, "Text": "<hi>"}); err != nil {
+		t.Fatalf("RenderTurboStream failed: %v", err)
+	}
+	if err := box.RenderTurboStream(rec, "remove", `message_"3"`, "", nil); err != nil {
+		t.Fatalf("RenderTurboStream failed: %v", err)
+	}
+
+	expected := `<turbo-stream action="append" target="messages"><template><div id="message_7">&lt;hi&gt;</div></template></turbo-stream>` + "\n" +
+		`<turbo-stream action="remove" target="message_&#34;3&#34;"></turbo-stream>` + "\n"
+	if rec.Body.String() != expected {
+		t.Fatalf("RenderTurboStream wrote %s, expected %s", rec.Body.String(), expected)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != templatebox.TurboStreamContentType {
+		t.Fatalf("Content-Type was %s, expected %s", ct, templatebox.TurboStreamContentType)
+	}
+
+	if err := box.RenderTurboStream(rec, "explode", "messages", "message", nil); err == nil {
+		t.Fatalf("RenderTurboStream succeeded, expected an error for an unknown action")
+	}
+}
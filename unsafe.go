@@ -0,0 +1,135 @@
+package templatebox
+
+import (
+	"fmt"
+	"html/template"
+	"reflect"
+	"sort"
+)
+
+// UnsafeFunc is a template function that returns one of the html/template
+// trusted types, such as template.HTML, and so bypasses contextual
+// escaping for its result.
+type UnsafeFunc struct {
+	Name string
+
+	// Justification explains why the function's output is safe to render
+	// without escaping.
+	Justification string
+
+	// Builtin is true for the builtin functions of the Box.
+	Builtin bool
+}
+
+// registeredFunc is a function added with RegisterUnsafeFunc.
+type registeredFunc struct {
+	fn            any
+	justification string
+}
+
+// builtinUnsafeFuncs are the builtin functions returning trusted types.
+var builtinUnsafeFuncs = map[string]string{
+	"sanitize":     "output is cleaned by the configured Sanitizer",
+	"markdown":     "converted HTML is cleaned by the configured Sanitizer",
+	"highlight":    "code is escaped by the configured Highlighter",
+	"srcset":       "URLs and widths are built from the Assets configuration",
+	"imgTag":       "attribute values are escaped with html.EscapeString",
+	"metaTags":     "attribute values are escaped with html.EscapeString",
+	"icon":         "markup is read from the configured Icons, with classes escaped",
+	"hxHeaders":    "the JSON attribute value is escaped with html.EscapeString",
+	sourceFuncName: "fixed HTML comments naming the template source, in debug mode only",
+	bannerFuncName: "HTML comment holding the escaped Config.Banner",
+}
+
+// trustedTypes are the html/template types whose values are not escaped.
+var trustedTypes = map[reflect.Type]bool{
+	reflect.TypeOf(template.HTML("")):     true,
+	reflect.TypeOf(template.HTMLAttr("")): true,
+	reflect.TypeOf(template.JS("")):       true,
+	reflect.TypeOf(template.JSStr("")):    true,
+	reflect.TypeOf(template.CSS("")):      true,
+	reflect.TypeOf(template.URL("")):      true,
+	reflect.TypeOf(template.Srcset("")):   true,
+}
+
+// RegisterUnsafeFunc adds a template function that returns a trusted
+// html/template type to every template in the Box, recording why its output
+// is safe. Functions registered after a template is added are only
+// available to templates added later.
+//
+// In strict mode (Config.StrictHTML) this is the only way to add such a
+// function, so UnsafeFuncs lists every escaping bypass available to the
+// templates.
+func (b *Box) RegisterUnsafeFunc(name string, fn any, justification string) error {
+	if reflect.TypeOf(fn) == nil || reflect.TypeOf(fn).Kind() != reflect.Func {
+		return fmt.Errorf("register unsafe func %s: value is %T, not a function", name, fn)
+	}
+	if justification == "" {
+		return fmt.Errorf("register unsafe func %s: justification is required", name)
+	}
+
+	if b.unsafeFuncs == nil {
+		b.unsafeFuncs = make(map[string]registeredFunc)
+	}
+	b.unsafeFuncs[name] = registeredFunc{fn: fn, justification: justification}
+	return nil
+}
+
+// UnsafeFuncs returns the builtin functions and the functions registered
+// with RegisterUnsafeFunc that return trusted html/template types, sorted by
+// name. A registered function replaces a builtin of the same name.
+func (b *Box) UnsafeFuncs() []UnsafeFunc {
+	funcs := make(map[string]UnsafeFunc)
+	for name, why := range builtinUnsafeFuncs {
+		funcs[name] = UnsafeFunc{Name: name, Justification: why, Builtin: true}
+	}
+	for name, f := range b.unsafeFuncs {
+		funcs[name] = UnsafeFunc{Name: name, Justification: f.justification}
+	}
+
+	list := make([]UnsafeFunc, 0, len(funcs))
+	for _, f := range funcs {
+		list = append(list, f)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// checkFuncMaps returns an error in strict mode if the global FuncMap or
+// the local FuncMap contains a function returning a trusted type.
+func (b *Box) checkFuncMaps(local FuncMap) error {
+	if !b.cfg.StrictHTML {
+		return nil
+	}
+	for _, m := range []FuncMap{b.globalFuncMap, local} {
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if t := trustedResult(m[name]); t != nil {
+				return fmt.Errorf("strict mode: func %s returns %s; register it with RegisterUnsafeFunc", name, t)
+			}
+		}
+	}
+	return nil
+}
+
+// trustedResult returns the first trusted html/template type returned by
+// fn, or nil if fn returns none.
+func trustedResult(fn any) reflect.Type {
+	t := reflect.TypeOf(fn)
+	if t == nil || t.Kind() != reflect.Func {
+		return nil
+	}
+	for i := range t.NumOut() {
+		if trustedTypes[t.Out(i)] {
+			return t.Out(i)
+		}
+	}
+	return nil
+}
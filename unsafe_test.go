@@ -0,0 +1,62 @@
+package templatebox_test
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestStrictHTMLRequiresRegisteredUnsafeFuncs(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", &templatebox.Config{
+		StrictHTML: true,
+	})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	bold := func(s string) template.HTML { return template.HTML("<b>" + template.HTMLEscapeString(s) + "</b>") }
+
+	err = box.AddTemplateRaw("bold", templatebox.TemplateSet{
+		Templates: []string{`{{ bold .Name }}`},
+		FuncMap:   templatebox.FuncMap{"bold": bold},
+	})
+	if err == nil || !strings.Contains(err.Error(), "RegisterUnsafeFunc") {
+		t.Fatalf("AddTemplateRaw returned %v, expected a strict mode error", err)
+	}
+
+	if err := box.RegisterUnsafeFunc("bold", bold, ""); err == nil {
+		t.Fatalf("RegisterUnsafeFunc succeeded, expected an error for a missing justification")
+	}
+	if err := box.RegisterUnsafeFunc("bold", bold, "input is escaped before wrapping"); err != nil {
+		t.Fatalf("RegisterUnsafeFunc failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("bold", templatebox.TemplateSet{
+		Templates: []string{`{{ bold .Name }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := box.RenderHTML(&buf, "bold", map[string]string{"Name": "<Ann>"}); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+	expected := "<b>&lt;Ann&gt;</b>"
+	if buf.String() != expected {
+		t.Fatalf("RenderHTML returned %s, expected %s", buf.String(), expected)
+	}
+
+	var names []string
+	for _, f := range box.UnsafeFuncs() {
+		if !f.Builtin {
+			names = append(names, f.Name+": "+f.Justification)
+		}
+	}
+	if len(names) != 1 || names[0] != "bold: input is escaped before wrapping" {
+		t.Fatalf("UnsafeFuncs returned %v, expected only bold", names)
+	}
+}
@@ -0,0 +1,45 @@
+package templatebox
+
+import (
+	"sort"
+	"time"
+)
+
+// markRendered records that the named template was rendered at now.
+func (b *Box) markRendered(name string, now time.Time) {
+	b.lastRendered.store(name, now)
+}
+
+// LastRendered returns the time the named template was last rendered
+// successfully. The boolean is false if it has never been rendered.
+func (b *Box) LastRendered(name string) (time.Time, bool) {
+	return b.lastRendered.load(name)
+}
+
+// Unrendered returns the sorted names of the registered templates that have
+// not been rendered successfully within the last since duration, including
+// those never rendered at all. Calling it some time after deployment helps
+// large applications find dead templates that are safe to delete.
+func (b *Box) Unrendered(since time.Duration) []string {
+	cutoff := time.Now().Add(-since)
+	var names []string
+	for _, name := range b.Names() {
+		if t, ok := b.lastRendered.load(name); !ok || t.Before(cutoff) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Names returns the sorted names of all templates registered with the Box.
+func (b *Box) Names() []string {
+	b.mu.RLock()
+	names := make([]string, 0, len(b.html))
+	for name := range b.html {
+		names = append(names, name)
+	}
+	b.mu.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
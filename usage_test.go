@@ -0,0 +1,73 @@
+package templatebox_test
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestUnrendered(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateMap(map[string]templatebox.FileSet{
+		"a": {Filenames: []string{"layout.html", "a.html"}},
+		"b": {Filenames: []string{"layout.html", "b.html"}},
+		"c": {Filenames: []string{"layout.html", "c.html"}},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateMap failed: %v", err)
+	}
+
+	if err := box.RenderHTML(io.Discard, "b", nil); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	if _, ok := box.LastRendered("a"); ok {
+		t.Fatalf("LastRendered(a) returned true, expected false")
+	}
+	if _, ok := box.LastRendered("b"); !ok {
+		t.Fatalf("LastRendered(b) returned false, expected true")
+	}
+
+	got := box.Unrendered(time.Hour)
+	expected := []string{"a", "c"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Unrendered returned %v, expected %v", got, expected)
+	}
+}
+
+// BenchmarkRenderManyTemplatesParallel renders many templates from many
+// goroutines, the case the sharded render bookkeeping is for.
+func BenchmarkRenderManyTemplatesParallel(b *testing.B) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		b.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	names := make([]string, 64)
+	for i := range names {
+		names[i] = fmt.Sprintf("t%d", i)
+		err := box.AddTemplateRaw(names[i], templatebox.TemplateSet{Templates: []string{`{{ . }}`}})
+		if err != nil {
+			b.Fatalf("AddTemplateRaw failed: %v", err)
+		}
+	}
+
+	var next atomic.Int64
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		name := names[next.Add(1)%int64(len(names))]
+		for pb.Next() {
+			if err := box.RenderHTML(io.Discard, name, "x"); err != nil {
+				b.Fatalf("RenderHTML failed: %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package templatebox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+)
+
+// Output rules reported in LintIssue.Rule by CheckOutput and
+// Config.ValidateHTML.
+const (
+	// RuleUnclosedTag reports an element that is not closed, or is still
+	// open when an enclosing element is closed.
+	RuleUnclosedTag = "unclosed-tag"
+
+	// RuleUnexpectedEndTag reports an end tag without a matching start
+	// tag.
+	RuleUnexpectedEndTag = "unexpected-end-tag"
+)
+
+// voidElements have no end tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// optionalEndElements may omit their end tag, so are not checked.
+var optionalEndElements = map[string]bool{
+	"html": true, "head": true, "body": true, "p": true, "li": true,
+	"dt": true, "dd": true, "option": true, "optgroup": true,
+	"colgroup": true, "caption": true, "thead": true, "tbody": true,
+	"tfoot": true, "tr": true, "td": true, "th": true,
+	"rb": true, "rt": true, "rtc": true, "rp": true,
+}
+
+// CheckOutput renders the named templates with their sample data, as used
+// by Preview, and reports problems in the rendered HTML, such as a <div>
+// in a partial that is never closed, which are hard to trace back from a
+// broken layout in the browser. It is intended for CI. If no names are
+// given every html/template template with a page or fragment Output is
+// checked. An error is returned if a template fails to render.
+//
+// Elements whose end tag is optional in HTML, such as <p> and <li>, are
+// not checked. With Config.CheckAccessibility the output is also checked
+// for accessibility problems. As with Preview, the checked renders are not
+// counted by LastRendered and Unrendered, and are not passed to Tee or
+// recorded.
+func (b *Box) CheckOutput(names ...string) ([]LintIssue, error) {
+	if len(names) == 0 {
+		for _, name := range b.htmlNames() {
+			if out, _ := b.OutputOf(name); out != OutputAttributes {
+				names = append(names, name)
+			}
+		}
+	}
+
+	var issues []LintIssue
+	for _, name := range names {
+		data, err := b.previewData(name)
+		if err != nil {
+			return nil, fmt.Errorf("check template %s: %w", name, err)
+		}
+		var buf bytes.Buffer
+		if err := b.renderQuiet(context.Background(), &buf, name, data); err != nil {
+			return nil, fmt.Errorf("check template %s: %w", name, err)
+		}
+		issues = append(issues, b.checkOutput(name, buf.Bytes())...)
+	}
+	return issues, nil
+}
+
+// validateOutput logs the problems found in the output of a render of the
+// named template when Config.ValidateHTML is set in debug mode.
+func (b *Box) validateOutput(name string, t Template, opts renderOptions, output []byte) {
+	if _, ok := t.(*template.Template); !ok || opts.output == OutputAttributes {
+		return
+	}
+	for _, issue := range b.checkOutput(name, output) {
+		b.logger().Warn("templatebox: invalid HTML output",
+			"template", name,
+			"location", issue.Location,
+			"rule", issue.Rule,
+			"message", issue.Message)
+	}
+}
+
+// checkOutput returns the problems found in the rendered output of the
+// named template.
+func (b *Box) checkOutput(name string, output []byte) []LintIssue {
+	issues := checkStructure(name, output)
+	if b.cfg.CheckAccessibility {
+		issues = append(issues, checkAccessibility(name, output)...)
+	}
+	return issues
+}
+
+// outputIssue returns a LintIssue at the line and column of the rendered
+// output of the named template.
+func outputIssue(name string, line, col int, rule, format string, args ...any) LintIssue {
+	return LintIssue{
+		Template: name,
+		Location: fmt.Sprintf("output:%d:%d", line, col),
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// openElement is an element on the stack of open elements.
+type openElement struct {
+	name      string
+	line, col int
+}
+
+// checkStructure reports unclosed and mismatched elements in output. The
+// Location of each issue is the line and column in the output.
+func checkStructure(name string, output []byte) []LintIssue {
+	var issues []LintIssue
+	report := func(line, col int, rule, format string, args ...any) {
+		issues = append(issues, outputIssue(name, line, col, rule, format, args...))
+	}
+
+	var stack []openElement
+	scanTokens(output, func(tok htmlToken, line, col int) {
+		if tok.Type != startTagToken && tok.Type != endTagToken {
+			return
+		}
+		el := tok.Data
+		if voidElements[el] || optionalEndElements[el] {
+			return
+		}
+
+		if tok.Type == startTagToken {
+			stack = append(stack, openElement{name: el, line: line, col: col})
+			return
+		}
+
+		i := len(stack) - 1
+		for i >= 0 && stack[i].name != el {
+			i--
+		}
+		if i < 0 {
+			report(line, col, RuleUnexpectedEndTag, "</%s> has no matching <%s>", el, el)
+			return
+		}
+		for _, open := range stack[i+1:] {
+			report(open.line, open.col, RuleUnclosedTag, "<%s> is not closed before </%s> at %d:%d",
+				open.name, el, line, col)
+		}
+		stack = stack[:i]
+	})
+
+	for _, open := range stack {
+		report(open.line, open.col, RuleUnclosedTag, "<%s> is not closed", open.name)
+	}
+	return issues
+}
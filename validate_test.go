@@ -0,0 +1,51 @@
+package templatebox_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestCheckOutput(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	err = box.AddTemplateRaw("page", templatebox.TemplateSet{
+		Templates: []string{
+			`<main><script>if (a<b) { x = "<div>" }</script><!-- <p> --><ul><li>{{ .Item }}</ul>{{ template "card" . }}</main></span>`,
+			`{{ define "card" }}<div class="card">
+  <img src="a.png"><br>
+  <section>{{ .Item }}
+</div>{{ end }}`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	box.SetExampleData("page", map[string]string{"Item": "one"})
+
+	issues, err := box.CheckOutput()
+	if err != nil {
+		t.Fatalf("CheckOutput failed: %v", err)
+	}
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	expected := []string{
+		"output:3:3: <section> is not closed before </div> at 4:1 (unclosed-tag)",
+		"output:4:14: </span> has no matching <span> (unexpected-end-tag)",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("CheckOutput returned %q, expected %q", got, expected)
+	}
+
+	// checking is not a use of the template
+	if _, ok := box.LastRendered("page"); ok {
+		t.Fatalf("LastRendered reported a render of page after CheckOutput")
+	}
+}
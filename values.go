@@ -0,0 +1,76 @@
+package templatebox
+
+import (
+	"fmt"
+	"os"
+	"slices"
+)
+
+// ValueProvider looks up the named values returned by the env and secret
+// template functions, such as environment variables or the secrets of a
+// secret manager.
+type ValueProvider interface {
+	Lookup(name string) (value string, ok bool, err error)
+}
+
+// ValueProviderFunc is an adapter to allow the use of an ordinary function
+// as a ValueProvider.
+type ValueProviderFunc func(name string) (string, bool, error)
+
+// Lookup calls f(name).
+func (f ValueProviderFunc) Lookup(name string) (string, bool, error) {
+	return f(name)
+}
+
+// OSEnv is a ValueProvider reading the environment variables of the
+// process.
+var OSEnv ValueProvider = ValueProviderFunc(func(name string) (string, bool, error) {
+	v, ok := os.LookupEnv(name)
+	return v, ok, nil
+})
+
+// valueSource is a ValueProvider restricted to an allowlist of names.
+type valueSource struct {
+	provider ValueProvider
+	allowed  []string
+}
+
+// SetEnvProvider sets the ValueProvider used by the env template function
+// when it is given a name, such as {{ env "PORT" }}, typically OSEnv.
+// Only the allowed names can be looked up, so templates cannot read
+// arbitrary values of the environment.
+func (b *Box) SetEnvProvider(p ValueProvider, allowed ...string) {
+	b.envSource = &valueSource{provider: p, allowed: allowed}
+}
+
+// SetSecretProvider sets the ValueProvider used by the secret template
+// function, such as {{ secret "db_password" }}. Only the allowed names can
+// be looked up.
+func (b *Box) SetSecretProvider(p ValueProvider, allowed ...string) {
+	b.secretSource = &valueSource{provider: p, allowed: allowed}
+}
+
+// lookup returns the allowed named value of the source. It is an error for
+// the name to be missing, so a template cannot silently render an empty
+// value.
+func (s *valueSource) lookup(fn, name string) (string, error) {
+	if s == nil {
+		return "", fmt.Errorf("%s %q called but no provider is set", fn, name)
+	}
+	if !slices.Contains(s.allowed, name) {
+		return "", fmt.Errorf("%s %q is not in the allowlist", fn, name)
+	}
+	v, ok, err := s.provider.Lookup(name)
+	if err != nil {
+		return "", fmt.Errorf("%s %q: %w", fn, name, err)
+	}
+	if !ok {
+		return "", fmt.Errorf("%s %q is not set", fn, name)
+	}
+	return v, nil
+}
+
+// secret implements the secret template function.
+func (b *Box) secret(name string) (string, error) {
+	return b.secretSource.lookup("secret", name)
+}
@@ -0,0 +1,307 @@
+package templatebox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Version identifies a template set recorded by Reload when
+// Config.KeepVersions is set.
+type Version struct {
+	// ID is the Fingerprint of the template set.
+	ID        string    `json:"id"`
+	Time      time.Time `json:"time"`
+	Templates int       `json:"templates"`
+}
+
+// version is a recorded Version with the sources of its templates.
+type version struct {
+	Version
+	hashes  map[string]TemplateHash
+	sources map[string][]Source
+}
+
+// VersionDiff lists the differences between two template set versions.
+type VersionDiff struct {
+	Old     string         `json:"old"`
+	New     string         `json:"new"`
+	Added   []string       `json:"added"`
+	Removed []string       `json:"removed"`
+	Changed []TemplateDiff `json:"changed"`
+}
+
+// TemplateDiff is the unified diff of the sources of a changed template.
+type TemplateDiff struct {
+	Name string `json:"name"`
+	Diff string `json:"diff"`
+}
+
+// recordVersion records the current template set as a new version unless
+// it is the same as the latest, dropping the oldest versions beyond
+// Config.KeepVersions.
+func (b *Box) recordVersion() {
+	b.mu.RLock()
+	v := version{
+		Version: Version{
+			ID:        fingerprint(b.hashes),
+			Time:      time.Now(),
+			Templates: len(b.hashes),
+		},
+		hashes:  make(map[string]TemplateHash, len(b.hashes)),
+		sources: make(map[string][]Source, len(b.sources)),
+	}
+	for name, h := range b.hashes {
+		v.hashes[name] = h
+	}
+	for name, srcs := range b.sources {
+		v.sources[name] = srcs
+	}
+	b.mu.RUnlock()
+
+	b.muVersions.Lock()
+	defer b.muVersions.Unlock()
+
+	if n := len(b.versions); n > 0 && b.versions[n-1].ID == v.ID {
+		return
+	}
+	b.versions = append(b.versions, v)
+	if extra := len(b.versions) - b.cfg.KeepVersions; extra > 0 {
+		b.versions = append([]version(nil), b.versions[extra:]...)
+	}
+}
+
+// Versions returns the recorded template set versions, oldest first. The
+// first call to Reload records the template set before and after the
+// reload, and later calls the template set after the reload, if it
+// changed. Nothing is recorded unless Config.KeepVersions is set.
+func (b *Box) Versions() []Version {
+	b.muVersions.RLock()
+	defer b.muVersions.RUnlock()
+
+	versions := make([]Version, len(b.versions))
+	for i, v := range b.versions {
+		versions[i] = v.Version
+	}
+	return versions
+}
+
+// Diff returns the templates added, removed and changed between the
+// recorded versions with the IDs oldID and newID, with a unified diff of
+// the sources of each changed template, so that reviewers can see exactly
+// what a template deploy changed.
+func (b *Box) Diff(oldID, newID string) (VersionDiff, error) {
+	old, ok := b.version(oldID)
+	if !ok {
+		return VersionDiff{}, fmt.Errorf("version %s not found", oldID)
+	}
+	cur, ok := b.version(newID)
+	if !ok {
+		return VersionDiff{}, fmt.Errorf("version %s not found", newID)
+	}
+
+	d := VersionDiff{Old: oldID, New: newID}
+	for _, name := range sortedKeys(old.hashes) {
+		if _, ok := cur.hashes[name]; !ok {
+			d.Removed = append(d.Removed, name)
+		}
+	}
+	for _, name := range sortedKeys(cur.hashes) {
+		h, ok := old.hashes[name]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, name)
+		case h.Sum != cur.hashes[name].Sum:
+			d.Changed = append(d.Changed, TemplateDiff{
+				Name: name,
+				Diff: diffSources(old.sources[name], cur.sources[name]),
+			})
+		}
+	}
+	return d, nil
+}
+
+// SwapVersion installs the recorded version with the given ID, parsing
+// the sources it was recorded with, so that a known good template set can
+// be restored without a deploy. Each template is parsed with the FuncMap,
+// Engine and other settings it is currently added with, and all are parsed
+// before any is replaced. Templates added since the version was recorded
+// are left unchanged; a version holding a template no longer in the Box
+// cannot be installed. In debug mode templates are rebuilt from their
+// files on the next render as usual.
+func (b *Box) SwapVersion(id string) error {
+	return b.SwapVersionContext(context.Background(), id)
+}
+
+// SwapVersionContext is like SwapVersion but records the actor of ctx, set
+// with WithActor, in the audit log.
+func (b *Box) SwapVersionContext(ctx context.Context, id string) error {
+	v, ok := b.version(id)
+	if !ok {
+		return fmt.Errorf("version %s not found", id)
+	}
+	changed, err := b.installVersion(v)
+	if err != nil {
+		return err
+	}
+	b.audit(ctx, AuditSwap, changed...)
+	return nil
+}
+
+// Rollback installs the version recorded before the current template set,
+// as SwapVersion does, or the latest version if the current template set
+// was not recorded. Calling Rollback again steps further back.
+func (b *Box) Rollback() error {
+	return b.RollbackContext(context.Background())
+}
+
+// RollbackContext is like Rollback but records the actor of ctx, set with
+// WithActor, in the audit log.
+func (b *Box) RollbackContext(ctx context.Context) error {
+	v, err := b.previousVersion()
+	if err != nil {
+		return err
+	}
+	changed, err := b.installVersion(v)
+	if err != nil {
+		return err
+	}
+	b.audit(ctx, AuditRollback, changed...)
+	return nil
+}
+
+// previousVersion returns the version recorded before the current template
+// set, or the latest version if the current template set was not
+// recorded.
+func (b *Box) previousVersion() (version, error) {
+	current := b.Fingerprint()
+
+	b.muVersions.RLock()
+	defer b.muVersions.RUnlock()
+
+	for i := len(b.versions) - 1; i >= 0; i-- {
+		if b.versions[i].ID != current {
+			continue
+		}
+		if i == 0 {
+			return version{}, fmt.Errorf("no version recorded before %s", current)
+		}
+		return b.versions[i-1], nil
+	}
+	if n := len(b.versions); n > 0 {
+		return b.versions[n-1], nil
+	}
+	return version{}, fmt.Errorf("no versions recorded")
+}
+
+// installVersion parses the sources of the recorded version and replaces
+// the templates with them, returning the names of the templates that
+// changed.
+func (b *Box) installVersion(v version) ([]string, error) {
+	names := sortedKeys(v.hashes)
+	parsed := make(map[string]parsedTemplate, len(names))
+	var errs []error
+	for _, name := range names {
+		p, err := b.parseVersion(name, v.sources[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("install version %s template %s: %w", v.ID, name, err))
+			continue
+		}
+		parsed[name] = p
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	var changed []string
+	b.mu.Lock()
+	for _, name := range names {
+		p := parsed[name]
+		if b.hashes[name].Sum != p.hash.Sum {
+			changed = append(changed, name)
+		}
+		b.html[name] = p.t
+		b.hashes[name] = p.hash
+		opts := b.opts[name]
+		opts.requires = parseRequires(p.srcs)
+		b.opts[name] = opts
+		b.sources[name] = p.srcs
+	}
+	b.publishLocked()
+	b.mu.Unlock()
+	return changed, nil
+}
+
+// parseVersion parses srcs, the recorded sources of the named template,
+// with the settings the template is currently added with.
+func (b *Box) parseVersion(name string, srcs []Source) (parsedTemplate, error) {
+	if len(srcs) == 0 {
+		return parsedTemplate{}, fmt.Errorf("no sources recorded")
+	}
+
+	b.muFileSets.RLock()
+	fset, isFile := b.fileSets[name]
+	rset, isRaw := b.rawSets[name]
+	b.muFileSets.RUnlock()
+
+	var t Template
+	var err error
+	switch {
+	case isFile:
+		t, err = b.parse(srcs[0].Name, srcs, fset.Engine, fset.Meta, fset.FuncMap)
+	case isRaw:
+		t, err = b.parse(name, srcs, rset.Engine, rset.Meta, rset.FuncMap)
+	default:
+		return parsedTemplate{}, fmt.Errorf("template is no longer in the Box")
+	}
+	if err != nil {
+		return parsedTemplate{}, err
+	}
+	return parsedTemplate{t: t, hash: hashSources(srcs), srcs: srcs}, nil
+}
+
+// version returns the recorded version with the given ID.
+func (b *Box) version(id string) (version, bool) {
+	b.muVersions.RLock()
+	defer b.muVersions.RUnlock()
+
+	for _, v := range b.versions {
+		if v.ID == id {
+			return v, true
+		}
+	}
+	return version{}, false
+}
+
+// diffSources returns the unified diffs of the sources of a template,
+// pairing sources by filename, or by name for raw templates.
+func diffSources(old, cur []Source) string {
+	label := func(src Source) string {
+		if src.Filename != "" {
+			return src.Filename
+		}
+		return src.Name
+	}
+
+	texts := make(map[string]string)
+	var labels []string
+	for _, src := range old {
+		texts[label(src)] = string(src.Text)
+		labels = append(labels, label(src))
+	}
+
+	var diff string
+	seen := make(map[string]bool)
+	for _, src := range cur {
+		l := label(src)
+		seen[l] = true
+		diff += unifiedDiff("a/"+l, "b/"+l, texts[l], string(src.Text))
+	}
+	for _, l := range labels {
+		if !seen[l] {
+			diff += unifiedDiff("a/"+l, "b/"+l, texts[l], "")
+		}
+	}
+	return diff
+}
@@ -0,0 +1,60 @@
+package templatebox_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestDiff(t *testing.T) {
+	path := t.TempDir()
+	writeTemplates(t, path, map[string]string{
+		"a.html": "line 1\nline 2\nline 3\n",
+		"b.html": "b\n",
+	})
+
+	box, err := templatebox.NewBoxFromOSDir(path, &templatebox.Config{KeepVersions: 5})
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+	err = box.AddTemplateMap(map[string]templatebox.FileSet{
+		"a": {Filenames: []string{"a.html"}},
+		"b": {Filenames: []string{"b.html"}},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateMap failed: %v", err)
+	}
+
+	writeTemplates(t, path, map[string]string{"a.html": "line 1\nline two\nline 3\n"})
+	if err := box.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	versions := box.Versions()
+	if len(versions) != 2 {
+		t.Fatalf("Versions returned %d versions, expected 2", len(versions))
+	}
+	if versions[1].ID != box.Fingerprint() {
+		t.Fatalf("latest version is %s, expected the Fingerprint %s", versions[1].ID, box.Fingerprint())
+	}
+
+	d, err := box.Diff(versions[0].ID, versions[1].ID)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	expected := []templatebox.TemplateDiff{{
+		Name: "a",
+		Diff: "--- a/a.html\n+++ b/a.html\n@@ -1,3 +1,3 @@\n line 1\n-line 2\n+line two\n line 3\n",
+	}}
+	if !reflect.DeepEqual(d.Changed, expected) {
+		t.Fatalf("Diff returned %+v, expected %+v", d.Changed, expected)
+	}
+	if len(d.Added) != 0 || len(d.Removed) != 0 {
+		t.Fatalf("Diff returned added %v and removed %v, expected none", d.Added, d.Removed)
+	}
+
+	if _, err := box.Diff("unknown", versions[1].ID); err == nil {
+		t.Fatalf("Diff of an unknown version succeeded, expected an error")
+	}
+}
@@ -0,0 +1,86 @@
+package templatebox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// Warm renders the named templates once, discarding the output, so that
+// caches are populated and runtime errors that only show with real data,
+// such as a missing field or a failing template function, surface at
+// startup rather than on the first request. Each template is rendered with
+// the same data Preview would use: the example data registered with
+// SetExampleData or found in an example data file, the recorded data, or
+// placeholder data from SetDataType.
+// If no names are given every template in the Box is warmed. The returned
+// error lists every template that failed to render. Warm renders are not
+// counted by LastRendered and Unrendered, and are not passed to Tee or
+// recorded.
+func (b *Box) Warm(ctx context.Context, names ...string) error {
+	if len(names) == 0 {
+		names = b.Names()
+	}
+
+	errs := make([]error, len(names))
+	workers := min(runtime.GOMAXPROCS(0), len(names))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if err := b.warm(ctx, names[i]); err != nil {
+					errs[i] = fmt.Errorf("warm template %s: %w", names[i], err)
+				}
+			}
+		}()
+	}
+	for i := range names {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// warm renders the named template with its preview data into io.Discard.
+func (b *Box) warm(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := b.previewData(name)
+	if err != nil {
+		return err
+	}
+	return b.renderQuiet(ctx, io.Discard, name, data)
+}
+
+// renderQuiet renders the named template to w with data, through the data
+// checks and output cache of the template but without the usage tracking,
+// Tee and recording of render, for renders that do not serve a page: Warm,
+// Preview and CheckOutput.
+func (b *Box) renderQuiet(ctx context.Context, w io.Writer, name string, data any) error {
+	release, err := b.acquireRender(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	t, opts, err := b.lookup(name)
+	if err != nil {
+		return err
+	}
+	if err := b.checkData(name, opts, data); err != nil {
+		return err
+	}
+	if opts.cache != nil && !b.debug() {
+		return b.renderCached(w, name, t, data, opts.cache)
+	}
+	return t.Execute(w, data)
+}
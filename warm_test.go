@@ -0,0 +1,50 @@
+package templatebox_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/andyfusniak/templatebox"
+)
+
+func TestWarm(t *testing.T) {
+	box, err := templatebox.NewBoxFromOSDir("testdata/templates", nil)
+	if err != nil {
+		t.Fatalf("NewBoxFromOSDir failed: %v", err)
+	}
+
+	type page struct{ Title string }
+	err = box.AddTemplateRaw("page", templatebox.TemplateSet{
+		Templates: []string{`<h1>{{ .Title }}</h1>`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	err = box.AddTemplateRaw("broken", templatebox.TemplateSet{
+		Templates: []string{`{{ .Missing }}`},
+	})
+	if err != nil {
+		t.Fatalf("AddTemplateRaw failed: %v", err)
+	}
+	box.SetExampleData("page", page{Title: "Home"})
+	box.SetExampleData("broken", page{Title: "Broken"})
+
+	if err := box.Warm(context.Background(), "page"); err != nil {
+		t.Fatalf("Warm failed: %v", err)
+	}
+
+	// warming is not a use of the template
+	if _, ok := box.LastRendered("page"); ok {
+		t.Fatalf("LastRendered reported a render of page after Warm")
+	}
+
+	err = box.Warm(context.Background())
+	if err == nil {
+		t.Fatalf("Warm succeeded, expected an error for the broken template")
+	}
+	expected := "warm template broken:"
+	if !strings.Contains(err.Error(), expected) {
+		t.Fatalf("Warm returned %v, expected it to contain %s", err, expected)
+	}
+}